@@ -0,0 +1,64 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Retail accounts are identified by checksummed addresses such as hdfc1<40 hex digits><8 hex digits>,
+// in the format checked by the chaincode: the address prefix of the bank, the separator "1", a 20 byte id
+// and the first 4 bytes of the SHA-256 of what precedes it
+const (
+	addressSeparator      = "1"
+	addressIDLength       = 20
+	addressChecksumLength = 4
+)
+
+// Generate the address of a new account of the bank
+// The id of a self-custodial account is derived from the base64 DER public key of its holder, other ids are random
+func newAddress(publicKey string) (string, error) {
+	id := make([]byte, addressIDLength)
+	if publicKey != "" {
+		der, err := base64.StdEncoding.DecodeString(publicKey)
+		if err != nil {
+			return "", fmt.Errorf("public key must be base64 encoded: %w", err)
+		}
+		digest := sha256.Sum256(der)
+		copy(id, digest[:])
+	} else if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate account id: %w", err)
+	}
+	return withChecksum(Config.AddressPrefix + addressSeparator + hex.EncodeToString(id)), nil
+}
+
+// Check the checksum of an account id shaped like an address of a bank in the registry
+// Other ids are legacy free-text ids, the chaincode accepts them for registered accounts only, which the bank cannot tell offline
+func checkAccountID(account string) error {
+	if account == "" {
+		return fmt.Errorf("account id cannot be empty")
+	}
+	for _, prefix := range Banks.addressPrefixes() {
+		digits, ok := strings.CutPrefix(account, prefix+addressSeparator)
+		if !ok || len(digits) != 2*(addressIDLength+addressChecksumLength) || strings.ToLower(digits) != digits {
+			continue
+		}
+		if _, err := hex.DecodeString(digits); err != nil {
+			continue
+		}
+		if withChecksum(account[:len(account)-2*addressChecksumLength]) != account {
+			return fmt.Errorf("the checksum of address %s does not match, check it for typos", account)
+		}
+		return nil
+	}
+	return nil
+}
+
+// Append the checksum to the prefix, separator and id of an address
+func withChecksum(address string) string {
+	digest := sha256.Sum256([]byte(address))
+	return address + hex.EncodeToString(digest[:addressChecksumLength])
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckAccountID(t *testing.T) {
+	Banks = &bankRegistry{prefixes: []string{"hdfc", "axis"}}
+	valid := withChecksum("axis1" + strings.Repeat("ab", addressIDLength))
+	body := valid[:len(valid)-2*addressChecksumLength]
+
+	tests := []struct {
+		name    string
+		account string
+		wantErr string
+	}{
+		{"address of a registered bank", valid, ""},
+		{"changed checksum", body + "00000000", "does not match"},
+		{"empty id", "", "cannot be empty"},
+		// Ids not shaped like an address are left to the registration check of the chaincode
+		{"legacy id", "alice.hdfc", ""},
+		{"legacy id without a dot", "alice", ""},
+		{"legacy id with the prefix of a bank", "axis1alice", ""},
+		{"uppercase digits", "axis1" + strings.ToUpper(valid[len("axis1"):]), ""},
+		{"missing checksum", body, ""},
+		{"address of an unregistered bank", withChecksum("sbi1" + strings.Repeat("ab", addressIDLength)), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAccountID(tt.account)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkAccountID(%q) = %v, want nil", tt.account, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("checkAccountID(%q) = %v, want an error containing %q", tt.account, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewAddress(t *testing.T) {
+	Banks = &bankRegistry{prefixes: []string{"hdfc"}}
+	Config = &bankConfig{AddressPrefix: "hdfc"}
+
+	address, err := newAddress("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkAccountID(address); err != nil {
+		t.Fatalf("new address %s is rejected: %v", address, err)
+	}
+}
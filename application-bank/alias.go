@@ -0,0 +1,128 @@
+package main
+
+import (
+	cbdc "app/api"
+	"common/gwstatus"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// aliasRecord mirrors the Alias returned by the chaincode
+type aliasRecord struct {
+	Alias       string `json:"alias"`
+	Account     string `json:"account"`
+	DisplayName string `json:"displayName"`
+}
+
+// Aliases such as priya@hdfc end in "@" and the address prefix of the bank owning them
+func isAlias(id string) bool {
+	return strings.Contains(id, "@")
+}
+
+// Check that an alias has the suffix of the bank, the chaincode only lets a bank manage its own aliases
+func checkAliasSuffix(alias string) error {
+	if !strings.HasSuffix(alias, "@"+Config.AddressPrefix) {
+		return status.Errorf(codes.InvalidArgument, "alias %s must end in @%s", alias, Config.AddressPrefix)
+	}
+	return nil
+}
+
+// Resolve an alias to its account, other ids are checked and returned unchanged
+func resolveAccount(contract *client.Contract, id string) (string, error) {
+	if !isAlias(id) {
+		if err := checkAccountID(id); err != nil {
+			return "", gwstatus.InvalidArgument(err)
+		}
+		return id, nil
+	}
+	alias, err := resolveAlias(contract, id)
+	if err != nil {
+		return "", err
+	}
+	return alias.Account, nil
+}
+
+// Register an alias of the bank for one of its accounts
+func registerAlias(contract *client.Contract, alias, account, displayName string) (string, error) {
+	fmt.Printf("\n--> Submit Transaction: RegisterAlias, registers %s for %s\n", alias, account)
+	if err := checkAliasSuffix(alias); err != nil {
+		return "xxxxx", err
+	}
+	_, txId, err := gwstatus.SubmitTransaction(contract, "RegisterAlias", alias, account, displayName)
+	return txId, err
+}
+
+// Delete an alias of the bank
+func deleteAlias(contract *client.Contract, alias string) (string, error) {
+	fmt.Printf("\n--> Submit Transaction: DeleteAlias, deletes %s\n", alias)
+	_, txId, err := gwstatus.SubmitTransaction(contract, "DeleteAlias", alias)
+	return txId, err
+}
+
+// Get the account an alias resolves to
+func resolveAlias(contract *client.Contract, alias string) (*aliasRecord, error) {
+	fmt.Printf("\n--> Evaluate Transaction: ResolveAlias, returns the account of %s\n", alias)
+	evaluateResult, err := contract.EvaluateTransaction("ResolveAlias", alias)
+	if err != nil {
+		return nil, gwstatus.ToStatus(err)
+	}
+
+	var record aliasRecord
+	if err := json.Unmarshal(evaluateResult, &record); err != nil {
+		return nil, gwstatus.ToStatus(fmt.Errorf("failed to parse alias: %w", err))
+	}
+	return &record, nil
+}
+
+// Get the name to display for an account: the display name of its first alias, else the alias itself
+// Returns an empty name for accounts without aliases
+func displayName(contract *client.Contract, account string) (string, error) {
+	evaluateResult, err := contract.EvaluateTransaction("GetAliases", account)
+	if err != nil {
+		return "", gwstatus.ToStatus(err)
+	}
+
+	var records []aliasRecord
+	if err := json.Unmarshal(evaluateResult, &records); err != nil {
+		return "", gwstatus.ToStatus(fmt.Errorf("failed to parse aliases: %w", err))
+	}
+	if len(records) == 0 {
+		return "", nil
+	}
+	if records[0].DisplayName != "" {
+		return records[0].DisplayName, nil
+	}
+	return records[0].Alias, nil
+}
+
+// Fill in the display names of the accounts of transfer records, looking up each account once
+func addDisplayNames(contract *client.Contract, records []*cbdc.TxRecord) error {
+	names := make(map[string]string)
+	lookup := func(account string) (string, error) {
+		if name, ok := names[account]; ok {
+			return name, nil
+		}
+		name, err := displayName(contract, account)
+		if err != nil {
+			return "", err
+		}
+		names[account] = name
+		return name, nil
+	}
+
+	for _, record := range records {
+		var err error
+		if record.FromName, err = lookup(record.From); err != nil {
+			return err
+		}
+		if record.ToName, err = lookup(record.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
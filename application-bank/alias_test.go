@@ -0,0 +1,104 @@
+package main
+
+import (
+	cbdc "app/api"
+	"common/gwstatus"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// aliasFunctions serves ResolveAlias for the aliases of a test
+func aliasFunctions(aliases map[string]string) map[string]chaincodeFunc {
+	return map[string]chaincodeFunc{
+		"ResolveAlias": func(args ...string) (string, error) {
+			account, ok := aliases[args[0]]
+			if !ok {
+				return "", fmt.Errorf("NOT_FOUND: the alias %s is not registered", args[0])
+			}
+			return fmt.Sprintf(`{"alias":"%s","account":"%s","bank":"HDFCBankMSP"}`, args[0], account), nil
+		},
+	}
+}
+
+func TestResolveAccount(t *testing.T) {
+	setupBank(t)
+	account := withChecksum("hdfc1" + strings.Repeat("ab", addressIDLength))
+	contract, gateway := newTestContract(t, aliasFunctions(map[string]string{"priya@hdfc": account}))
+
+	resolved, err := resolveAccount(contract, "priya@hdfc")
+	if err != nil || resolved != account {
+		t.Fatalf("resolveAccount(priya@hdfc) = %q, %v, want %q", resolved, err, account)
+	}
+
+	// The code of the chaincode error picks the status, the message is reported without it
+	_, err = resolveAccount(contract, "ravi@hdfc")
+	if st := status.Convert(err); st.Code() != codes.NotFound || st.Message() != "the alias ravi@hdfc is not registered" {
+		t.Fatalf("resolveAccount(ravi@hdfc) = %v, want NotFound", err)
+	}
+
+	// Other ids are checked offline and returned unchanged
+	if resolved, err := resolveAccount(contract, account); err != nil || resolved != account {
+		t.Fatalf("resolveAccount(%s) = %q, %v, want it unchanged", account, resolved, err)
+	}
+	mistyped := account[:len(account)-8] + "00000000"
+	if _, err := resolveAccount(contract, mistyped); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("resolveAccount(%s) = %v, want InvalidArgument", mistyped, err)
+	}
+	if got := gateway.count("ResolveAlias"); got != 2 {
+		t.Fatalf("%d aliases resolved, want 2", got)
+	}
+}
+
+func TestBulkTxResolvesAlias(t *testing.T) {
+	setupBank(t)
+	account := withChecksum("hdfc1" + strings.Repeat("ab", addressIDLength))
+	payee := withChecksum("axis1" + strings.Repeat("cd", addressIDLength))
+	functions := aliasFunctions(map[string]string{"priya@hdfc": account})
+	functions["TransferBatch"] = func(args ...string) (string, error) { return "", nil }
+	var gateway *fakeGateway
+	Contract, gateway = newTestContract(t, functions)
+
+	res, err := (&server{}).BulkTx(context.Background(), &cbdc.BulkTxRequest{
+		From: "priya@hdfc",
+		Legs: []*cbdc.BulkTxLeg{{To: payee, DecimalAmount: "10.00"}},
+	})
+	if err != nil {
+		t.Fatalf("BulkTx() = %v, want nil", err)
+	}
+	if res.From != account {
+		t.Errorf("from = %s, want %s", res.From, account)
+	}
+	calls := gateway.served()
+	if len(calls) != 2 || !strings.HasPrefix(calls[1], "TransferBatch "+account+" ") {
+		t.Fatalf("calls = %q, want the batch submitted from %s", calls, account)
+	}
+}
+
+func TestRegisterAliasRejected(t *testing.T) {
+	setupBank(t)
+	account := withChecksum("hdfc1" + strings.Repeat("ab", addressIDLength))
+	contract, _ := newTestContract(t, map[string]chaincodeFunc{
+		"RegisterAlias": func(args ...string) (string, error) {
+			return "", fmt.Errorf("ALREADY_EXISTS: the alias %s is already registered", args[0])
+		},
+	})
+
+	// A transaction the peers refuse to endorse reports the code of the chaincode error, its error carries the proposed transaction id
+	txId, err := registerAlias(contract, "priya@hdfc", account, "")
+	if st := status.Convert(err); st.Code() != codes.AlreadyExists || st.Message() != "the alias priya@hdfc is already registered" {
+		t.Fatalf("registerAlias() = %v, want AlreadyExists", err)
+	}
+	if txId != "xxxxx" || gwstatus.TxID(err) == "xxxxx" {
+		t.Fatalf("registerAlias() = transaction %s with an error of transaction %s, want xxxxx and the proposed transaction", txId, gwstatus.TxID(err))
+	}
+
+	_, err = registerAlias(contract, "priya@axis", account, "")
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("registerAlias() with the suffix of another bank = %v, want InvalidArgument", err)
+	}
+}
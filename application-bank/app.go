@@ -0,0 +1,318 @@
+package main
+
+import (
+	cbdc "app/api"
+	"bytes"
+	"common/gwstatus"
+	"common/units"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"os"
+	"path"
+	"strconv"
+)
+
+// Get Current Client Id
+func getCurrentClientId(contract *client.Contract) (string, error) {
+	fmt.Println("\n--> Evaluate Transaction: ClientAccountID, function returns the id of the requesting client's account")
+	evaluateResult, err := contract.EvaluateTransaction("ClientAccountID")
+	if err != nil {
+		return "", fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+	result := string(evaluateResult)
+
+	fmt.Printf("*** Result:%s\n", result)
+	return result, nil
+}
+
+// Get any Client Balance
+// balanceBuckets mirrors the BalanceBuckets returned by the chaincode
+type balanceBuckets struct {
+	Total        string `json:"total"`
+	Free         string `json:"free"`
+	PurposeBound []struct {
+		Purpose string `json:"purpose"`
+		Amount  string `json:"amount"`
+	} `json:"purposeBound"`
+}
+
+// Get the total, free and purpose-bound balances of an account
+func getClientBalance(contract *client.Contract, account string) (uint64, uint64, []*cbdc.PurposeBalance, error) {
+	fmt.Println("\n--> Evaluate Transaction: GetBalanceBuckets, function returns the free and purpose-bound balances of an account")
+	evaluateResult, err := contract.EvaluateTransaction("GetBalanceBuckets", account)
+	if err != nil {
+		return 0, 0, nil, gwstatus.ToStatus(err)
+	}
+
+	fmt.Printf("*** %s:%s\n", account, formatJSON(evaluateResult))
+	var buckets balanceBuckets
+	if err := json.Unmarshal(evaluateResult, &buckets); err != nil {
+		return 0, 0, nil, fmt.Errorf("failed to parse balance: %w", err)
+	}
+
+	// Balances are arbitrary-precision on the ledger; refuse the ones that do not fit the API
+	total, err := strconv.ParseUint(buckets.Total, 10, 64)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	free, err := strconv.ParseUint(buckets.Free, 10, 64)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	purposeBalances := make([]*cbdc.PurposeBalance, 0, len(buckets.PurposeBound))
+	for _, bucket := range buckets.PurposeBound {
+		amount, err := strconv.ParseUint(bucket.Amount, 10, 64)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		purposeBalances = append(purposeBalances, &cbdc.PurposeBalance{
+			Purpose:       bucket.Purpose,
+			Amount:        amount,
+			DecimalAmount: units.Format(amount),
+		})
+	}
+	return total, free, purposeBalances, nil
+}
+
+// Register an end user account on the ledger
+// A public key registers a self-custodial account, debited only by transfers signed by its holder
+func registerAccount(contract *client.Contract, account string, kycTier uint32, merchantCategory, publicKey string) (string, error) {
+	fmt.Printf("\n--> Submit Transaction: RegisterAccount, registers %s with KYC tier %d\n", account, kycTier)
+	name, args := "RegisterAccount", []string{account, strconv.FormatUint(uint64(kycTier), 10), merchantCategory}
+	if publicKey != "" {
+		if merchantCategory != "" {
+			return account, status.Error(codes.InvalidArgument, "merchant accounts cannot be self-custodial")
+		}
+		name, args = "RegisterSelfCustodialAccount", []string{account, strconv.FormatUint(uint64(kycTier), 10), publicKey}
+	}
+	_, _, err := gwstatus.SubmitTransaction(contract, name, args...)
+	return account, err
+}
+
+// Check that an account is registered and held by this bank
+func checkHeldAccount(contract *client.Contract, account string) error {
+	evaluateResult, err := contract.EvaluateTransaction("GetAccount", account)
+	if err != nil {
+		return gwstatus.ToStatus(err)
+	}
+	var acc struct {
+		Bank      string `json:"bank"`
+		Custodian string `json:"custodian"`
+	}
+	if err := json.Unmarshal(evaluateResult, &acc); err != nil {
+		return gwstatus.ToStatus(fmt.Errorf("failed to parse account: %w", err))
+	}
+	custodian := acc.Custodian
+	if custodian == "" {
+		custodian = acc.Bank
+	}
+	if custodian != Config.MSPID {
+		return status.Errorf(codes.PermissionDenied, "account %s is not held by %s", account, Config.MSPID)
+	}
+	return nil
+}
+
+// Look up the transaction that already used a payment reference to debit an account, if any
+func getPaymentReference(contract *client.Contract, from, paymentRef string) (string, error) {
+	evaluateResult, err := contract.EvaluateTransaction("GetPaymentReference", from, paymentRef)
+	if err != nil {
+		return "", gwstatus.ToStatus(err)
+	}
+	return string(evaluateResult), nil
+}
+
+// Transfer an amount between accounts in custody of the bank, returning the id of the transaction
+// A payment reference already used by a committed transfer returns that transfer with the message "Transaction Already Committed"
+func transferFrom(contract *client.Contract, from, to, amount, paymentRef string) (string, string, string, uint64, string, error) {
+	fmt.Printf("\n--> Transfer %s %s->%s", amount, from, to)
+	value, err := strconv.Atoi(amount)
+	if err != nil || value <= 0 {
+		return "xxxxx", from, to, 0, "", status.Errorf(codes.InvalidArgument, "invalid amount %v", amount)
+	}
+
+	// A retried request must not pay twice; report the transaction that already committed it
+	if paymentRef != "" {
+		if txId, err := getPaymentReference(contract, from, paymentRef); err == nil && txId != "" {
+			fmt.Printf("*** Payment reference %s already committed in transaction %s\n", paymentRef, txId)
+			return txId, from, to, uint64(value), "Transaction Already Committed", nil
+		}
+	}
+	_, txId, err := gwstatus.SubmitTransaction(contract, "TransferFrom", from, to, amount, paymentRef)
+	if err != nil {
+		return txId, from, to, uint64(value), "", err
+	}
+	return txId, from, to, uint64(value), "Transaction Committed Successfully", nil
+}
+
+// transferRecord mirrors the TransferRecord returned by the chaincode
+type transferRecord struct {
+	TxID      string `json:"txId"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Value     uint64 `json:"value"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Get a page of the transaction history of an account
+func getHistory(contract *client.Contract, account string, fromTime, toTime int64, pageSize int32, bookmark string) ([]*cbdc.TxRecord, string, error) {
+	fmt.Printf("\n--> Evaluate Transaction: GetTransactionHistory, returns the transfer records of %s\n", account)
+	if pageSize <= 0 {
+		pageSize = HistoryPageSize
+	}
+	evaluateResult, err := contract.EvaluateTransaction("GetTransactionHistory", account,
+		strconv.FormatInt(fromTime, 10), strconv.FormatInt(toTime, 10), strconv.FormatInt(int64(pageSize), 10), bookmark)
+	if err != nil {
+		return nil, "", gwstatus.ToStatus(err)
+	}
+
+	var history struct {
+		Records  []transferRecord `json:"records"`
+		Bookmark string           `json:"bookmark"`
+	}
+	if err := json.Unmarshal(evaluateResult, &history); err != nil {
+		return nil, "", fmt.Errorf("failed to parse transaction history: %w", err)
+	}
+
+	records := make([]*cbdc.TxRecord, 0, len(history.Records))
+	for _, record := range history.Records {
+		records = append(records, &cbdc.TxRecord{
+			TxId:      record.TxID,
+			From:      record.From,
+			To:        record.To,
+			Amount:    record.Value,
+			Timestamp: record.Timestamp,
+		})
+	}
+	if err := addDisplayNames(contract, records); err != nil {
+		return nil, "", err
+	}
+	return records, history.Bookmark, nil
+}
+
+// Lock an amount of a customer account in escrow until the recipient reveals the preimage of the hashlock
+func createHTLC(contract *client.Contract, sender, recipient string, amount uint64, hashlock string, timeout int64) (string, string, error) {
+	fmt.Printf("\n--> Submit Transaction: CreateHTLC, locks %d of %s for %s\n", amount, sender, recipient)
+	result, txId, err := gwstatus.SubmitTransaction(contract, "CreateHTLC", sender, recipient, strconv.FormatUint(amount, 10), hashlock, strconv.FormatInt(timeout, 10))
+	return txId, string(result), err
+}
+
+// Pay a locked amount to its recipient
+func claimHTLC(contract *client.Contract, htlcId, preimage string) (string, error) {
+	fmt.Printf("\n--> Submit Transaction: ClaimHTLC, claims %s\n", htlcId)
+	_, txId, err := gwstatus.SubmitTransaction(contract, "ClaimHTLC", htlcId, preimage)
+	return txId, err
+}
+
+// Return a locked amount to its sender after the timeout
+func refundHTLC(contract *client.Contract, htlcId string) (string, error) {
+	fmt.Printf("\n--> Submit Transaction: RefundHTLC, refunds %s\n", htlcId)
+	_, txId, err := gwstatus.SubmitTransaction(contract, "RefundHTLC", htlcId)
+	return txId, err
+}
+
+// Hand CBDC from the bank reserve account back to the central bank
+func redeem(ctx context.Context, amount uint64) (string, uint64, error) {
+	res, err := RBIClient.Redeem(ctx, &cbdc.RedeemRequest{
+		Account: Config.BankAccount,
+		Amount:  amount,
+	})
+	if err != nil {
+		return "xxxxx", amount, err
+	}
+	return res.TxId, res.Amount, nil
+}
+
+// newGrpcConnection creates a gRPC connection to the Gateway server.
+func newGrpcConnection() *grpc.ClientConn {
+	certificatePEM, err := os.ReadFile(Config.TLSCertPath)
+	if err != nil {
+		panic(fmt.Errorf("failed to read TLS certifcate file: %w", err))
+	}
+
+	certificate, err := identity.CertificateFromPEM(certificatePEM)
+	if err != nil {
+		panic(err)
+	}
+
+	certPool := x509.NewCertPool()
+	certPool.AddCert(certificate)
+	transportCredentials := credentials.NewClientTLSFromCert(certPool, Config.GatewayPeer)
+
+	connection, err := grpc.NewClient(Config.PeerEndpoint, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		panic(fmt.Errorf("failed to create gRPC connection: %w", err))
+	}
+
+	return connection
+}
+
+// newIdentity creates a client identity for this Gateway connection using an X.509 certificate.
+func newIdentity() *identity.X509Identity {
+	certificatePEM, err := readFirstFile(Config.CertPath)
+	if err != nil {
+		panic(fmt.Errorf("failed to read certificate file: %w", err))
+	}
+
+	certificate, err := identity.CertificateFromPEM(certificatePEM)
+	if err != nil {
+		panic(err)
+	}
+
+	id, err := identity.NewX509Identity(Config.MSPID, certificate)
+	if err != nil {
+		panic(err)
+	}
+
+	return id
+}
+
+// newSign creates a function that generates a digital signature from a message digest using a private key.
+func newSign() identity.Sign {
+	privateKeyPEM, err := readFirstFile(Config.KeyPath)
+	if err != nil {
+		panic(fmt.Errorf("failed to read private key file: %w", err))
+	}
+
+	privateKey, err := identity.PrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		panic(err)
+	}
+
+	sign, err := identity.NewPrivateKeySign(privateKey)
+	if err != nil {
+		panic(err)
+	}
+
+	return sign
+}
+
+func readFirstFile(dirPath string) ([]byte, error) {
+	dir, err := os.Open(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fileNames, err := dir.Readdirnames(1)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(path.Join(dirPath, fileNames[0]))
+}
+
+// Format JSON data
+func formatJSON(data []byte) string {
+	var prettyJSON bytes.Buffer
+	if err := json.Indent(&prettyJSON, data, "", "  "); err != nil {
+		return string(data)
+	}
+	return prettyJSON.String()
+}
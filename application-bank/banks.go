@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// BankRefreshInterval is how often the node rereads the bank registry, picking up the banks onboarded since
+const BankRefreshInterval = 5 * time.Minute
+
+// bank is a commercial bank of the bank registry kept on the ledger by the central bank
+type bank struct {
+	MSPID          string `json:"mspId"`
+	AddressPrefix  string `json:"addressPrefix"`
+	ReserveAccount string `json:"reserveAccount"`
+}
+
+// bankRegistry caches the address prefixes of the banks of the ledger registry, accepted as transfer counterparties
+type bankRegistry struct {
+	contract *client.Contract
+
+	mu       sync.RWMutex
+	prefixes []string
+}
+
+var Banks *bankRegistry
+
+// Read the bank registry and set the reserve account and address prefix of the bank of the node from its entry
+func loadBankRegistry(contract *client.Contract) (*bankRegistry, error) {
+	registry := &bankRegistry{contract: contract}
+	banks, err := registry.refresh()
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(banks, func(b bank) bool { return b.MSPID == Config.MSPID })
+	if i < 0 {
+		return nil, fmt.Errorf("%s is not in the bank registry, the central bank registers it from its banks config", Config.MSPID)
+	}
+	Config.BankAccount = banks[i].ReserveAccount
+	Config.AddressPrefix = banks[i].AddressPrefix
+	return registry, nil
+}
+
+// Reread the bank registry every BankRefreshInterval
+func (r *bankRegistry) run() {
+	for range time.Tick(BankRefreshInterval) {
+		if _, err := r.refresh(); err != nil {
+			log.Printf("Failed to refresh bank registry: %v", err)
+		}
+	}
+}
+
+// Reread the bank registry from the ledger and cache the address prefixes of its banks
+func (r *bankRegistry) refresh() ([]bank, error) {
+	evaluateResult, err := r.contract.EvaluateTransaction("GetBanks")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bank registry: %w", err)
+	}
+	var banks []bank
+	if err := json.Unmarshal(evaluateResult, &banks); err != nil {
+		return nil, fmt.Errorf("failed to parse bank registry: %w", err)
+	}
+
+	prefixes := make([]string, 0, len(banks))
+	for _, b := range banks {
+		prefixes = append(prefixes, b.AddressPrefix)
+	}
+	r.mu.Lock()
+	r.prefixes = prefixes
+	r.mu.Unlock()
+	return banks, nil
+}
+
+// Get the address prefixes of the banks of the registry
+func (r *bankRegistry) addressPrefixes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.prefixes
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	cbdc "app/api"
+	"common/gwstatus"
+	"common/units"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// batchLeg mirrors the TransferLeg taken by the chaincode
+type batchLeg struct {
+	To     string `json:"to"`
+	Amount uint64 `json:"amount"`
+}
+
+// parseBulkLegs reads the legs of a bulk transaction from its JSON legs or, if there are none, from its CSV payload
+// Every leg gets a result; ok is false if any of them is invalid
+func parseBulkLegs(req *cbdc.BulkTxRequest) ([]batchLeg, []*cbdc.BulkTxLegResult, bool) {
+	legs := req.Legs
+	if len(legs) == 0 && req.Csv != "" {
+		reader := csv.NewReader(strings.NewReader(req.Csv))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		for line := 1; ; line++ {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, []*cbdc.BulkTxLegResult{{Success: false, Message: fmt.Sprintf("Invalid CSV: %v", err)}}, false
+			}
+			if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "to") {
+				continue
+			}
+			if len(record) != 2 {
+				return nil, []*cbdc.BulkTxLegResult{{Success: false, Message: fmt.Sprintf("Invalid CSV line %d: expected to,amount", line)}}, false
+			}
+			legs = append(legs, &cbdc.BulkTxLeg{To: strings.TrimSpace(record[0]), DecimalAmount: strings.TrimSpace(record[1])})
+		}
+	}
+	if len(legs) == 0 {
+		return nil, []*cbdc.BulkTxLegResult{{Success: false, Message: "No legs in bulk transaction"}}, false
+	}
+
+	ok := true
+	batch := make([]batchLeg, 0, len(legs))
+	results := make([]*cbdc.BulkTxLegResult, 0, len(legs))
+	for _, leg := range legs {
+		result := &cbdc.BulkTxLegResult{To: leg.To, DecimalAmount: leg.DecimalAmount}
+		value, err := units.Parse(leg.DecimalAmount, req.Currency, leg.Amount)
+		addressErr := checkAccountID(leg.To)
+		switch {
+		case err != nil:
+			result.Message = err.Error()
+		case value == 0:
+			result.Message = "Amount must be positive"
+		case leg.To == "":
+			result.Message = "Recipient is missing"
+		case addressErr != nil:
+			result.Message = addressErr.Error()
+		default:
+			result.Success = true
+		}
+		if !result.Success {
+			ok = false
+		} else {
+			result.Amount = value
+			result.DecimalAmount = units.Format(value)
+		}
+		batch = append(batch, batchLeg{To: leg.To, Amount: value})
+		results = append(results, result)
+	}
+	return batch, results, ok
+}
+
+// Transfer to several recipients from one account in a single transaction
+func transferBatch(contract *client.Contract, from string, legs []batchLeg, paymentRef string) (string, string, error) {
+	fmt.Printf("\n--> Submit Transaction: TransferBatch, transfers from %s to %d recipients\n", from, len(legs))
+
+	// A retried request must not pay twice; report the transaction that already committed it
+	if paymentRef != "" {
+		if txId, err := getPaymentReference(contract, from, paymentRef); err == nil && txId != "" {
+			fmt.Printf("*** Payment reference %s already committed in transaction %s\n", paymentRef, txId)
+			return txId, "Transaction Already Committed", nil
+		}
+	}
+
+	legsJSON, err := json.Marshal(legs)
+	if err != nil {
+		return "xxxxx", "", gwstatus.ToStatus(fmt.Errorf("failed to encode legs: %w", err))
+	}
+
+	_, txId, err := gwstatus.SubmitTransaction(contract, "TransferBatch", from, string(legsJSON), paymentRef)
+	if err != nil {
+		return txId, "", err
+	}
+	return txId, "Transaction Committed Successfully", nil
+}
+
+// Build the InvalidArgument status error of a bulk transaction, with a field violation for each invalid leg
+func invalidLegs(results []*cbdc.BulkTxLegResult) error {
+	badRequest := &errdetails.BadRequest{}
+	for i, result := range results {
+		if !result.Success {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       fmt.Sprintf("legs[%d]", i),
+				Description: result.Message,
+			})
+		}
+	}
+	st := status.New(codes.InvalidArgument, "invalid legs, nothing was transferred")
+	if detailed, err := st.WithDetails(badRequest); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// Total of the legs of a batch in minor units
+func batchTotal(legs []batchLeg) uint64 {
+	var total uint64
+	for _, leg := range legs {
+		total += leg.Amount
+	}
+	return total
+}
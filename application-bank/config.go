@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Defaults of the settings a bank config may leave out
+const (
+	DefaultChannelName   = "retail"
+	DefaultChaincodeName = "cbdc"
+	DefaultRBIEndpoint   = "localhost:7999"
+	DefaultStateDir      = "."
+)
+
+// bankConfig is the configuration of a bank node, read from a YAML file such as config/hdfc.yaml
+// Each setting can be overridden by an environment variable, see applyEnv
+// The certificate, key and TLS certificate paths are relative to cryptoPath
+type bankConfig struct {
+	MSPID           string `yaml:"mspId"`
+	CryptoPath      string `yaml:"cryptoPath"`
+	CertPath        string `yaml:"certPath"`
+	KeyPath         string `yaml:"keyPath"`
+	TLSCertPath     string `yaml:"tlsCertPath"`
+	PeerEndpoint    string `yaml:"peerEndpoint"`
+	GatewayPeer     string `yaml:"gatewayPeer"`
+	ChannelName     string `yaml:"channelName"`
+	ChaincodeName   string `yaml:"chaincodeName"`
+	ApplicationPort int    `yaml:"applicationPort"`
+	GatewayPort     int    `yaml:"gatewayPort"`
+	RBIEndpoint     string `yaml:"rbiEndpoint"`
+	StateDir        string `yaml:"stateDir"`
+
+	// Reserve account of the bank and the prefix of the addresses of its accounts, read from the bank registry on the ledger
+	BankAccount   string `yaml:"-"`
+	AddressPrefix string `yaml:"-"`
+}
+
+var Config *bankConfig
+
+// Load the config of the bank node from a YAML file and the environment
+// An empty path reads the config from the environment only
+func loadConfig(configPath string) (*bankConfig, error) {
+	config := &bankConfig{
+		ChannelName:   DefaultChannelName,
+		ChaincodeName: DefaultChaincodeName,
+		RBIEndpoint:   DefaultRBIEndpoint,
+		StateDir:      DefaultStateDir,
+	}
+	if configPath != "" {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config %s: %w", configPath, err)
+		}
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %w", configPath, err)
+		}
+	}
+	if err := config.applyEnv(); err != nil {
+		return nil, err
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	config.CertPath = path.Join(config.CryptoPath, config.CertPath)
+	config.KeyPath = path.Join(config.CryptoPath, config.KeyPath)
+	config.TLSCertPath = path.Join(config.CryptoPath, config.TLSCertPath)
+	return config, nil
+}
+
+// Override the settings that have an environment variable set
+func (c *bankConfig) applyEnv() error {
+	overrides := []struct {
+		name  string
+		value *string
+	}{
+		{"BANK_MSP_ID", &c.MSPID},
+		{"BANK_CRYPTO_PATH", &c.CryptoPath},
+		{"BANK_CERT_PATH", &c.CertPath},
+		{"BANK_KEY_PATH", &c.KeyPath},
+		{"BANK_TLS_CERT_PATH", &c.TLSCertPath},
+		{"BANK_PEER_ENDPOINT", &c.PeerEndpoint},
+		{"BANK_GATEWAY_PEER", &c.GatewayPeer},
+		{"CHANNEL_NAME", &c.ChannelName},
+		{"CHAINCODE_NAME", &c.ChaincodeName},
+		{"RBI_ENDPOINT", &c.RBIEndpoint},
+		{"BANK_STATE_DIR", &c.StateDir},
+	}
+	for _, override := range overrides {
+		if value := os.Getenv(override.name); value != "" {
+			*override.value = value
+		}
+	}
+
+	ports := []struct {
+		name  string
+		value *int
+	}{
+		{"BANK_APPLICATION_PORT", &c.ApplicationPort},
+		{"BANK_GATEWAY_PORT", &c.GatewayPort},
+	}
+	for _, port := range ports {
+		value := os.Getenv(port.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", port.name, value, err)
+		}
+		*port.value = parsed
+	}
+	return nil
+}
+
+// Check that the settings without a default are set
+func (c *bankConfig) validate() error {
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"mspId", c.MSPID},
+		{"cryptoPath", c.CryptoPath},
+		{"certPath", c.CertPath},
+		{"keyPath", c.KeyPath},
+		{"tlsCertPath", c.TLSCertPath},
+		{"peerEndpoint", c.PeerEndpoint},
+		{"gatewayPeer", c.GatewayPeer},
+	}
+	var errs []error
+	for _, setting := range required {
+		if setting.value == "" {
+			errs = append(errs, fmt.Errorf("%s must be set", setting.name))
+		}
+	}
+	if c.ApplicationPort <= 0 || c.GatewayPort <= 0 {
+		errs = append(errs, fmt.Errorf("applicationPort and gatewayPort must be set"))
+	} else if c.ApplicationPort == c.GatewayPort {
+		errs = append(errs, fmt.Errorf("applicationPort and gatewayPort must differ"))
+	}
+	return errors.Join(errs...)
+}
+
+// Get the path of a state file of the bank node in its state directory
+func (c *bankConfig) statePath(name string) string {
+	return path.Join(c.StateDir, name)
+}
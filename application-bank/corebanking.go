@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Opening balance of the deposit accounts of the in-memory core banking system in minor units
+// Override with the CORE_BANKING_OPENING_BALANCE environment variable
+const DefaultDepositOpeningBalance = 10000000
+
+// coreBanking is the adapter to the core banking system holding the deposit accounts of the customers
+// Debits and credits carry a reference, an operation retried with the same reference is applied once
+type coreBanking interface {
+	Debit(ctx context.Context, account string, amount uint64, reference string) error
+	Credit(ctx context.Context, account string, amount uint64, reference string) error
+	Balance(ctx context.Context, account string) (uint64, error)
+}
+
+var CoreBanking coreBanking
+
+// memoryCoreBanking is an in-memory stand-in for the core banking system
+// Deposit accounts are opened with the opening balance the first time they are used and are lost on restart
+type memoryCoreBanking struct {
+	mu             sync.Mutex
+	openingBalance uint64
+	balances       map[string]uint64
+	applied        map[string]bool
+}
+
+func newMemoryCoreBanking(openingBalance uint64) *memoryCoreBanking {
+	return &memoryCoreBanking{
+		openingBalance: openingBalance,
+		balances:       make(map[string]uint64),
+		applied:        make(map[string]bool),
+	}
+}
+
+func (b *memoryCoreBanking) Debit(ctx context.Context, account string, amount uint64, reference string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.applied[reference] {
+		return nil
+	}
+	balance := b.balance(account)
+	if balance < amount {
+		return fmt.Errorf("deposit account %s has insufficient funds: %d < %d", account, balance, amount)
+	}
+	b.balances[account] = balance - amount
+	b.applied[reference] = true
+	return nil
+}
+
+func (b *memoryCoreBanking) Credit(ctx context.Context, account string, amount uint64, reference string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.applied[reference] {
+		return nil
+	}
+	b.balances[account] = b.balance(account) + amount
+	b.applied[reference] = true
+	return nil
+}
+
+func (b *memoryCoreBanking) Balance(ctx context.Context, account string) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.balance(account), nil
+}
+
+// Get the balance of a deposit account, opening it if needed; b.mu must be held
+func (b *memoryCoreBanking) balance(account string) uint64 {
+	balance, ok := b.balances[account]
+	if !ok {
+		balance = b.openingBalance
+		b.balances[account] = balance
+	}
+	return balance
+}
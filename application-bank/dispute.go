@@ -0,0 +1,62 @@
+package main
+
+import (
+	cbdc "app/api"
+	"common/gwstatus"
+	"common/units"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// Dispute a transfer made from a customer account
+func raiseDispute(contract *client.Contract, txId, payer, payee string, amount uint64, reason string) (string, string, error) {
+	fmt.Printf("\n--> Submit Transaction: RaiseDispute, disputes %d of transaction %s\n", amount, txId)
+	result, disputeTxId, err := gwstatus.SubmitTransaction(contract, "RaiseDispute", txId, payer, payee, strconv.FormatUint(amount, 10), reason)
+	return disputeTxId, string(result), err
+}
+
+// disputeRecord mirrors the Dispute returned by the chaincode
+type disputeRecord struct {
+	ID           string `json:"id"`
+	TxID         string `json:"txId"`
+	Payer        string `json:"payer"`
+	Payee        string `json:"payee"`
+	Amount       uint64 `json:"amount"`
+	Reason       string `json:"reason"`
+	Status       string `json:"status"`
+	Resolution   string `json:"resolution"`
+	ReversalTxID string `json:"reversalTxId"`
+	Recovered    uint64 `json:"recovered"`
+	Shortfall    uint64 `json:"shortfall"`
+}
+
+// Get a dispute and its status
+func getDispute(contract *client.Contract, disputeId string) (*cbdc.Dispute, error) {
+	fmt.Printf("\n--> Evaluate Transaction: GetDispute, returns dispute %s\n", disputeId)
+	evaluateResult, err := contract.EvaluateTransaction("GetDispute", disputeId)
+	if err != nil {
+		return nil, gwstatus.ToStatus(err)
+	}
+
+	var dispute disputeRecord
+	if err := json.Unmarshal(evaluateResult, &dispute); err != nil {
+		return nil, gwstatus.ToStatus(fmt.Errorf("failed to parse dispute: %w", err))
+	}
+	return &cbdc.Dispute{
+		DisputeId:     dispute.ID,
+		TxId:          dispute.TxID,
+		Payer:         dispute.Payer,
+		Payee:         dispute.Payee,
+		Amount:        dispute.Amount,
+		DecimalAmount: units.Format(dispute.Amount),
+		Reason:        dispute.Reason,
+		Status:        dispute.Status,
+		Resolution:    dispute.Resolution,
+		ReversalTxId:  dispute.ReversalTxID,
+		Recovered:     dispute.Recovered,
+		Shortfall:     dispute.Shortfall,
+	}, nil
+}
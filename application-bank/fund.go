@@ -0,0 +1,432 @@
+package main
+
+import (
+	cbdc "app/api"
+	"common/gwstatus"
+	"common/units"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Steps of a fund saga
+// A fund is served from the bank reserve account, or mints the amount into it when the reserve cannot cover it,
+// and then transfers the amount to the customer account
+// If the transfer cannot be made, a minted amount is redeemed back to the central bank
+const (
+	FundPending        = "PENDING"
+	FundReserved       = "RESERVED"
+	FundMinted         = "MINTED"
+	FundCompleted      = "COMPLETED"
+	FundCompensated    = "COMPENSATED"
+	FundFailed         = "FAILED"
+	FundNeedsAttention = "NEEDS_ATTENTION"
+)
+
+const (
+	FundMaxAttempts = 5
+	FundRetryDelay  = time.Second
+	FundStepTimeout = 30 * time.Second
+	FundWaitTimeout = 20 * time.Second
+)
+
+// fundSaga is the persisted progress of a fund request
+type fundSaga struct {
+	ID               string    `json:"id"`
+	Account          string    `json:"account"`
+	Amount           uint64    `json:"amount"`
+	IdempotencyKey   string    `json:"idempotencyKey,omitempty"`
+	Status           string    `json:"status"`
+	MintTxID         string    `json:"mintTxId,omitempty"`
+	TransferTxID     string    `json:"transferTxId,omitempty"`
+	CompensationTxID string    `json:"compensationTxId,omitempty"`
+	Attempts         uint32    `json:"attempts"`
+	Message          string    `json:"message,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+
+	// Deposit account to link to the customer account once the fund completes, if any
+	BankAccountNumber string `json:"bankAccountNumber,omitempty"`
+	BankName          string `json:"bankName,omitempty"`
+}
+
+// paymentRef is the payment reference of the transfer, so a retried transfer is not paid twice
+func (f *fundSaga) paymentRef() string {
+	if f.IdempotencyKey != "" {
+		return f.IdempotencyKey
+	}
+	return f.ID
+}
+
+// done reports whether the saga reached a final status
+func (f *fundSaga) done() bool {
+	return f.Status != FundPending && f.Status != FundReserved && f.Status != FundMinted
+}
+
+// fundStore keeps the fund sagas in a JSON file so they survive a restart of the application
+type fundStore struct {
+	mu    sync.Mutex
+	path  string
+	sagas map[string]*fundSaga
+}
+
+var FundStore *fundStore
+
+// fundStartMu keeps concurrent requests with the same idempotency key from starting two sagas
+var fundStartMu sync.Mutex
+
+// Open the fund store, loading the sagas saved in the file if it exists
+func openFundStore(path string) (*fundStore, error) {
+	store := &fundStore{path: path, sagas: make(map[string]*fundSaga)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fund store %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &store.sagas); err != nil {
+		return nil, fmt.Errorf("failed to parse fund store %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// Save a saga, writing the whole store to its file
+func (s *fundStore) put(saga *fundSaga) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *saga
+	saved.UpdatedAt = time.Now().UTC()
+	s.sagas[saga.ID] = &saved
+
+	data, err := json.MarshalIndent(s.sagas, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fund store: %w", err)
+	}
+	// Replace the file in one step so a crash never leaves it half written
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write fund store %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace fund store %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// Get a copy of a saga by its id
+func (s *fundStore) get(id string) (*fundSaga, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saga, ok := s.sagas[id]
+	if !ok {
+		return nil, false
+	}
+	found := *saga
+	return &found, true
+}
+
+// Get a copy of the saga started for an idempotency key
+func (s *fundStore) getByIdempotencyKey(key string) (*fundSaga, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, saga := range s.sagas {
+		if saga.IdempotencyKey == key {
+			found := *saga
+			return &found, true
+		}
+	}
+	return nil, false
+}
+
+// Get copies of the sagas that have not reached a final status
+func (s *fundStore) unfinished() []*fundSaga {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sagas []*fundSaga
+	for _, saga := range s.sagas {
+		if !saga.done() {
+			found := *saga
+			sagas = append(sagas, &found)
+		}
+	}
+	return sagas
+}
+
+// Fund a customer account from the bank reserve account, linking a deposit account to it once funded if one is given
+// The saga runs in the background; the returned saga is its state once finished or after FundWaitTimeout
+func fund(contract *client.Contract, account string, amount uint64, idempotencyKey, bankAccountNumber, bankName string) (*fundSaga, error) {
+	saga, started, err := startFund(contract, account, amount, idempotencyKey, bankAccountNumber, bankName)
+	if err != nil || saga.done() {
+		return saga, err
+	}
+	if started {
+		go runFund(contract, saga)
+	}
+	return waitFund(saga.ID), nil
+}
+
+// Save a new saga for a fund request
+// A retried request gets the saga it started instead of minting again
+func startFund(contract *client.Contract, account string, amount uint64, idempotencyKey, bankAccountNumber, bankName string) (*fundSaga, bool, error) {
+	fundStartMu.Lock()
+	defer fundStartMu.Unlock()
+
+	if idempotencyKey != "" {
+		if saga, ok := FundStore.getByIdempotencyKey(idempotencyKey); ok {
+			return saga, false, nil
+		}
+		if txId, err := getPaymentReference(contract, Config.BankAccount, idempotencyKey); err == nil && txId != "" {
+			return &fundSaga{Account: account, Amount: amount, IdempotencyKey: idempotencyKey, Status: FundCompleted, TransferTxID: txId, Message: "Fund Already Committed"}, false, nil
+		}
+	}
+
+	now := time.Now().UTC()
+	saga := &fundSaga{
+		ID:             fmt.Sprintf("fund%d", now.UnixNano()),
+		Account:        account,
+		Amount:         amount,
+		IdempotencyKey: idempotencyKey,
+		Status:         FundPending,
+		CreatedAt:      now,
+
+		BankAccountNumber: bankAccountNumber,
+		BankName:          bankName,
+	}
+	if err := FundStore.put(saga); err != nil {
+		return nil, false, err
+	}
+	return saga, true, nil
+}
+
+// Wait for a saga that may be running to finish, up to FundWaitTimeout
+func waitFund(id string) *fundSaga {
+	deadline := time.Now().Add(FundWaitTimeout)
+	for {
+		saga, _ := FundStore.get(id)
+		if saga.done() || time.Now().After(deadline) {
+			return saga
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// Run the remaining steps of a saga, saving its progress after each step
+func runFund(contract *client.Contract, saga *fundSaga) {
+	reserved := false
+	if saga.Status == FundPending {
+		if reserved = Liquidity.reserve(saga.Amount); reserved {
+			saga.Status = FundReserved
+		} else {
+			mintFund(saga)
+		}
+		saveFund(saga)
+	}
+	if saga.Status == FundReserved || saga.Status == FundMinted {
+		transferFund(contract, saga)
+		saveFund(saga)
+	}
+	if reserved {
+		Liquidity.release(saga.Amount)
+	} else {
+		Liquidity.notify()
+	}
+	if saga.Status == FundCompleted && saga.BankAccountNumber != "" {
+		if err := linkDeposit(saga.Account, saga.BankAccountNumber, saga.BankName); err != nil {
+			log.Printf("fund %s completed but deposit account %s was not linked: %v", saga.ID, saga.BankAccountNumber, err)
+		}
+	}
+	log.Printf("fund %s of %d to %s: %s %s", saga.ID, saga.Amount, saga.Account, saga.Status, saga.Message)
+}
+
+// Mint the amount into the bank reserve account
+// Only failures that did not reach the central bank are retried; an unknown outcome is left to the operators
+func mintFund(saga *fundSaga) {
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), FundStepTimeout)
+		res, err := RBIClient.Mint(ctx, &cbdc.MintRequest{
+			Account: Config.BankAccount,
+			Amount:  saga.Amount,
+		})
+		cancel()
+
+		switch code := status.Code(err); {
+		case err == nil:
+			saga.Status = FundMinted
+			saga.MintTxID = res.TxId
+			saga.Message = ""
+			return
+		case code == codes.Unknown || code == codes.DeadlineExceeded || code == codes.Internal || code == codes.Canceled:
+			saga.Status = FundNeedsAttention
+			saga.MintTxID = gwstatus.TxID(err)
+			saga.Message = fmt.Sprintf("Mint outcome unknown: %s", status.Convert(err).Message())
+			return
+		case code != codes.Unavailable:
+			saga.Status = FundFailed
+			saga.MintTxID = gwstatus.TxID(err)
+			saga.Message = fmt.Sprintf("Mint rejected: %s", status.Convert(err).Message())
+			return
+		case attempt == FundMaxAttempts:
+			saga.Status = FundFailed
+			saga.Message = fmt.Sprintf("Failed to reach RBI due to error: %v", err)
+			return
+		}
+
+		saga.Message = fmt.Sprintf("Retrying mint: %v", err)
+		saveFund(saga)
+		time.Sleep(FundRetryDelay << (attempt - 1))
+	}
+}
+
+// Transfer the amount to the customer account, redeeming a minted amount if the transfer keeps failing
+// Only failures that left the transfer uncommitted are retried, the payment reference keeps a retry from paying twice
+// When the outcome is unknown the transfer may still commit, so the saga checks the ledger for it instead of compensating
+func transferFund(contract *client.Contract, saga *fundSaga) {
+	for attempt := 1; ; attempt++ {
+		saga.Attempts++
+		txId, _, _, _, msg, err := transferFrom(contract, Config.BankAccount, saga.Account, strconv.FormatUint(saga.Amount, 10), saga.paymentRef())
+		saga.TransferTxID = txId
+		if err == nil {
+			saga.Status = FundCompleted
+			saga.Message = msg
+			return
+		}
+		saga.Message = status.Convert(err).Message()
+
+		// A payment reference already in use means an earlier attempt may have committed the transfer
+		code := status.Code(err)
+		if code == codes.Unknown || code == codes.DeadlineExceeded || code == codes.AlreadyExists {
+			checkFundTransfer(contract, saga)
+			return
+		}
+		if (code != codes.Unavailable && code != codes.Aborted) || attempt == FundMaxAttempts {
+			break
+		}
+		saveFund(saga)
+		time.Sleep(FundRetryDelay << (attempt - 1))
+	}
+
+	// An amount served from the reserve stays in the reserve
+	if saga.Status == FundReserved {
+		saga.Status = FundFailed
+		saga.Message = fmt.Sprintf("Transfer failed: %s", saga.Message)
+		return
+	}
+
+	// Compensate by handing the minted amount back to the central bank
+	ctx, cancel := context.WithTimeout(context.Background(), FundStepTimeout)
+	defer cancel()
+	txId, _, err := redeem(ctx, saga.Amount)
+	if err != nil {
+		saga.Status = FundNeedsAttention
+		saga.Message = fmt.Sprintf("Transfer failed: %s; redeem failed: %s", saga.Message, status.Convert(err).Message())
+		return
+	}
+	saga.Status = FundCompensated
+	saga.CompensationTxID = txId
+	saga.Message = fmt.Sprintf("Transfer failed: %s; minted amount redeemed", saga.Message)
+}
+
+// Settle a transfer whose outcome is unknown from the payment reference it records on the ledger
+// A transfer that is not on the ledger yet may still commit, compensating it could pay the customer and redeem the amount,
+// so it is left to the operators
+func checkFundTransfer(contract *client.Contract, saga *fundSaga) {
+	txId, err := getPaymentReference(contract, Config.BankAccount, saga.paymentRef())
+	switch {
+	case err != nil:
+		saga.Status = FundNeedsAttention
+		saga.Message = fmt.Sprintf("Transfer outcome unknown: %s; payment reference check failed: %s", saga.Message, status.Convert(err).Message())
+	case txId != "":
+		saga.Status = FundCompleted
+		saga.TransferTxID = txId
+		saga.Message = "Transaction Already Committed"
+	default:
+		saga.Status = FundNeedsAttention
+		saga.Message = fmt.Sprintf("Transfer outcome unknown: %s", saga.Message)
+	}
+}
+
+// Save the progress of a saga, the saga keeps running if the store cannot be written
+func saveFund(saga *fundSaga) {
+	if err := FundStore.put(saga); err != nil {
+		log.Printf("failed to save fund %s: %v", saga.ID, err)
+	}
+}
+
+// Resume the sagas left unfinished by a previous run of the application
+// A saga stopped before its mint was confirmed cannot tell whether the amount was minted, so it is left to the operators
+func resumeFunds(contract *client.Contract) {
+	for _, saga := range FundStore.unfinished() {
+		if saga.Status == FundPending {
+			saga.Status = FundNeedsAttention
+			saga.Message = "Mint outcome unknown after restart"
+			saveFund(saga)
+			log.Printf("fund %s of %d to %s: %s %s", saga.ID, saga.Amount, saga.Account, saga.Status, saga.Message)
+			continue
+		}
+		runFund(contract, saga)
+	}
+}
+
+// Get the progress of a fund request
+func getFundStatus(fundId string) (*fundSaga, error) {
+	saga, ok := FundStore.get(fundId)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "fund %s not found", fundId)
+	}
+	return saga, nil
+}
+
+// Build the status error of a fund saga that ended without funding the account
+// Sagas that are still running or completed are reported with their response instead
+func fundError(saga *fundSaga) error {
+	code := codes.FailedPrecondition
+	switch saga.Status {
+	case FundCompensated:
+		code = codes.Aborted
+	case FundNeedsAttention:
+		code = codes.Unknown
+	}
+	st := status.New(code, saga.Message)
+	return gwstatus.WithErrorInfo(st, code, saga.Message, "FUND_"+saga.Status, saga.TransferTxID, "fundId", saga.ID, "mintTxId", saga.MintTxID, "compensationTxId", saga.CompensationTxID)
+}
+
+// Build the response reporting the progress of a fund saga
+func fundResponse(saga *fundSaga) *cbdc.FundResponse {
+	txId := saga.TransferTxID
+	if txId == "" {
+		txId = "xxxxx"
+	}
+	message := saga.Message
+	if !saga.done() && message == "" {
+		message = "Fund in progress, poll GetFundStatus for its completion"
+	}
+	return &cbdc.FundResponse{
+		TxId:             txId,
+		Account:          saga.Account,
+		Amount:           saga.Amount,
+		Success:          saga.Status == FundCompleted,
+		Message:          message,
+		DecimalAmount:    units.Format(saga.Amount),
+		Currency:         units.Currency,
+		FundId:           saga.ID,
+		Status:           saga.Status,
+		MintTxId:         saga.MintTxID,
+		CompensationTxId: saga.CompensationTxID,
+		Attempts:         saga.Attempts,
+	}
+}
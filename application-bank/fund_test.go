@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRunFund(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "peer unavailable")
+
+	tests := []struct {
+		name         string
+		reserve      uint64
+		transferErrs []error
+		paymentRefTx string
+		redeemErr    error
+		wantStatus   string
+		wantMessage  string
+		wantMinted   []uint64
+		wantRedeemed []uint64
+		wantAttempts uint32
+	}{
+		{
+			name:         "served from the reserve",
+			reserve:      5000,
+			wantStatus:   FundCompleted,
+			wantAttempts: 1,
+		},
+		{
+			name:         "minted when the reserve is short",
+			reserve:      500,
+			wantStatus:   FundCompleted,
+			wantMinted:   []uint64{1000},
+			wantAttempts: 1,
+		},
+		{
+			name:         "transfer retried while the peers are unavailable",
+			reserve:      5000,
+			transferErrs: []error{unavailable},
+			wantStatus:   FundCompleted,
+			wantAttempts: 2,
+		},
+		{
+			name:         "minted amount redeemed when the transfer is rejected",
+			reserve:      500,
+			transferErrs: []error{errors.New("recipient account hdfc1alice is frozen: court order")},
+			wantStatus:   FundCompensated,
+			wantMessage:  "recipient account hdfc1alice is frozen: court order; minted amount redeemed",
+			wantMinted:   []uint64{1000},
+			wantRedeemed: []uint64{1000},
+			wantAttempts: 1,
+		},
+		{
+			name:         "amount served from the reserve stays there when the transfer is rejected",
+			reserve:      5000,
+			transferErrs: []error{errors.New("recipient account hdfc1alice is frozen: court order")},
+			wantStatus:   FundFailed,
+			wantMessage:  "Transfer failed: recipient account hdfc1alice is frozen: court order",
+			wantAttempts: 1,
+		},
+		{
+			name:         "redeem failing after a rejected transfer",
+			reserve:      500,
+			transferErrs: []error{errors.New("recipient account hdfc1alice is closed")},
+			redeemErr:    status.Error(codes.Unavailable, "RBI unavailable"),
+			wantStatus:   FundNeedsAttention,
+			wantMessage:  "redeem failed: RBI unavailable",
+			wantMinted:   []uint64{1000},
+			wantAttempts: 1,
+		},
+		{
+			name:         "unknown outcome of a transfer that committed",
+			reserve:      500,
+			transferErrs: []error{status.Error(codes.Unknown, "connection reset")},
+			paymentRefTx: "tx42",
+			wantStatus:   FundCompleted,
+			wantMessage:  "Transaction Already Committed",
+			wantMinted:   []uint64{1000},
+			wantAttempts: 1,
+		},
+		{
+			name:         "unknown outcome of a transfer not on the ledger is not compensated",
+			reserve:      500,
+			transferErrs: []error{status.Error(codes.Unknown, "connection reset")},
+			wantStatus:   FundNeedsAttention,
+			wantMessage:  "Transfer outcome unknown",
+			wantMinted:   []uint64{1000},
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbi := setupBank(t)
+			rbi.redeemErr = tt.redeemErr
+
+			transferErrs := tt.transferErrs
+			paymentRefTx := ""
+			contract, gateway := newTestContract(t, map[string]chaincodeFunc{
+				"GetBalanceBuckets": func(args ...string) (string, error) { return balanceResult(tt.reserve), nil },
+				"GetPaymentReference": func(args ...string) (string, error) {
+					return paymentRefTx, nil
+				},
+				"TransferFrom": func(args ...string) (string, error) {
+					if len(transferErrs) > 0 {
+						err := transferErrs[0]
+						transferErrs = transferErrs[1:]
+						// A transfer whose outcome is unknown is found on the ledger by its payment reference
+						paymentRefTx = tt.paymentRefTx
+						return "", err
+					}
+					return "", nil
+				},
+			})
+			Liquidity = &liquidityManager{contract: contract, config: liquidityConfig{}, wake: make(chan struct{}, 1)}
+
+			saga, started, err := startFund(contract, "hdfc1alice", 1000, "", "", "")
+			if err != nil || !started {
+				t.Fatalf("startFund() = %v, %v, want a new saga", started, err)
+			}
+			runFund(contract, saga)
+
+			saved, _ := FundStore.get(saga.ID)
+			if saved.Status != tt.wantStatus {
+				t.Fatalf("status = %s (%s), want %s", saved.Status, saved.Message, tt.wantStatus)
+			}
+			if !strings.Contains(saved.Message, tt.wantMessage) {
+				t.Errorf("message = %q, want it to contain %q", saved.Message, tt.wantMessage)
+			}
+			if saved.Attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", saved.Attempts, tt.wantAttempts)
+			}
+			if got := gateway.count("TransferFrom"); got != int(tt.wantAttempts) {
+				t.Errorf("%d transfers submitted, want %d", got, tt.wantAttempts)
+			}
+			if !slices.Equal(rbi.minted, tt.wantMinted) {
+				t.Errorf("minted %v, want %v", rbi.minted, tt.wantMinted)
+			}
+			if !slices.Equal(rbi.redeemed, tt.wantRedeemed) {
+				t.Errorf("redeemed %v, want %v", rbi.redeemed, tt.wantRedeemed)
+			}
+			if tt.wantStatus == FundCompensated && saved.CompensationTxID != "redeem1" {
+				t.Errorf("compensation transaction = %q, want redeem1", saved.CompensationTxID)
+			}
+			if Liquidity.inFlight != 0 {
+				t.Errorf("%d of the reserve still set aside", Liquidity.inFlight)
+			}
+		})
+	}
+}
+
+func TestStartFundIdempotencyKey(t *testing.T) {
+	setupBank(t)
+	paymentRefTx := ""
+	contract, _ := newTestContract(t, map[string]chaincodeFunc{
+		"GetPaymentReference": func(args ...string) (string, error) { return paymentRefTx, nil },
+	})
+
+	saga, started, err := startFund(contract, "hdfc1alice", 1000, "order-1", "", "")
+	if err != nil || !started {
+		t.Fatalf("startFund() = %v, %v, want a new saga", started, err)
+	}
+
+	// A retried request gets the saga it started
+	retried, started, err := startFund(contract, "hdfc1alice", 1000, "order-1", "", "")
+	if err != nil || started || retried.ID != saga.ID {
+		t.Fatalf("retried startFund() = %v, %v, %v, want saga %s", retried, started, err, saga.ID)
+	}
+
+	// A request whose transfer committed before the store was lost is reported as completed
+	paymentRefTx = "tx42"
+	committed, started, err := startFund(contract, "hdfc1alice", 1000, "order-2", "", "")
+	if err != nil || started {
+		t.Fatalf("startFund() = %v, %v, want no new saga", started, err)
+	}
+	if committed.Status != FundCompleted || committed.TransferTxID != "tx42" {
+		t.Fatalf("saga = %s with transfer %s, want %s with transfer tx42", committed.Status, committed.TransferTxID, FundCompleted)
+	}
+}
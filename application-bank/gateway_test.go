@@ -0,0 +1,242 @@
+package main
+
+import (
+	cbdc "app/api"
+	"common/gwstatus"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// chaincodeFunc serves a chaincode function in tests
+// A plain error is returned by the chaincode, a status error is a failure of the network
+type chaincodeFunc func(args ...string) (string, error)
+
+// fakeGateway is a Fabric Gateway serving the chaincode functions of a test, every transaction commits
+type fakeGateway struct {
+	mu        sync.Mutex
+	functions map[string]chaincodeFunc
+	calls     []string
+}
+
+// Connect a contract to a fake gateway serving the given chaincode functions
+func newTestContract(t *testing.T, functions map[string]chaincodeFunc) (*client.Contract, *fakeGateway) {
+	fake := &fakeGateway{functions: functions}
+	gw, err := client.Connect(testIdentity{}, client.WithSign(func(digest []byte) ([]byte, error) { return digest, nil }), client.WithClientConnection(fake))
+	if err != nil {
+		t.Fatalf("failed to connect to the fake gateway: %v", err)
+	}
+	t.Cleanup(func() { gw.Close() })
+	return gw.GetNetwork("retail").GetContract("cbdc"), fake
+}
+
+// Get the chaincode calls served so far, each as its function name and arguments separated by spaces
+func (g *fakeGateway) served() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.calls...)
+}
+
+// Count the calls of a chaincode function served so far
+func (g *fakeGateway) count(function string) int {
+	count := 0
+	for _, call := range g.served() {
+		if strings.HasPrefix(call, function+" ") || call == function {
+			count++
+		}
+	}
+	return count
+}
+
+func (g *fakeGateway) Invoke(ctx context.Context, method string, args any, reply any, opts ...grpc.CallOption) error {
+	switch method {
+	case gateway.Gateway_Evaluate_FullMethodName:
+		result, err := g.call(args.(*gateway.EvaluateRequest).GetProposedTransaction())
+		if err != nil {
+			return err
+		}
+		proto.Merge(reply.(proto.Message), &gateway.EvaluateResponse{Result: &peer.Response{Status: 200, Payload: []byte(result)}})
+	case gateway.Gateway_Endorse_FullMethodName:
+		request := args.(*gateway.EndorseRequest)
+		result, err := g.call(request.GetProposedTransaction())
+		if err != nil {
+			return err
+		}
+		envelope, err := preparedTransaction(request.GetChannelId(), request.GetTransactionId(), result)
+		if err != nil {
+			return err
+		}
+		proto.Merge(reply.(proto.Message), &gateway.EndorseResponse{PreparedTransaction: envelope})
+	case gateway.Gateway_Submit_FullMethodName:
+	case gateway.Gateway_CommitStatus_FullMethodName:
+		proto.Merge(reply.(proto.Message), &gateway.CommitStatusResponse{Result: peer.TxValidationCode_VALID, BlockNumber: 1})
+	default:
+		return status.Errorf(codes.Unimplemented, "method %s is not served by the fake gateway", method)
+	}
+	return nil
+}
+
+func (g *fakeGateway) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method %s is not served by the fake gateway", method)
+}
+
+// Serve the chaincode function invoked by a proposal
+// Chaincode errors are reported as the peers report them, in a gateway.ErrorDetail
+func (g *fakeGateway) call(signedProposal *peer.SignedProposal) (string, error) {
+	function, args, err := invocation(signedProposal)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	g.mu.Lock()
+	g.calls = append(g.calls, strings.Join(append([]string{function}, args...), " "))
+	serve, ok := g.functions[function]
+	g.mu.Unlock()
+	if !ok {
+		return "", chaincodeError(fmt.Errorf("function %s not found", function))
+	}
+
+	result, err := serve(args...)
+	if _, isStatus := status.FromError(err); err != nil && !isStatus {
+		return "", chaincodeError(err)
+	}
+	return result, err
+}
+
+// Build the status of a chaincode error as the gateway returns it
+func chaincodeError(err error) error {
+	st, _ := status.New(codes.Aborted, "failed to endorse transaction, see attached details for more info").WithDetails(&gateway.ErrorDetail{
+		Address: "peer0.rbi.example.com:7051",
+		MspId:   "RBIMSP",
+		Message: gwstatus.ChaincodeResponseText + "500, " + err.Error(),
+	})
+	return st.Err()
+}
+
+// Get the function name and the arguments of the chaincode invocation of a proposal
+func invocation(signedProposal *peer.SignedProposal) (string, []string, error) {
+	var proposal peer.Proposal
+	if err := proto.Unmarshal(signedProposal.GetProposalBytes(), &proposal); err != nil {
+		return "", nil, err
+	}
+	var payload peer.ChaincodeProposalPayload
+	if err := proto.Unmarshal(proposal.GetPayload(), &payload); err != nil {
+		return "", nil, err
+	}
+	var spec peer.ChaincodeInvocationSpec
+	if err := proto.Unmarshal(payload.GetInput(), &spec); err != nil {
+		return "", nil, err
+	}
+
+	input := spec.GetChaincodeSpec().GetInput().GetArgs()
+	if len(input) == 0 {
+		return "", nil, fmt.Errorf("proposal has no function name")
+	}
+	args := make([]string, 0, len(input)-1)
+	for _, arg := range input[1:] {
+		args = append(args, string(arg))
+	}
+	return string(input[0]), args, nil
+}
+
+// Build the envelope of an endorsed transaction with the result of its chaincode function
+func preparedTransaction(channelID, txID, result string) (*common.Envelope, error) {
+	chaincodeAction, err := proto.Marshal(&peer.ChaincodeAction{Response: &peer.Response{Status: 200, Payload: []byte(result)}})
+	if err != nil {
+		return nil, err
+	}
+	responsePayload, err := proto.Marshal(&peer.ProposalResponsePayload{Extension: chaincodeAction})
+	if err != nil {
+		return nil, err
+	}
+	actionPayload, err := proto.Marshal(&peer.ChaincodeActionPayload{Action: &peer.ChaincodeEndorsedAction{ProposalResponsePayload: responsePayload}})
+	if err != nil {
+		return nil, err
+	}
+	transaction, err := proto.Marshal(&peer.Transaction{Actions: []*peer.TransactionAction{{Payload: actionPayload}}})
+	if err != nil {
+		return nil, err
+	}
+	channelHeader, err := proto.Marshal(&common.ChannelHeader{ChannelId: channelID, TxId: txID})
+	if err != nil {
+		return nil, err
+	}
+	payload, err := proto.Marshal(&common.Payload{Header: &common.Header{ChannelHeader: channelHeader}, Data: transaction})
+	if err != nil {
+		return nil, err
+	}
+	return &common.Envelope{Payload: payload}, nil
+}
+
+// testIdentity is the client identity of the bank in tests
+type testIdentity struct{}
+
+func (testIdentity) MspID() string       { return "HDFCBankMSP" }
+func (testIdentity) Credentials() []byte { return []byte("certificate") }
+
+// fakeRBI serves the Mint and Redeem calls of the bank to the RBI node in tests
+type fakeRBI struct {
+	cbdc.CBDCClient
+
+	mu        sync.Mutex
+	mintErr   error
+	redeemErr error
+	minted    []uint64
+	redeemed  []uint64
+}
+
+func (r *fakeRBI) Mint(ctx context.Context, in *cbdc.MintRequest, opts ...grpc.CallOption) (*cbdc.MintResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mintErr != nil {
+		return nil, r.mintErr
+	}
+	r.minted = append(r.minted, in.Amount)
+	return &cbdc.MintResponse{TxId: fmt.Sprintf("mint%d", len(r.minted)), Success: true, Message: "Minted Successfully"}, nil
+}
+
+func (r *fakeRBI) Redeem(ctx context.Context, in *cbdc.RedeemRequest, opts ...grpc.CallOption) (*cbdc.RedeemResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.redeemErr != nil {
+		return nil, r.redeemErr
+	}
+	r.redeemed = append(r.redeemed, in.Amount)
+	return &cbdc.RedeemResponse{TxId: fmt.Sprintf("redeem%d", len(r.redeemed)), Amount: in.Amount, Success: true}, nil
+}
+
+// Configure the bank node of a test with the reserve account hdfc.cbdc
+func setupBank(t *testing.T) *fakeRBI {
+	Config = &bankConfig{MSPID: "HDFCBankMSP", BankAccount: "hdfc.cbdc", AddressPrefix: "hdfc"}
+	Banks = &bankRegistry{prefixes: []string{"hdfc", "axis"}}
+	rbi := &fakeRBI{}
+	RBIClient = rbi
+	CoreBanking = newMemoryCoreBanking(10000)
+
+	var err error
+	FundStore, err = openFundStore(t.TempDir() + "/funds.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	DepositLinks, err = openDepositLinkStore(t.TempDir() + "/links.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rbi
+}
+
+// balanceResult is the GetBalanceBuckets result of a balance without purpose-bound funds
+func balanceResult(balance uint64) string {
+	return fmt.Sprintf(`{"total":"%d","free":"%d","purposeBound":[]}`, balance, balance)
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	cbdc "app/api"
+	"common/gwstatus"
+	"common/units"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"google.golang.org/grpc/status"
+)
+
+// Default reserve band of the bank reserve account in minor units
+// Override with the RESERVE_LOW_WATERMARK, RESERVE_TARGET and RESERVE_HIGH_WATERMARK environment variables
+const (
+	DefaultReserveLowWatermark  = 100000
+	DefaultReserveTarget        = 500000
+	DefaultReserveHighWatermark = 1000000
+	LiquidityInterval           = 30 * time.Second
+)
+
+// Actions of the liquidity manager
+const (
+	LiquidityTopUp  = "TOP_UP"
+	LiquidityRedeem = "REDEEM"
+)
+
+// liquidityConfig is the reserve band the liquidity manager keeps the reserve account in
+type liquidityConfig struct {
+	LowWatermark  uint64
+	Target        uint64
+	HighWatermark uint64
+}
+
+func (c liquidityConfig) validate() error {
+	if c.LowWatermark > c.Target || c.Target > c.HighWatermark {
+		return fmt.Errorf("watermarks must satisfy low <= target <= high, got %d, %d and %d", c.LowWatermark, c.Target, c.HighWatermark)
+	}
+	return nil
+}
+
+// liquidityManager serves fund requests from the bank reserve account and keeps its balance within the reserve band
+// Below the low watermark it asks RBI to mint up to the target, above the high watermark it redeems down to the target
+type liquidityManager struct {
+	mu       sync.Mutex
+	contract *client.Contract
+	config   liquidityConfig
+	wake     chan struct{}
+
+	// Amount set aside for fund requests whose transfer has not committed yet
+	inFlight uint64
+	// Amount of the redeem in progress, which fund requests cannot set aside until it is over
+	redeeming uint64
+
+	balance           uint64
+	lastChecked       time.Time
+	lastAction        string
+	lastActionAmount  uint64
+	lastActionTxID    string
+	lastActionSuccess bool
+	message           string
+}
+
+var Liquidity *liquidityManager
+
+// Create the liquidity manager with the reserve band of the environment
+func newLiquidityManager(contract *client.Contract) (*liquidityManager, error) {
+	var config liquidityConfig
+	var err error
+	if config.LowWatermark, err = envAmount("RESERVE_LOW_WATERMARK", DefaultReserveLowWatermark); err != nil {
+		return nil, err
+	}
+	if config.Target, err = envAmount("RESERVE_TARGET", DefaultReserveTarget); err != nil {
+		return nil, err
+	}
+	if config.HighWatermark, err = envAmount("RESERVE_HIGH_WATERMARK", DefaultReserveHighWatermark); err != nil {
+		return nil, err
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &liquidityManager{contract: contract, config: config, wake: make(chan struct{}, 1)}, nil
+}
+
+// Read an amount in minor units from an environment variable
+func envAmount(name string, defaultAmount uint64) (uint64, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultAmount, nil
+	}
+	amount, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+	return amount, nil
+}
+
+// Rebalance the reserve every LiquidityInterval, or sooner when a fund request changed it
+func (m *liquidityManager) run() {
+	ticker := time.NewTicker(LiquidityInterval)
+	defer ticker.Stop()
+	for {
+		m.rebalance()
+		select {
+		case <-ticker.C:
+		case <-m.wake:
+		}
+	}
+}
+
+// Ask for a rebalance without waiting for it
+func (m *liquidityManager) notify() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Set aside an amount of the reserve for a fund request, reporting false if the reserve cannot cover it
+func (m *liquidityManager) reserve(amount uint64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, free, _, err := getClientBalance(m.contract, Config.BankAccount)
+	if err != nil {
+		log.Printf("failed to read reserve balance: %v", err)
+		return false
+	}
+	m.balance = free
+	held := m.inFlight + m.redeeming
+	if free < held || free-held < amount {
+		return false
+	}
+	m.inFlight += amount
+	return true
+}
+
+// Release an amount set aside by reserve once its transfer is over
+func (m *liquidityManager) release(amount uint64) {
+	m.mu.Lock()
+	m.inFlight -= min(amount, m.inFlight)
+	m.mu.Unlock()
+	m.notify()
+}
+
+// Replace the reserve band and rebalance against it
+func (m *liquidityManager) setConfig(config liquidityConfig) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.config = config
+	m.mu.Unlock()
+	log.Printf("reserve band set to low %d, target %d, high %d", config.LowWatermark, config.Target, config.HighWatermark)
+	m.notify()
+	return nil
+}
+
+// Top the reserve up to the target below the low watermark, or redeem the surplus above the high watermark
+// The amount set aside for fund requests does not count as available
+// The lock is not held across the call to RBI, fund requests keep being served while the redeemed amount is kept from them
+func (m *liquidityManager) rebalance() {
+	checked := time.Now()
+	_, free, _, err := getClientBalance(m.contract, Config.BankAccount)
+
+	m.mu.Lock()
+	m.lastChecked = checked
+	if err != nil {
+		m.message = fmt.Sprintf("Failed to read reserve balance: %v", err)
+		m.mu.Unlock()
+		return
+	}
+	m.balance = free
+	available := free - min(free, m.inFlight)
+	var action string
+	var amount uint64
+	switch {
+	case available < m.config.LowWatermark:
+		action, amount = LiquidityTopUp, m.config.Target-available
+	case available > m.config.HighWatermark:
+		action, amount = LiquidityRedeem, available-m.config.Target
+		m.redeeming = amount
+	}
+	if action != "" {
+		m.message = fmt.Sprintf("%s of %d in progress", action, amount)
+	}
+	m.mu.Unlock()
+	if action == "" {
+		return
+	}
+
+	var txId, msg string
+	var success bool
+	ctx, cancel := context.WithTimeout(context.Background(), FundStepTimeout)
+	defer cancel()
+	if action == LiquidityTopUp {
+		res, err := RBIClient.Mint(ctx, &cbdc.MintRequest{
+			Account: Config.BankAccount,
+			Amount:  amount,
+		})
+		if err != nil {
+			txId, msg = gwstatus.TxID(err), fmt.Sprintf("Mint failed: %s", status.Convert(err).Message())
+		} else {
+			txId, success, msg = res.TxId, true, res.Message
+		}
+	} else {
+		txId, _, err = redeem(ctx, amount)
+		if err != nil {
+			msg = fmt.Sprintf("Redeem failed: %s", status.Convert(err).Message())
+		} else {
+			success, msg = true, "Redeemed Successfully"
+		}
+	}
+	log.Printf("reserve %s of %d with %d available: %s", action, amount, available, msg)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.redeeming = 0
+	m.lastAction = action
+	m.lastActionAmount = amount
+	m.lastActionTxID = txId
+	m.lastActionSuccess = success
+	m.message = msg
+}
+
+// Build the response reporting the reserve band and the last action of the liquidity manager
+func (m *liquidityManager) response() *cbdc.LiquidityResponse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var lastChecked int64
+	if !m.lastChecked.IsZero() {
+		lastChecked = m.lastChecked.Unix()
+	}
+	return &cbdc.LiquidityResponse{
+		Success:               true,
+		Message:               m.message,
+		Account:               Config.BankAccount,
+		ReserveBalance:        m.balance,
+		DecimalReserveBalance: units.Format(m.balance),
+		InFlight:              m.inFlight,
+		LowWatermark:          m.config.LowWatermark,
+		Target:                m.config.Target,
+		HighWatermark:         m.config.HighWatermark,
+		LastAction:            m.lastAction,
+		LastActionAmount:      m.lastActionAmount,
+		LastActionTxId:        m.lastActionTxID,
+		LastActionSuccess:     m.lastActionSuccess,
+		LastChecked:           lastChecked,
+	}
+}
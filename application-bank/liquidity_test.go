@@ -0,0 +1,149 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRebalance(t *testing.T) {
+	band := liquidityConfig{LowWatermark: 1000, Target: 5000, HighWatermark: 10000}
+
+	tests := []struct {
+		name         string
+		balance      uint64
+		inFlight     uint64
+		mintErr      error
+		wantAction   string
+		wantAmount   uint64
+		wantSuccess  bool
+		wantMessage  string
+		wantMinted   []uint64
+		wantRedeemed []uint64
+	}{
+		{
+			name:        "below the low watermark",
+			balance:     400,
+			wantAction:  LiquidityTopUp,
+			wantAmount:  4600,
+			wantSuccess: true,
+			wantMinted:  []uint64{4600},
+		},
+		{
+			name:    "at the low watermark",
+			balance: 1000,
+		},
+		{
+			name:    "at the high watermark",
+			balance: 10000,
+		},
+		{
+			name:         "above the high watermark",
+			balance:      12000,
+			wantAction:   LiquidityRedeem,
+			wantAmount:   7000,
+			wantSuccess:  true,
+			wantRedeemed: []uint64{7000},
+		},
+		{
+			name:        "amount set aside for fund requests is not available",
+			balance:     3000,
+			inFlight:    2500,
+			wantAction:  LiquidityTopUp,
+			wantAmount:  4500,
+			wantSuccess: true,
+			wantMinted:  []uint64{4500},
+		},
+		{
+			name:        "mint rejected",
+			balance:     400,
+			mintErr:     status.Error(codes.PermissionDenied, "client is not authorized to mint new tokens"),
+			wantAction:  LiquidityTopUp,
+			wantAmount:  4600,
+			wantMessage: "Mint failed: client is not authorized to mint new tokens",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbi := setupBank(t)
+			rbi.mintErr = tt.mintErr
+			contract, _ := newTestContract(t, map[string]chaincodeFunc{
+				"GetBalanceBuckets": func(args ...string) (string, error) { return balanceResult(tt.balance), nil },
+			})
+			m := &liquidityManager{contract: contract, config: band, wake: make(chan struct{}, 1), inFlight: tt.inFlight}
+
+			m.rebalance()
+
+			res := m.response()
+			if res.LastAction != tt.wantAction || res.LastActionAmount != tt.wantAmount || res.LastActionSuccess != tt.wantSuccess {
+				t.Fatalf("last action = %s of %d (success %v), want %s of %d (success %v)",
+					res.LastAction, res.LastActionAmount, res.LastActionSuccess, tt.wantAction, tt.wantAmount, tt.wantSuccess)
+			}
+			if !strings.Contains(res.Message, tt.wantMessage) {
+				t.Errorf("message = %q, want it to contain %q", res.Message, tt.wantMessage)
+			}
+			if res.ReserveBalance != tt.balance {
+				t.Errorf("reserve balance = %d, want %d", res.ReserveBalance, tt.balance)
+			}
+			if !slices.Equal(rbi.minted, tt.wantMinted) {
+				t.Errorf("minted %v, want %v", rbi.minted, tt.wantMinted)
+			}
+			if !slices.Equal(rbi.redeemed, tt.wantRedeemed) {
+				t.Errorf("redeemed %v, want %v", rbi.redeemed, tt.wantRedeemed)
+			}
+			if m.redeeming != 0 {
+				t.Errorf("%d still held back for a redeem", m.redeeming)
+			}
+		})
+	}
+}
+
+func TestReserve(t *testing.T) {
+	setupBank(t)
+	contract, _ := newTestContract(t, map[string]chaincodeFunc{
+		"GetBalanceBuckets": func(args ...string) (string, error) { return balanceResult(5000), nil },
+	})
+	m := &liquidityManager{contract: contract, wake: make(chan struct{}, 1)}
+
+	if !m.reserve(3000) {
+		t.Fatal("reserve(3000) = false with 5000 free, want true")
+	}
+	if m.reserve(2500) {
+		t.Fatal("reserve(2500) = true with 3000 of 5000 set aside, want false")
+	}
+
+	// The amount being redeemed cannot be set aside either
+	m.redeeming = 1500
+	if m.reserve(1000) {
+		t.Fatal("reserve(1000) = true with 3000 set aside and 1500 being redeemed, want false")
+	}
+	if !m.reserve(500) {
+		t.Fatal("reserve(500) = false with 500 left, want true")
+	}
+
+	m.release(3500)
+	if m.inFlight != 0 {
+		t.Fatalf("%d still set aside after the release", m.inFlight)
+	}
+}
+
+func TestSetLiquidityConfig(t *testing.T) {
+	m := &liquidityManager{wake: make(chan struct{}, 1)}
+
+	err := m.setConfig(liquidityConfig{LowWatermark: 5000, Target: 1000, HighWatermark: 10000})
+	if err == nil || !strings.Contains(err.Error(), "low <= target <= high") {
+		t.Fatalf("setConfig() with the target below the low watermark = %v, want an error", err)
+	}
+
+	band := liquidityConfig{LowWatermark: 1000, Target: 1000, HighWatermark: 1000}
+	if err := m.setConfig(band); err != nil {
+		t.Fatalf("setConfig() = %v, want nil", err)
+	}
+	if m.config != band {
+		t.Fatalf("config = %+v, want %+v", m.config, band)
+	}
+}
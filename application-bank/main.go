@@ -0,0 +1,570 @@
+package main
+
+import (
+	"common/gwstatus"
+	"common/units"
+	"context"
+	"flag"
+	"fmt"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/credentials/insecure"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	cbdc "app/api"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-gateway/pkg/hash"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// The bank the node serves is set by its config, e.g. go run . -config config/hdfc.yaml
+// State files are kept in the state directory of the config, so the nodes of several banks can share a working directory
+const (
+	HistoryPageSize = 20
+	FundStorePath   = "fund-sagas.json"
+	DepositLinkPath = "deposit-links.json"
+	SweepCheckpoint = "sweep-checkpoint.json"
+)
+
+type server struct {
+	cbdc.CBDCServer
+}
+
+var (
+	Contract  *client.Contract
+	RBIClient cbdc.CBDCClient
+)
+
+func main() {
+	configPath := flag.String("config", os.Getenv("BANK_CONFIG"), "path of the YAML config of the bank")
+	flag.Parse()
+
+	var err error
+	Config, err = loadConfig(*configPath)
+	if err != nil {
+		log.Fatalln("Failed to load bank config", err)
+	}
+	if err := os.MkdirAll(Config.StateDir, 0o700); err != nil {
+		log.Fatalln("Failed to create state directory", err)
+	}
+	log.Printf("Starting bank node of %s", Config.MSPID)
+
+	// The gRPC client connection should be shared by all Gateway connections to this endpoint
+	clientConnection := newGrpcConnection()
+	defer clientConnection.Close()
+
+	id := newIdentity()
+	sign := newSign()
+
+	// Create a Gateway connection for a specific client identity
+	gw, err := client.Connect(
+		id,
+		client.WithSign(sign),
+		client.WithHash(hash.SHA256),
+		client.WithClientConnection(clientConnection),
+		// Default timeouts for different gRPC calls
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(1*time.Minute),
+	)
+	if err != nil {
+		panic(err)
+	}
+	defer gw.Close()
+
+	network := gw.GetNetwork(Config.ChannelName)
+	contract := network.GetContract(Config.ChaincodeName)
+	Contract = contract
+	if _, err := getCurrentClientId(contract); err != nil {
+		log.Fatalln("Failed to reach the chaincode", err)
+	}
+	if err := units.LoadDecimals(contract); err != nil {
+		log.Fatalln("Failed to load token decimals", err)
+	}
+
+	// The reserve account and address prefix of the bank and the banks of the network come from the ledger registry
+	Banks, err = loadBankRegistry(contract)
+	if err != nil {
+		log.Fatalln("Failed to load bank registry", err)
+	}
+	log.Printf("Bank node of %s serves reserve account %s and addresses prefixed %s", Config.MSPID, Config.BankAccount, Config.AddressPrefix)
+
+	FundStore, err = openFundStore(Config.statePath(FundStorePath))
+	if err != nil {
+		log.Fatalln("Failed to open fund store", err)
+	}
+	Liquidity, err = newLiquidityManager(contract)
+	if err != nil {
+		log.Fatalln("Failed to configure reserve band", err)
+	}
+	DepositLinks, err = openDepositLinkStore(Config.statePath(DepositLinkPath))
+	if err != nil {
+		log.Fatalln("Failed to open deposit link store", err)
+	}
+	Sweeper, err = newSweepEngine(contract)
+	if err != nil {
+		log.Fatalln("Failed to configure sweep band", err)
+	}
+	openingBalance, err := envAmount("CORE_BANKING_OPENING_BALANCE", DefaultDepositOpeningBalance)
+	if err != nil {
+		log.Fatalln("Failed to configure core banking", err)
+	}
+	CoreBanking = newMemoryCoreBanking(openingBalance)
+
+	// Set up a gRPC Server
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", Config.ApplicationPort))
+	if err != nil {
+		log.Fatalf("Failed to listen %v", err)
+	}
+	var grpcServer = grpc.NewServer()
+	cbdc.RegisterCBDCServer(grpcServer, &server{})
+	go func() {
+		if errServer := grpcServer.Serve(lis); errServer != nil {
+			log.Fatalf("failed to serve: %v", errServer)
+		}
+	}()
+
+	// Connect to RBI Server
+	rbiConn, err := grpc.NewClient(Config.RBIEndpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalln("Failed to Dial RBI Server", err)
+	}
+	rbiClient := cbdc.NewCBDCClient(rbiConn)
+	RBIClient = rbiClient
+
+	// Pick up the banks onboarded while the node runs
+	go Banks.run()
+
+	// Resume the fund requests left unfinished by the previous run
+	go resumeFunds(contract)
+
+	// Keep the reserve account within its band
+	go Liquidity.run()
+
+	// Sweep the wallets linked to deposit accounts as transfers commit
+	go Sweeper.run(network, Config.ChaincodeName, Config.statePath(SweepCheckpoint))
+
+	// Connect gRPC-Gateway to your gRPC-Server
+	conn, err := grpc.NewClient(fmt.Sprintf("0.0.0.0:%d", Config.ApplicationPort), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalln("Failed to Dial Server", err)
+	}
+	gwmux := runtime.NewServeMux()
+	err = cbdc.RegisterCBDCHandler(context.Background(), gwmux, conn)
+	if err != nil {
+		log.Fatalln("Failed to register gateway", err)
+	}
+	gwServer := &http.Server{Addr: fmt.Sprintf(":%d", Config.GatewayPort), Handler: gwmux}
+	log.Printf("Serving gRPC-Gateway on http://0.0.0.0:%d\n", Config.GatewayPort)
+	log.Fatalln(gwServer.ListenAndServe())
+}
+
+func (s *server) GetBalance(ctx context.Context, req *cbdc.GetBalanceRequest) (*cbdc.GetBalanceResponse, error) {
+	account, err := resolveAccount(Contract, req.GetAccount())
+	if err != nil {
+		return nil, err
+	}
+	balance, free, purposeBalances, err := getClientBalance(Contract, account)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.GetBalanceResponse{
+		Balance:            balance,
+		DecimalBalance:     units.Format(balance),
+		Currency:           units.Currency,
+		FreeBalance:        free,
+		DecimalFreeBalance: units.Format(free),
+		PurposeBalances:    purposeBalances,
+	}, nil
+}
+
+func (s *server) CreateAccount(ctx context.Context, req *cbdc.CreateAccountRequest) (*cbdc.CreateAccountResponse, error) {
+	// The deprecated account field used to name the account, it now only carries an alias for older clients
+	alias := req.Alias
+	if req.Account != "" {
+		if !isAlias(req.Account) {
+			return nil, gwstatus.InvalidArgument(fmt.Errorf("account ids are generated by the bank, %s cannot be chosen; use alias to register an alias", req.Account))
+		}
+		if alias != "" && alias != req.Account {
+			return nil, gwstatus.InvalidArgument(fmt.Errorf("account and alias name different aliases"))
+		}
+		alias = req.Account
+	}
+	if alias != "" {
+		if !isAlias(alias) {
+			return nil, gwstatus.InvalidArgument(fmt.Errorf("%s is not an alias", alias))
+		}
+		if err := checkAliasSuffix(alias); err != nil {
+			return nil, err
+		}
+	}
+	address, err := newAddress(req.PublicKey)
+	if err != nil {
+		return nil, gwstatus.InvalidArgument(err)
+	}
+	var acc string
+	if len(req.Signers) > 0 {
+		acc, err = registerMultisigAccount(Contract, address, req.Signers, req.SignerKeys, req.Threshold)
+	} else {
+		acc, err = registerAccount(Contract, address, req.KycTier, req.MerchantCategory, req.PublicKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The account exists even if its alias cannot be registered, the alias can be registered again later
+	msg := "Account Created Successfully"
+	if alias != "" {
+		if _, err := registerAlias(Contract, alias, acc, ""); err != nil {
+			msg = fmt.Sprintf("%s, failed to register alias %s: %s", msg, alias, status.Convert(err).Message())
+		}
+	}
+
+	return &cbdc.CreateAccountResponse{
+		Account: acc,
+		Success: true,
+		Message: msg,
+	}, nil
+}
+
+func (s *server) Tx(ctx context.Context, req *cbdc.TxRequest) (*cbdc.TxResponse, error) {
+	value, err := units.Parse(req.DecimalAmount, req.Currency, req.Amount)
+	if err != nil {
+		return nil, gwstatus.InvalidArgument(err)
+	}
+	fromAccount, err := resolveAccount(Contract, req.From)
+	if err != nil {
+		return nil, err
+	}
+	toAccount, err := resolveAccount(Contract, req.To)
+	if err != nil {
+		return nil, err
+	}
+	txId, from, to, amount, msg, err := transferFrom(Contract, fromAccount, toAccount, strconv.FormatUint(value, 10), req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.TxResponse{
+		TxId:          txId,
+		From:          from,
+		To:            to,
+		Amount:        amount,
+		Success:       true,
+		Message:       msg,
+		DecimalAmount: units.Format(amount),
+		Currency:      units.Currency,
+	}, nil
+}
+
+func (s *server) SubmitSignedTx(ctx context.Context, req *cbdc.SignedTxRequest) (*cbdc.TxResponse, error) {
+	txId, from, to, amount, err := submitSignedTx(Contract, req.Payload, req.Signature)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.TxResponse{
+		TxId:          txId,
+		From:          from,
+		To:            to,
+		Amount:        amount,
+		Success:       true,
+		Message:       "Transaction Committed Successfully",
+		DecimalAmount: units.Format(amount),
+		Currency:      units.Currency,
+	}, nil
+}
+
+func (s *server) Fund(ctx context.Context, req *cbdc.FundRequest) (*cbdc.FundResponse, error) {
+	value, err := units.Parse(req.DecimalAmount, req.Currency, req.Amount)
+	if err != nil {
+		return nil, gwstatus.InvalidArgument(err)
+	}
+	// Only the accounts the bank holds can be linked to its deposit accounts, the link is made once the fund completes
+	if req.BankAccountNumber != "" {
+		if err := checkHeldAccount(Contract, req.Account); err != nil {
+			return nil, err
+		}
+	}
+	saga, err := fund(Contract, req.Account, value, req.IdempotencyKey, req.BankAccountNumber, req.BankName)
+	if err != nil {
+		return nil, gwstatus.ToStatus(err)
+	}
+	// A saga still running is not an error, the client polls GetFundStatus for its completion
+	if saga.done() && saga.Status != FundCompleted {
+		return nil, fundError(saga)
+	}
+	return fundResponse(saga), nil
+}
+
+func (s *server) GetFundStatus(ctx context.Context, req *cbdc.GetFundStatusRequest) (*cbdc.FundResponse, error) {
+	saga, err := getFundStatus(req.FundId)
+	if err != nil {
+		return nil, err
+	}
+	return fundResponse(saga), nil
+}
+
+func (s *server) GetLiquidity(ctx context.Context, req *cbdc.GetLiquidityRequest) (*cbdc.LiquidityResponse, error) {
+	return Liquidity.response(), nil
+}
+
+func (s *server) SetLiquidity(ctx context.Context, req *cbdc.SetLiquidityRequest) (*cbdc.LiquidityResponse, error) {
+	err := Liquidity.setConfig(liquidityConfig{
+		LowWatermark:  req.LowWatermark,
+		Target:        req.Target,
+		HighWatermark: req.HighWatermark,
+	})
+	if err != nil {
+		return nil, gwstatus.InvalidArgument(err)
+	}
+	return Liquidity.response(), nil
+}
+
+func (s *server) GetHistory(ctx context.Context, req *cbdc.GetHistoryRequest) (*cbdc.GetHistoryResponse, error) {
+	records, bookmark, err := getHistory(Contract, req.Account, req.FromTime, req.ToTime, req.PageSize, req.Bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.GetHistoryResponse{
+		Records:  records,
+		Bookmark: bookmark,
+	}, nil
+}
+
+func (s *server) Redeem(ctx context.Context, req *cbdc.RedeemRequest) (*cbdc.RedeemResponse, error) {
+	value, err := units.Parse(req.DecimalAmount, req.Currency, req.Amount)
+	if err != nil {
+		return nil, gwstatus.InvalidArgument(err)
+	}
+	txId, amt, err := redeem(ctx, value)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.RedeemResponse{
+		TxId:          txId,
+		Account:       Config.BankAccount,
+		Amount:        amt,
+		Success:       true,
+		Message:       "Redeemed Successfully",
+		DecimalAmount: units.Format(amt),
+		Currency:      units.Currency,
+	}, nil
+}
+
+func (s *server) CreateHTLC(ctx context.Context, req *cbdc.CreateHTLCRequest) (*cbdc.HTLCResponse, error) {
+	value, err := units.Parse(req.DecimalAmount, req.Currency, req.Amount)
+	if err != nil {
+		return nil, gwstatus.InvalidArgument(err)
+	}
+	txId, htlcId, err := createHTLC(Contract, req.Sender, req.Recipient, value, req.Hashlock, req.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.HTLCResponse{
+		TxId:    txId,
+		HtlcId:  htlcId,
+		Success: true,
+		Message: "HTLC Created Successfully",
+	}, nil
+}
+
+func (s *server) ClaimHTLC(ctx context.Context, req *cbdc.ClaimHTLCRequest) (*cbdc.HTLCResponse, error) {
+	txId, err := claimHTLC(Contract, req.HtlcId, req.Preimage)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.HTLCResponse{
+		TxId:    txId,
+		HtlcId:  req.HtlcId,
+		Success: true,
+		Message: "HTLC Claimed Successfully",
+	}, nil
+}
+
+func (s *server) RefundHTLC(ctx context.Context, req *cbdc.RefundHTLCRequest) (*cbdc.HTLCResponse, error) {
+	txId, err := refundHTLC(Contract, req.HtlcId)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.HTLCResponse{
+		TxId:    txId,
+		HtlcId:  req.HtlcId,
+		Success: true,
+		Message: "HTLC Refunded Successfully",
+	}, nil
+}
+
+func (s *server) BulkTx(ctx context.Context, req *cbdc.BulkTxRequest) (*cbdc.BulkTxResponse, error) {
+	legs, results, ok := parseBulkLegs(req)
+	if !ok {
+		return nil, invalidLegs(results)
+	}
+	fromAccount, err := resolveAccount(Contract, req.From)
+	if err != nil {
+		return nil, err
+	}
+	txId, msg, err := transferBatch(Contract, fromAccount, legs, req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// The legs share the outcome of the batch
+	for _, result := range results {
+		result.Success = true
+		result.Message = msg
+	}
+	total := batchTotal(legs)
+	return &cbdc.BulkTxResponse{
+		TxId:         txId,
+		From:         fromAccount,
+		Total:        total,
+		DecimalTotal: units.Format(total),
+		Currency:     units.Currency,
+		Success:      true,
+		Message:      msg,
+		Results:      results,
+	}, nil
+}
+
+func (s *server) RaiseDispute(ctx context.Context, req *cbdc.RaiseDisputeRequest) (*cbdc.DisputeResponse, error) {
+	value, err := units.Parse(req.DecimalAmount, req.Currency, req.Amount)
+	if err != nil {
+		return nil, gwstatus.InvalidArgument(err)
+	}
+	txId, disputeId, err := raiseDispute(Contract, req.TxId, req.Payer, req.Payee, value, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	res := &cbdc.DisputeResponse{TxId: txId, Success: true, Message: "Dispute Raised Successfully"}
+	res.Dispute, _ = getDispute(Contract, disputeId)
+	return res, nil
+}
+
+func (s *server) GetDispute(ctx context.Context, req *cbdc.GetDisputeRequest) (*cbdc.DisputeResponse, error) {
+	dispute, err := getDispute(Contract, req.DisputeId)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.DisputeResponse{Success: true, Dispute: dispute}, nil
+}
+
+func (s *server) ProposeTransfer(ctx context.Context, req *cbdc.ProposeTransferRequest) (*cbdc.ProposalResponse, error) {
+	action, err := parseMultisigAction(req.Payload)
+	if err != nil {
+		return nil, err
+	}
+	txId, proposalId, msg, err := proposeTransfer(Contract, action, req.Payload, req.Signature)
+	if err != nil && proposalId == "" {
+		return nil, err
+	}
+	// A proposal that could not be executed yet stays pending, so it is reported with the error of its execution
+	res := &cbdc.ProposalResponse{TxId: txId, Success: err == nil, Message: msg}
+	if err != nil {
+		res.Message = status.Convert(err).Message()
+	}
+	res.Proposal, _ = getProposal(Contract, action.Account, proposalId)
+	return res, nil
+}
+
+func (s *server) ApproveProposal(ctx context.Context, req *cbdc.ApproveProposalRequest) (*cbdc.ProposalResponse, error) {
+	action, err := parseMultisigAction(req.Payload)
+	if err != nil {
+		return nil, err
+	}
+	txId, msg, err := approveProposal(Contract, action, req.Payload, req.Signature)
+	if err != nil {
+		return nil, err
+	}
+	res := &cbdc.ProposalResponse{TxId: txId, Success: true, Message: msg}
+	res.Proposal, _ = getProposal(Contract, action.Account, action.Proposal)
+	return res, nil
+}
+
+func (s *server) CancelProposal(ctx context.Context, req *cbdc.CancelProposalRequest) (*cbdc.ProposalResponse, error) {
+	action, err := parseMultisigAction(req.Payload)
+	if err != nil {
+		return nil, err
+	}
+	txId, err := cancelProposal(Contract, action, req.Payload, req.Signature)
+	if err != nil {
+		return nil, err
+	}
+	res := &cbdc.ProposalResponse{TxId: txId, Success: true, Message: "Proposal Cancelled Successfully"}
+	res.Proposal, _ = getProposal(Contract, action.Account, action.Proposal)
+	return res, nil
+}
+
+func (s *server) ListProposals(ctx context.Context, req *cbdc.ListProposalsRequest) (*cbdc.ListProposalsResponse, error) {
+	proposals, err := listProposals(Contract, req.Account)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := getProposalNonce(Contract, req.Account)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.ListProposalsResponse{Proposals: proposals, Success: true, ProposalNonce: nonce}, nil
+}
+
+func (s *server) RotateSigners(ctx context.Context, req *cbdc.RotateSignersRequest) (*cbdc.RotateSignersResponse, error) {
+	txId, err := rotateSigners(Contract, req.Payload, req.Signers, req.Signatures)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.RotateSignersResponse{TxId: txId, Success: true, Message: "Signers Rotated Successfully"}, nil
+}
+
+func (s *server) RegisterAlias(ctx context.Context, req *cbdc.RegisterAliasRequest) (*cbdc.AliasResponse, error) {
+	account, err := resolveAccount(Contract, req.Account)
+	if err != nil {
+		return nil, err
+	}
+	txId, err := registerAlias(Contract, req.Alias, account, req.DisplayName)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.AliasResponse{
+		Alias:       req.Alias,
+		Account:     account,
+		DisplayName: req.DisplayName,
+		TxId:        txId,
+		Success:     true,
+		Message:     "Alias Registered Successfully",
+	}, nil
+}
+
+func (s *server) ResolveAlias(ctx context.Context, req *cbdc.ResolveAliasRequest) (*cbdc.AliasResponse, error) {
+	alias, err := resolveAlias(Contract, req.Alias)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.AliasResponse{
+		Alias:       alias.Alias,
+		Account:     alias.Account,
+		DisplayName: alias.DisplayName,
+		Success:     true,
+	}, nil
+}
+
+func (s *server) DeleteAlias(ctx context.Context, req *cbdc.DeleteAliasRequest) (*cbdc.AliasResponse, error) {
+	txId, err := deleteAlias(Contract, req.Alias)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.AliasResponse{Alias: req.Alias, TxId: txId, Success: true, Message: "Alias Deleted Successfully"}, nil
+}
+
+func (s *server) GetDepositLink(ctx context.Context, req *cbdc.GetDepositLinkRequest) (*cbdc.DepositLinkResponse, error) {
+	return depositLinkResponse(req.Account)
+}
+
+func (s *server) UnlinkDeposit(ctx context.Context, req *cbdc.UnlinkDepositRequest) (*cbdc.DepositLinkResponse, error) {
+	if err := unlinkDeposit(req.Account); err != nil {
+		return nil, err
+	}
+	return &cbdc.DepositLinkResponse{Account: req.Account, Success: true, Message: "Deposit Account Unlinked"}, nil
+}
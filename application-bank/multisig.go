@@ -0,0 +1,226 @@
+package main
+
+import (
+	cbdc "app/api"
+	"common/gwstatus"
+	"common/units"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// proposalRecord mirrors the Proposal returned by the chaincode
+type proposalRecord struct {
+	ID           string   `json:"id"`
+	Account      string   `json:"account"`
+	To           string   `json:"to"`
+	Amount       uint64   `json:"amount"`
+	Proposer     string   `json:"proposer"`
+	Approvals    []string `json:"approvals"`
+	Status       string   `json:"status"`
+	ExecutedTxID string   `json:"executedTxId"`
+	Nonce        uint64   `json:"nonce"`
+}
+
+// multisigAction mirrors the MultisigAction signed by a signer of a multisig account
+type multisigAction struct {
+	Channel   string `json:"channel"`
+	Chaincode string `json:"chaincode"`
+	Action    string `json:"action"`
+	Account   string `json:"account"`
+	Signer    string `json:"signer"`
+	Proposal  string `json:"proposal"`
+	To        string `json:"to"`
+	Amount    uint64 `json:"amount"`
+	Nonce     uint64 `json:"nonce"`
+}
+
+// signerRotation mirrors the SignerRotation signed by the signers of a multisig account
+type signerRotation struct {
+	Channel   string `json:"channel"`
+	Chaincode string `json:"chaincode"`
+	Account   string `json:"account"`
+}
+
+// Parse a signed multisig action, failing if it is signed for another deployment
+func parseMultisigAction(payload string) (*multisigAction, error) {
+	var action multisigAction
+	if err := json.Unmarshal([]byte(payload), &action); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid signed payload: %v", err)
+	}
+	if err := checkSignedDomain(action.Channel, action.Chaincode); err != nil {
+		return nil, err
+	}
+	return &action, nil
+}
+
+func (p *proposalRecord) toProto() *cbdc.Proposal {
+	return &cbdc.Proposal{
+		ProposalId:    p.ID,
+		Account:       p.Account,
+		To:            p.To,
+		Amount:        p.Amount,
+		DecimalAmount: units.Format(p.Amount),
+		Proposer:      p.Proposer,
+		Approvals:     p.Approvals,
+		Status:        p.Status,
+		ExecutedTxId:  p.ExecutedTxID,
+		Nonce:         p.Nonce,
+	}
+}
+
+// Register a corporate account whose payments need the approval of threshold of its signers
+func registerMultisigAccount(contract *client.Contract, account string, signers, signerKeys []string, threshold uint32) (string, error) {
+	fmt.Printf("\n--> Submit Transaction: RegisterMultisigAccount, registers %s with %d of %d signers\n", account, threshold, len(signers))
+	signersJSON, err := json.Marshal(signers)
+	if err != nil {
+		return account, gwstatus.ToStatus(fmt.Errorf("failed to encode signers: %w", err))
+	}
+	signerKeysJSON, err := json.Marshal(signerKeys)
+	if err != nil {
+		return account, gwstatus.ToStatus(fmt.Errorf("failed to encode signer keys: %w", err))
+	}
+	_, _, err = gwstatus.SubmitTransaction(contract, "RegisterMultisigAccount", account, string(signersJSON), string(signerKeysJSON), strconv.FormatUint(uint64(threshold), 10))
+	return account, err
+}
+
+// Relay a transfer proposal signed by a signer of a multisig account, executing it if no other approval is needed
+func proposeTransfer(contract *client.Contract, action *multisigAction, payload, signature string) (string, string, string, error) {
+	fmt.Printf("\n--> Submit Transaction: ProposeTransfer, proposes %d %s->%s for %s\n", action.Amount, action.Account, action.To, action.Signer)
+	result, txId, err := gwstatus.SubmitTransaction(contract, "ProposeTransfer", payload, signature)
+	if err != nil {
+		return txId, "", "", err
+	}
+	proposalId := string(result)
+	executeTxId, executed, err := executeIfApproved(contract, action.Account, proposalId)
+	if err != nil {
+		return executeTxId, proposalId, "", err
+	}
+	if executed {
+		return executeTxId, proposalId, "Transaction Committed Successfully", nil
+	}
+	return txId, proposalId, "Proposal Awaiting Approvals", nil
+}
+
+// Relay the approval of a pending proposal signed by a signer, executing the proposal once it has enough approvals
+func approveProposal(contract *client.Contract, action *multisigAction, payload, signature string) (string, string, error) {
+	fmt.Printf("\n--> Submit Transaction: ApproveTransfer, approves proposal %s for %s\n", action.Proposal, action.Signer)
+	_, txId, err := gwstatus.SubmitTransaction(contract, "ApproveTransfer", payload, signature)
+	if err != nil {
+		return txId, "", err
+	}
+	executeTxId, executed, err := executeIfApproved(contract, action.Account, action.Proposal)
+	if err != nil {
+		return executeTxId, "", err
+	}
+	if executed {
+		return executeTxId, "Transaction Committed Successfully", nil
+	}
+	return txId, "Proposal Awaiting Approvals", nil
+}
+
+// Relay the cancellation of a pending proposal signed by a signer
+func cancelProposal(contract *client.Contract, action *multisigAction, payload, signature string) (string, error) {
+	fmt.Printf("\n--> Submit Transaction: CancelProposal, cancels proposal %s for %s\n", action.Proposal, action.Signer)
+	_, txId, err := gwstatus.SubmitTransaction(contract, "CancelProposal", payload, signature)
+	return txId, err
+}
+
+// Relay the replacement of the signers of a multisig account signed by its current signers
+func rotateSigners(contract *client.Contract, payload string, signers, signatures []string) (string, error) {
+	var rotation signerRotation
+	if err := json.Unmarshal([]byte(payload), &rotation); err != nil {
+		return "xxxxx", status.Errorf(codes.InvalidArgument, "invalid signed payload: %v", err)
+	}
+	if err := checkSignedDomain(rotation.Channel, rotation.Chaincode); err != nil {
+		return "xxxxx", err
+	}
+	fmt.Printf("\n--> Submit Transaction: RotateSigners, rotates the signers of %s\n", rotation.Account)
+	signersJSON, err := json.Marshal(signers)
+	if err != nil {
+		return "xxxxx", gwstatus.ToStatus(fmt.Errorf("failed to encode signers: %w", err))
+	}
+	signaturesJSON, err := json.Marshal(signatures)
+	if err != nil {
+		return "xxxxx", gwstatus.ToStatus(fmt.Errorf("failed to encode signatures: %w", err))
+	}
+	_, txId, err := gwstatus.SubmitTransaction(contract, "RotateSigners", payload, string(signersJSON), string(signaturesJSON))
+	return txId, err
+}
+
+// Execute a proposal that has the approvals of the threshold of signers of its account
+// Reports false without an error if the proposal still needs approvals
+func executeIfApproved(contract *client.Contract, account, proposalId string) (string, bool, error) {
+	evaluateResult, err := contract.EvaluateTransaction("GetAccount", account)
+	if err != nil {
+		return "xxxxx", false, gwstatus.ToStatus(err)
+	}
+	var acc struct {
+		Threshold int `json:"threshold"`
+	}
+	if err := json.Unmarshal(evaluateResult, &acc); err != nil {
+		return "xxxxx", false, gwstatus.ToStatus(fmt.Errorf("failed to parse account: %w", err))
+	}
+
+	proposal, err := getProposal(contract, account, proposalId)
+	if err != nil {
+		return "xxxxx", false, err
+	}
+	if len(proposal.Approvals) < acc.Threshold {
+		return "", false, nil
+	}
+
+	fmt.Printf("\n--> Submit Transaction: ExecuteTransfer, executes proposal %s\n", proposalId)
+	_, txId, err := gwstatus.SubmitTransaction(contract, "ExecuteTransfer", account, proposalId)
+	return txId, err == nil, err
+}
+
+// Get a proposal of a multisig account
+func getProposal(contract *client.Contract, account, proposalId string) (*cbdc.Proposal, error) {
+	evaluateResult, err := contract.EvaluateTransaction("GetProposal", account, proposalId)
+	if err != nil {
+		return nil, gwstatus.ToStatus(err)
+	}
+
+	var proposal proposalRecord
+	if err := json.Unmarshal(evaluateResult, &proposal); err != nil {
+		return nil, gwstatus.ToStatus(fmt.Errorf("failed to parse proposal: %w", err))
+	}
+	return proposal.toProto(), nil
+}
+
+// List the pending proposals of a multisig account
+func listProposals(contract *client.Contract, account string) ([]*cbdc.Proposal, error) {
+	fmt.Printf("\n--> Evaluate Transaction: GetPendingProposals, returns the pending proposals of %s\n", account)
+	evaluateResult, err := contract.EvaluateTransaction("GetPendingProposals", account)
+	if err != nil {
+		return nil, gwstatus.ToStatus(err)
+	}
+
+	var records []proposalRecord
+	if err := json.Unmarshal(evaluateResult, &records); err != nil {
+		return nil, gwstatus.ToStatus(fmt.Errorf("failed to parse proposals: %w", err))
+	}
+	proposals := make([]*cbdc.Proposal, 0, len(records))
+	for _, record := range records {
+		proposals = append(proposals, record.toProto())
+	}
+	return proposals, nil
+}
+
+// Get the nonce of the last proposal or signer rotation of a multisig account
+func getProposalNonce(contract *client.Contract, account string) (uint64, error) {
+	evaluateResult, err := contract.EvaluateTransaction("GetProposalNonce", account)
+	if err != nil {
+		return 0, gwstatus.ToStatus(err)
+	}
+	nonce, err := strconv.ParseUint(string(evaluateResult), 10, 64)
+	if err != nil {
+		return 0, gwstatus.ToStatus(fmt.Errorf("failed to parse nonce: %w", err))
+	}
+	return nonce, nil
+}
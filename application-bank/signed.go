@@ -0,0 +1,47 @@
+package main
+
+import (
+	"common/gwstatus"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// signedTransfer mirrors the SignedTransfer signed by the holder of a self-custodial account
+type signedTransfer struct {
+	Channel   string `json:"channel"`
+	Chaincode string `json:"chaincode"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Amount    uint64 `json:"amount"`
+	Nonce     uint64 `json:"nonce"`
+	Expiry    int64  `json:"expiry"`
+}
+
+// Relay a transfer signed by the holder of a self-custodial account
+// The payload is passed on unchanged, the signature covers its exact bytes
+func submitSignedTx(contract *client.Contract, payload, signature string) (string, string, string, uint64, error) {
+	var transfer signedTransfer
+	if err := json.Unmarshal([]byte(payload), &transfer); err != nil {
+		return "xxxxx", "", "", 0, status.Errorf(codes.InvalidArgument, "invalid signed payload: %v", err)
+	}
+	if err := checkSignedDomain(transfer.Channel, transfer.Chaincode); err != nil {
+		return "xxxxx", "", "", 0, err
+	}
+	fmt.Printf("\n--> Submit Transaction: TransferSigned, transfers %d %s->%s\n", transfer.Amount, transfer.From, transfer.To)
+
+	_, txId, err := gwstatus.SubmitTransaction(contract, "TransferSigned", payload, signature)
+	return txId, transfer.From, transfer.To, transfer.Amount, err
+}
+
+// Check that a signed payload names the channel and chaincode the bank submits to
+// The chaincode rejects payloads signed for another deployment, this fails before submitting
+func checkSignedDomain(channel, chaincode string) error {
+	if channel != Config.ChannelName || chaincode != Config.ChaincodeName {
+		return status.Errorf(codes.InvalidArgument, "payload is signed for %s/%s, this bank submits to %s/%s", channel, chaincode, Config.ChannelName, Config.ChaincodeName)
+	}
+	return nil
+}
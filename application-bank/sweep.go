@@ -0,0 +1,415 @@
+package main
+
+import (
+	cbdc "app/api"
+	"common/gwstatus"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Default sweep band of the wallets linked to a deposit account in minor units
+// Override with the SWEEP_CAP and SWEEP_FLOOR environment variables
+// Above the cap the excess is swept to the deposit account, below the floor the wallet is funded up to it from the deposit account
+const (
+	DefaultSweepCap     = 5000000
+	DefaultSweepFloor   = 100000
+	SweepRetryDelay     = 5 * time.Second
+	SweepOut            = "SWEEP_OUT"
+	SweepIn             = "SWEEP_IN"
+	SweepReversed       = "REVERSED"
+	SweepNeedsAttention = "NEEDS_ATTENTION"
+)
+
+// depositLink links a CBDC account to the deposit account of its holder at the bank
+type depositLink struct {
+	Account           string    `json:"account"`
+	BankAccountNumber string    `json:"bankAccountNumber"`
+	BankName          string    `json:"bankName,omitempty"`
+	LinkedAt          time.Time `json:"linkedAt"`
+	LastSweep         string    `json:"lastSweep,omitempty"`
+	LastSweepAmount   uint64    `json:"lastSweepAmount,omitempty"`
+	LastSweepTxID     string    `json:"lastSweepTxId,omitempty"`
+	LastSweepMessage  string    `json:"lastSweepMessage,omitempty"`
+	LastSweepAt       time.Time `json:"lastSweepAt,omitempty"`
+}
+
+// depositLinkStore keeps the deposit links in a JSON file so they survive a restart of the application
+type depositLinkStore struct {
+	mu    sync.Mutex
+	path  string
+	links map[string]*depositLink
+}
+
+var DepositLinks *depositLinkStore
+
+// Open the deposit link store, loading the links saved in the file if it exists
+func openDepositLinkStore(path string) (*depositLinkStore, error) {
+	store := &depositLinkStore{path: path, links: make(map[string]*depositLink)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deposit link store %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &store.links); err != nil {
+		return nil, fmt.Errorf("failed to parse deposit link store %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// Save a link, writing the whole store to its file
+func (s *depositLinkStore) put(link *depositLink) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *link
+	s.links[link.Account] = &saved
+	return s.save()
+}
+
+// Save the outcome of a sweep to the link it was made for
+// Reports false without saving if the link was removed or replaced while the sweep ran
+func (s *depositLinkStore) recordSweep(swept *depositLink) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, ok := s.links[swept.Account]
+	if !ok || link.BankAccountNumber != swept.BankAccountNumber || !link.LinkedAt.Equal(swept.LinkedAt) {
+		return false, nil
+	}
+	link.LastSweep = swept.LastSweep
+	link.LastSweepAmount = swept.LastSweepAmount
+	link.LastSweepTxID = swept.LastSweepTxID
+	link.LastSweepMessage = swept.LastSweepMessage
+	link.LastSweepAt = swept.LastSweepAt
+	return true, s.save()
+}
+
+// Remove the link of an account, reporting false if it had none
+func (s *depositLinkStore) remove(account string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.links[account]; !ok {
+		return false, nil
+	}
+	delete(s.links, account)
+	return true, s.save()
+}
+
+// Write the store to its file; s.mu must be held
+func (s *depositLinkStore) save() error {
+	data, err := json.MarshalIndent(s.links, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode deposit link store: %w", err)
+	}
+	// Replace the file in one step so a crash never leaves it half written
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write deposit link store %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace deposit link store %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// Get a copy of the link of an account
+func (s *depositLinkStore) get(account string) (*depositLink, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, ok := s.links[account]
+	if !ok {
+		return nil, false
+	}
+	found := *link
+	return &found, true
+}
+
+// Link a deposit account to a CBDC account, replacing its previous link
+func linkDeposit(account, bankAccountNumber, bankName string) error {
+	link, ok := DepositLinks.get(account)
+	if ok && link.BankAccountNumber == bankAccountNumber {
+		return nil
+	}
+	link = &depositLink{
+		Account:           account,
+		BankAccountNumber: bankAccountNumber,
+		BankName:          bankName,
+		LinkedAt:          time.Now().UTC(),
+	}
+	if err := DepositLinks.put(link); err != nil {
+		return err
+	}
+	log.Printf("deposit account %s linked to %s", bankAccountNumber, account)
+	return nil
+}
+
+// Remove the deposit link of a CBDC account
+func unlinkDeposit(account string) error {
+	removed, err := DepositLinks.remove(account)
+	if err != nil {
+		return gwstatus.ToStatus(err)
+	}
+	if !removed {
+		return status.Errorf(codes.NotFound, "%s has no linked deposit account", account)
+	}
+	log.Printf("deposit account unlinked from %s", account)
+	return nil
+}
+
+// transferEvent mirrors the Transfer event of the chaincode and the legs of its TransferBatch event
+type transferEvent struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Value uint64 `json:"value"`
+}
+
+// Get the transfers reported by a chaincode event, none for events that do not move funds
+// A batch reports all its legs in a single TransferBatch event, since Fabric keeps a single event per transaction
+func transfersOf(event *client.ChaincodeEvent) ([]transferEvent, error) {
+	switch event.EventName {
+	case "Transfer":
+		var transfer transferEvent
+		if err := json.Unmarshal(event.Payload, &transfer); err != nil {
+			return nil, err
+		}
+		return []transferEvent{transfer}, nil
+	case "TransferBatch":
+		var batch struct {
+			Transfers []transferEvent `json:"transfers"`
+		}
+		if err := json.Unmarshal(event.Payload, &batch); err != nil {
+			return nil, err
+		}
+		return batch.Transfers, nil
+	default:
+		return nil, nil
+	}
+}
+
+// sweepEngine keeps the linked wallets between the floor and the cap of the sweep band
+// It watches the Transfer and TransferBatch events and sweeps the linked accounts they touch, one event at a time
+type sweepEngine struct {
+	contract *client.Contract
+	cap      uint64
+	floor    uint64
+}
+
+var Sweeper *sweepEngine
+
+// Create the sweep engine with the sweep band of the environment
+func newSweepEngine(contract *client.Contract) (*sweepEngine, error) {
+	sweepCap, err := envAmount("SWEEP_CAP", DefaultSweepCap)
+	if err != nil {
+		return nil, err
+	}
+	floor, err := envAmount("SWEEP_FLOOR", DefaultSweepFloor)
+	if err != nil {
+		return nil, err
+	}
+	if floor >= sweepCap {
+		return nil, fmt.Errorf("sweep floor %d must be below the sweep cap %d", floor, sweepCap)
+	}
+	return &sweepEngine{contract: contract, cap: sweepCap, floor: floor}, nil
+}
+
+// Watch the chaincode events, resuming after the last event handled before a restart
+// The stream is reopened when it breaks
+func (e *sweepEngine) run(network *client.Network, chaincodeName string, checkpointPath string) {
+	checkpointer, err := client.NewFileCheckpointer(checkpointPath)
+	if err != nil {
+		log.Printf("sweep engine stopped, failed to open checkpoint %s: %v", checkpointPath, err)
+		return
+	}
+	defer checkpointer.Close()
+
+	for {
+		ctx, cancel := context.WithCancel(context.Background())
+		events, err := network.ChaincodeEvents(ctx, chaincodeName, client.WithCheckpoint(checkpointer))
+		if err != nil {
+			log.Printf("failed to read chaincode events: %v", err)
+		} else {
+			for event := range events {
+				e.handle(event)
+				if err := checkpointer.CheckpointChaincodeEvent(event); err != nil {
+					log.Printf("failed to save sweep checkpoint: %v", err)
+				}
+			}
+			log.Printf("chaincode event stream closed, reconnecting")
+		}
+		cancel()
+		time.Sleep(SweepRetryDelay)
+	}
+}
+
+// Sweep the linked accounts touched by a transfer event
+func (e *sweepEngine) handle(event *client.ChaincodeEvent) {
+	transfers, err := transfersOf(event)
+	if err != nil {
+		log.Printf("failed to parse %s event of %s: %v", event.EventName, event.TransactionID, err)
+		return
+	}
+
+	swept := make(map[string]bool)
+	for _, transfer := range transfers {
+		for _, account := range []string{transfer.From, transfer.To} {
+			if swept[account] {
+				continue
+			}
+			swept[account] = true
+			if link, ok := DepositLinks.get(account); ok {
+				e.sweep(link, event.TransactionID)
+			}
+		}
+	}
+}
+
+// Sweep the excess of a linked wallet above the cap to its deposit account, or fund it up to the floor
+// The references derive from the transaction that triggered the sweep, so a sweep replayed after a restart is applied once
+func (e *sweepEngine) sweep(link *depositLink, txId string) {
+	_, free, _, err := getClientBalance(e.contract, link.Account)
+	if err != nil {
+		log.Printf("sweep of %s skipped: %v", link.Account, err)
+		return
+	}
+
+	reference := fmt.Sprintf("sweep-%s-%s", txId, link.Account)
+	switch {
+	case free > e.cap:
+		e.sweepOut(link, free-e.cap, reference)
+	case free < e.floor:
+		e.sweepIn(link, e.floor-free, reference)
+	default:
+		return
+	}
+
+	link.LastSweepAt = time.Now().UTC()
+	if recorded, err := DepositLinks.recordSweep(link); err != nil {
+		log.Printf("failed to save sweep of %s: %v", link.Account, err)
+	} else if !recorded {
+		log.Printf("deposit link of %s changed during the sweep, its outcome is not recorded on the link", link.Account)
+	}
+	log.Printf("sweep %s of %d for %s: %s", link.LastSweep, link.LastSweepAmount, link.Account, link.LastSweepMessage)
+}
+
+// Move an amount from the wallet to the bank reserve account and credit it to the deposit account
+// The transfer is reversed if the deposit account cannot be credited
+func (e *sweepEngine) sweepOut(link *depositLink, amount uint64, reference string) {
+	link.LastSweep, link.LastSweepAmount, link.LastSweepTxID = SweepOut, amount, ""
+
+	txId, _, _, _, msg, err := transferFrom(e.contract, link.Account, Config.BankAccount, strconv.FormatUint(amount, 10), reference+"-out")
+	if err != nil {
+		link.LastSweepMessage = status.Convert(err).Message()
+		return
+	}
+	link.LastSweepTxID, link.LastSweepMessage = txId, msg
+	defer Liquidity.notify()
+
+	ctx, cancel := context.WithTimeout(context.Background(), FundStepTimeout)
+	defer cancel()
+	err = CoreBanking.Credit(ctx, link.BankAccountNumber, amount, reference+"-out")
+	if err == nil {
+		link.LastSweepMessage = fmt.Sprintf("Swept to deposit account %s", link.BankAccountNumber)
+		return
+	}
+
+	txId, _, _, _, _, reversalErr := transferFrom(e.contract, Config.BankAccount, link.Account, strconv.FormatUint(amount, 10), reference+"-out-reversal")
+	if reversalErr != nil {
+		link.LastSweep = SweepNeedsAttention
+		link.LastSweepMessage = fmt.Sprintf("Deposit credit failed: %v; reversal failed: %s", err, status.Convert(reversalErr).Message())
+		return
+	}
+	link.LastSweep = SweepReversed
+	link.LastSweepTxID = txId
+	link.LastSweepMessage = fmt.Sprintf("Deposit credit failed: %v; sweep reversed", err)
+}
+
+// Debit an amount from the deposit account and fund the wallet with it
+// The deposit account is refunded if the fund fails
+func (e *sweepEngine) sweepIn(link *depositLink, amount uint64, reference string) {
+	link.LastSweep, link.LastSweepAmount, link.LastSweepTxID = SweepIn, amount, ""
+
+	ctx, cancel := context.WithTimeout(context.Background(), FundStepTimeout)
+	defer cancel()
+	if err := CoreBanking.Debit(ctx, link.BankAccountNumber, amount, reference+"-in"); err != nil {
+		link.LastSweepMessage = fmt.Sprintf("Deposit debit failed: %v", err)
+		return
+	}
+
+	// The engine handles one event at a time, so it waits for the fund saga to finish
+	saga, err := fund(e.contract, link.Account, amount, reference+"-in", "", "")
+	for err == nil && !saga.done() {
+		saga = waitFund(saga.ID)
+	}
+	switch {
+	case err != nil:
+		link.LastSweepMessage = status.Convert(err).Message()
+	case saga.Status == FundCompleted:
+		link.LastSweepTxID = saga.TransferTxID
+		link.LastSweepMessage = fmt.Sprintf("Funded from deposit account %s", link.BankAccountNumber)
+		return
+	case saga.Status == FundNeedsAttention:
+		link.LastSweep = SweepNeedsAttention
+		link.LastSweepMessage = fmt.Sprintf("Fund %s needs attention: %s", saga.ID, saga.Message)
+		return
+	default:
+		link.LastSweepMessage = saga.Message
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), FundStepTimeout)
+	defer cancel()
+	if refundErr := CoreBanking.Credit(ctx, link.BankAccountNumber, amount, reference+"-in-reversal"); refundErr != nil {
+		link.LastSweep = SweepNeedsAttention
+		link.LastSweepMessage = fmt.Sprintf("Fund failed: %s; deposit refund failed: %v", link.LastSweepMessage, refundErr)
+		return
+	}
+	link.LastSweep = SweepReversed
+	link.LastSweepMessage = fmt.Sprintf("Fund failed: %s; deposit refunded", link.LastSweepMessage)
+}
+
+// Build the response reporting the deposit link of an account and its last sweep
+func depositLinkResponse(account string) (*cbdc.DepositLinkResponse, error) {
+	link, ok := DepositLinks.get(account)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "%s has no linked deposit account", account)
+	}
+
+	res := &cbdc.DepositLinkResponse{
+		Account:           link.Account,
+		BankAccountNumber: link.BankAccountNumber,
+		BankName:          link.BankName,
+		Cap:               Sweeper.cap,
+		Floor:             Sweeper.floor,
+		LastSweep:         link.LastSweep,
+		LastSweepAmount:   link.LastSweepAmount,
+		LastSweepTxId:     link.LastSweepTxID,
+		LastSweepMessage:  link.LastSweepMessage,
+		Success:           true,
+	}
+	if !link.LastSweepAt.IsZero() {
+		res.LastSweepAt = link.LastSweepAt.Unix()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), FundStepTimeout)
+	defer cancel()
+	balance, err := CoreBanking.Balance(ctx, link.BankAccountNumber)
+	if err != nil {
+		res.Message = fmt.Sprintf("Failed to read deposit balance: %v", err)
+	} else {
+		res.DepositBalance = balance
+	}
+	return res, nil
+}
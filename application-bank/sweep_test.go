@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// failingCoreBanking fails the debits and credits of the references given, others are applied in memory
+type failingCoreBanking struct {
+	*memoryCoreBanking
+	failing map[string]error
+}
+
+func (b *failingCoreBanking) Debit(ctx context.Context, account string, amount uint64, reference string) error {
+	if err := b.failing[reference]; err != nil {
+		return err
+	}
+	return b.memoryCoreBanking.Debit(ctx, account, amount, reference)
+}
+
+func (b *failingCoreBanking) Credit(ctx context.Context, account string, amount uint64, reference string) error {
+	if err := b.failing[reference]; err != nil {
+		return err
+	}
+	return b.memoryCoreBanking.Credit(ctx, account, amount, reference)
+}
+
+func TestSweep(t *testing.T) {
+	account := withChecksum("hdfc1" + strings.Repeat("ab", addressIDLength))
+	reference := "sweep-tx1-" + account
+	down := errors.New("core banking unavailable")
+	frozen := errors.New("recipient account " + account + " is frozen: court order")
+
+	tests := []struct {
+		name          string
+		balance       uint64
+		failing       map[string]error
+		transferErrs  map[string]error
+		wantSweep     string
+		wantAmount    uint64
+		wantTxID      bool
+		wantMessage   string
+		wantTransfers []string
+		wantDeposit   uint64
+	}{
+		{
+			name:          "excess swept to the deposit account",
+			balance:       8000,
+			wantSweep:     SweepOut,
+			wantAmount:    3000,
+			wantTxID:      true,
+			wantMessage:   "Swept to deposit account 0001",
+			wantTransfers: []string{account + " hdfc.cbdc 3000 " + reference + "-out"},
+			wantDeposit:   13000,
+		},
+		{
+			name:         "sweep out rejected by the chaincode",
+			balance:      8000,
+			transferErrs: map[string]error{reference + "-out": errors.New("account " + account + " is frozen: court order")},
+			wantSweep:    SweepOut,
+			wantAmount:   3000,
+			wantMessage:  "is frozen: court order",
+			wantDeposit:  10000,
+		},
+		{
+			name:        "sweep out reversed when the deposit account cannot be credited",
+			balance:     8000,
+			failing:     map[string]error{reference + "-out": down},
+			wantSweep:   SweepReversed,
+			wantAmount:  3000,
+			wantTxID:    true,
+			wantMessage: "Deposit credit failed: core banking unavailable; sweep reversed",
+			wantTransfers: []string{
+				account + " hdfc.cbdc 3000 " + reference + "-out",
+				"hdfc.cbdc " + account + " 3000 " + reference + "-out-reversal",
+			},
+			wantDeposit: 10000,
+		},
+		{
+			name:         "sweep out reversal failing",
+			balance:      8000,
+			failing:      map[string]error{reference + "-out": down},
+			transferErrs: map[string]error{reference + "-out-reversal": frozen},
+			wantSweep:    SweepNeedsAttention,
+			wantAmount:   3000,
+			wantTxID:     true,
+			wantMessage:  "Deposit credit failed: core banking unavailable; reversal failed: " + frozen.Error(),
+			wantTransfers: []string{
+				account + " hdfc.cbdc 3000 " + reference + "-out",
+			},
+			wantDeposit: 10000,
+		},
+		{
+			name:          "wallet funded up to the floor",
+			balance:       200,
+			wantSweep:     SweepIn,
+			wantAmount:    800,
+			wantTxID:      true,
+			wantMessage:   "Funded from deposit account 0001",
+			wantTransfers: []string{"hdfc.cbdc " + account + " 800 " + reference + "-in"},
+			wantDeposit:   9200,
+		},
+		{
+			name:        "deposit account not debited",
+			balance:     200,
+			failing:     map[string]error{reference + "-in": down},
+			wantSweep:   SweepIn,
+			wantAmount:  800,
+			wantMessage: "Deposit debit failed: core banking unavailable",
+			wantDeposit: 10000,
+		},
+		{
+			name:         "deposit account refunded when the fund fails",
+			balance:      200,
+			transferErrs: map[string]error{reference + "-in": frozen},
+			wantSweep:    SweepReversed,
+			wantAmount:   800,
+			wantMessage:  "Fund failed: Transfer failed: " + frozen.Error() + "; deposit refunded",
+			wantDeposit:  10000,
+		},
+		{
+			name:         "deposit refund failing",
+			balance:      200,
+			failing:      map[string]error{reference + "-in-reversal": down},
+			transferErrs: map[string]error{reference + "-in": frozen},
+			wantSweep:    SweepNeedsAttention,
+			wantAmount:   800,
+			wantMessage:  "deposit refund failed: core banking unavailable",
+			wantDeposit:  9200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupBank(t)
+			deposits := &failingCoreBanking{memoryCoreBanking: newMemoryCoreBanking(10000), failing: tt.failing}
+			CoreBanking = deposits
+
+			var transfers []string
+			contract, _ := newTestContract(t, map[string]chaincodeFunc{
+				"GetBalanceBuckets": func(args ...string) (string, error) {
+					if args[0] == account {
+						return balanceResult(tt.balance), nil
+					}
+					return balanceResult(100000), nil
+				},
+				"GetPaymentReference": func(args ...string) (string, error) { return "", nil },
+				"TransferFrom": func(args ...string) (string, error) {
+					if err := tt.transferErrs[args[3]]; err != nil {
+						return "", err
+					}
+					transfers = append(transfers, strings.Join(args, " "))
+					return "", nil
+				},
+			})
+			Liquidity = &liquidityManager{contract: contract, wake: make(chan struct{}, 1)}
+			if err := DepositLinks.put(&depositLink{Account: account, BankAccountNumber: "0001"}); err != nil {
+				t.Fatal(err)
+			}
+			engine := &sweepEngine{contract: contract, cap: 5000, floor: 1000}
+
+			link, _ := DepositLinks.get(account)
+			engine.sweep(link, "tx1")
+
+			saved, _ := DepositLinks.get(account)
+			if saved.LastSweep != tt.wantSweep || saved.LastSweepAmount != tt.wantAmount {
+				t.Fatalf("last sweep = %s of %d (%s), want %s of %d", saved.LastSweep, saved.LastSweepAmount, saved.LastSweepMessage, tt.wantSweep, tt.wantAmount)
+			}
+			if !strings.Contains(saved.LastSweepMessage, tt.wantMessage) {
+				t.Errorf("message = %q, want it to contain %q", saved.LastSweepMessage, tt.wantMessage)
+			}
+			// Only a transaction that exists is recorded
+			if (saved.LastSweepTxID != "") != tt.wantTxID {
+				t.Errorf("transaction = %q, want one: %v", saved.LastSweepTxID, tt.wantTxID)
+			}
+			if strings.Join(transfers, "\n") != strings.Join(tt.wantTransfers, "\n") {
+				t.Errorf("transfers = %q, want %q", transfers, tt.wantTransfers)
+			}
+			if got, _ := deposits.Balance(context.Background(), "0001"); got != tt.wantDeposit {
+				t.Errorf("deposit balance = %d, want %d", got, tt.wantDeposit)
+			}
+		})
+	}
+}
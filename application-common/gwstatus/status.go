@@ -0,0 +1,226 @@
+// Package gwstatus translates the errors of the Fabric gateway and of the chaincode into gRPC status errors,
+// with codes the REST gateway of the nodes maps to HTTP statuses
+package gwstatus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Domain and reasons of the ErrorInfo detail attached to the status of a failed request
+const (
+	ErrorDomain          = "cbdc"
+	ReasonEndorseFailed  = "ENDORSE_FAILED"
+	ReasonSubmitFailed   = "SUBMIT_FAILED"
+	ReasonCommitUnknown  = "COMMIT_STATUS_UNKNOWN"
+	ReasonCommitFailed   = "COMMIT_FAILED"
+	ReasonEvaluateFailed = "EVALUATE_FAILED"
+)
+
+// ChaincodeResponseText starts the messages of the errors the peers report for a chaincode response
+const ChaincodeResponseText = "chaincode response "
+
+// Codes of the errors returned by the chaincode, which starts their messages with the code and ": "
+// Chaincode errors without a code break a business rule and are reported as FailedPrecondition
+var chaincodeErrorCodes = map[string]codes.Code{
+	"UNAUTHORIZED":     codes.PermissionDenied,
+	"NOT_FOUND":        codes.NotFound,
+	"ALREADY_EXISTS":   codes.AlreadyExists,
+	"INVALID_ARGUMENT": codes.InvalidArgument,
+}
+
+// ToStatus translates an error into a gRPC status error with a code the REST gateway maps to an HTTP status
+// Errors of the Fabric gateway keep the gateway.ErrorDetail of each peer and gain an ErrorInfo with the reason and the transaction id
+// Errors that already carry a status are returned unchanged, other errors are Internal
+func ToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var endorseErr *client.EndorseError
+	var submitErr *client.SubmitError
+	var commitStatusErr *client.CommitStatusError
+	var commitErr *client.CommitError
+	switch {
+	case errors.As(err, &endorseErr):
+		st := status.Convert(endorseErr)
+		return WithErrorInfo(st, chaincodeCode(st), errorMessage(err), ReasonEndorseFailed, endorseErr.TransactionID)
+	case errors.As(err, &submitErr):
+		// The orderer did not take the transaction, it can be submitted again
+		code := status.Code(submitErr)
+		if code != codes.Unavailable && code != codes.DeadlineExceeded {
+			code = codes.Aborted
+		}
+		return WithErrorInfo(status.Convert(submitErr), code, errorMessage(err), ReasonSubmitFailed, submitErr.TransactionID)
+	case errors.As(err, &commitStatusErr):
+		// The transaction may still commit, so the error must not read as safe to retry
+		code := codes.Unknown
+		if errors.Is(err, context.DeadlineExceeded) || status.Code(commitStatusErr) == codes.DeadlineExceeded {
+			code = codes.DeadlineExceeded
+		}
+		return WithErrorInfo(status.Convert(commitStatusErr), code, errorMessage(err), ReasonCommitUnknown, commitStatusErr.TransactionID)
+	case errors.As(err, &commitErr):
+		return CommitFailure(commitErr.TransactionID, commitErr.Code)
+	}
+
+	if st, ok := status.FromError(err); ok {
+		if !isChaincodeError(st) {
+			return err
+		}
+		return WithErrorInfo(st, chaincodeCode(st), errorMessage(err), ReasonEvaluateFailed, "")
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// CommitFailure builds the status error of a transaction that was ordered but failed validation
+// Read conflicts are Aborted so the client can submit the transaction again
+func CommitFailure(txId string, validationCode peer.TxValidationCode) error {
+	code := codes.FailedPrecondition
+	if validationCode == peer.TxValidationCode_MVCC_READ_CONFLICT || validationCode == peer.TxValidationCode_PHANTOM_READ_CONFLICT {
+		code = codes.Aborted
+	}
+	st := status.New(code, fmt.Sprintf("transaction %s failed to commit with status: %d", txId, int32(validationCode)))
+	return WithErrorInfo(st, code, st.Message(), ReasonCommitFailed, txId, "validationCode", validationCode.String())
+}
+
+// WithErrorInfo replaces the code and message of a status and attaches an ErrorInfo detail, keeping its other details
+// The metadata are pairs of keys and values
+func WithErrorInfo(st *status.Status, code codes.Code, message, reason, txId string, metadata ...string) error {
+	info := &errdetails.ErrorInfo{Reason: reason, Domain: ErrorDomain, Metadata: make(map[string]string)}
+	if txId != "" {
+		info.Metadata["txId"] = txId
+	}
+	for i := 0; i+1 < len(metadata); i += 2 {
+		info.Metadata[metadata[i]] = metadata[i+1]
+	}
+
+	proto := &spb.Status{Code: int32(code), Message: message, Details: st.Proto().GetDetails()}
+	if detail, err := anypb.New(info); err == nil {
+		proto.Details = append(proto.Details, detail)
+	}
+	return status.FromProto(proto).Err()
+}
+
+// TxID gets the id of the transaction reported by the ErrorInfo of a status error, or "xxxxx" if it has none
+func TxID(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Metadata["txId"] != "" {
+			return info.Metadata["txId"]
+		}
+	}
+	return "xxxxx"
+}
+
+// InvalidArgument builds the InvalidArgument status error of a request that failed validation
+func InvalidArgument(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// SubmitTransaction submits a transaction and waits for its commit, returning its result and its id
+// The error is a status error, the id is "xxxxx" if the transaction was not endorsed
+func SubmitTransaction(contract *client.Contract, name string, args ...string) ([]byte, string, error) {
+	result, commit, err := contract.SubmitAsync(name, client.WithArguments(args...))
+	if err != nil {
+		fmt.Printf("failed to submit transaction: %v\n", err)
+		return nil, "xxxxx", ToStatus(err)
+	}
+	fmt.Println("*** Waiting for transaction commit.")
+
+	commitStatus, err := commit.Status()
+	if err != nil {
+		fmt.Printf("failed to get commit status: %v\n", err)
+		return nil, commit.TransactionID(), ToStatus(err)
+	} else if !commitStatus.Successful {
+		fmt.Printf("transaction %s failed to commit with status: %d\n", commitStatus.TransactionID, int32(commitStatus.Code))
+		return nil, commitStatus.TransactionID, CommitFailure(commitStatus.TransactionID, commitStatus.Code)
+	}
+	fmt.Printf("*** Transaction committed successfully\n")
+	return result, commitStatus.TransactionID, nil
+}
+
+// Report whether a status carries an error returned by the chaincode rather than by the network
+func isChaincodeError(st *status.Status) bool {
+	return len(chaincodeMessages(st)) > 0
+}
+
+// Get the code of the chaincode error carried by a status, keeping the code of a status without one
+func chaincodeCode(st *status.Status) codes.Code {
+	messages := chaincodeMessages(st)
+	if len(messages) == 0 {
+		return st.Code()
+	}
+	code, _ := splitErrorCode(messages[0])
+	return code
+}
+
+// Split the code off the message of a chaincode error, FailedPrecondition for a message without one
+// The chaincode may add context before the code, as in "failed to transfer: NOT_FOUND: ...", which is kept
+func splitErrorCode(message string) (codes.Code, string) {
+	var prefix []string
+	for rest := message; ; {
+		segment, after, ok := strings.Cut(rest, ": ")
+		if !ok {
+			return codes.FailedPrecondition, message
+		}
+		if code, known := chaincodeErrorCodes[segment]; known {
+			return code, strings.Join(append(prefix, after), ": ")
+		}
+		prefix = append(prefix, segment)
+		rest = after
+	}
+}
+
+// Get the messages of the chaincode errors carried by the details of a status, or by its message
+func chaincodeMessages(st *status.Status) []string {
+	var messages []string
+	for _, detail := range st.Details() {
+		if detail, ok := detail.(*gateway.ErrorDetail); ok && strings.HasPrefix(detail.Message, ChaincodeResponseText) {
+			messages = append(messages, detail.Message)
+		}
+	}
+	if len(messages) == 0 && strings.Contains(st.Message(), ChaincodeResponseText) {
+		messages = append(messages, st.Message()[strings.Index(st.Message(), ChaincodeResponseText):])
+	}
+	for i, message := range messages {
+		// Drop the "chaincode response 500, " prefix
+		if _, text, ok := strings.Cut(message, ", "); ok {
+			messages[i] = text
+		}
+	}
+	return messages
+}
+
+// Get the message of an error, the chaincode errors of the peers without their codes if there are any
+func errorMessage(err error) string {
+	st := status.Convert(err)
+	var messages []string
+	for _, message := range chaincodeMessages(st) {
+		_, message = splitErrorCode(message)
+		if !slices.Contains(messages, message) {
+			messages = append(messages, message)
+		}
+	}
+	if len(messages) == 0 {
+		for _, detail := range st.Details() {
+			if detail, ok := detail.(*gateway.ErrorDetail); ok && !slices.Contains(messages, detail.Message) {
+				messages = append(messages, detail.Message)
+			}
+		}
+	}
+	if len(messages) == 0 {
+		return err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
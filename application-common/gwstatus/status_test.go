@@ -0,0 +1,174 @@
+package gwstatus_test
+
+import (
+	"common/gwstatus"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// evaluateError builds the status of an evaluation the peers rejected with the given chaincode errors
+func evaluateError(messages ...string) error {
+	st := status.New(codes.Unknown, "evaluate call to endorser returned error")
+	for _, message := range messages {
+		st, _ = st.WithDetails(&gateway.ErrorDetail{
+			Address: "peer0.rbi.example.com:7051",
+			MspId:   "RBIMSP",
+			Message: gwstatus.ChaincodeResponseText + "500, " + message,
+		})
+	}
+	return st.Err()
+}
+
+// errorInfo gets the ErrorInfo detail of a status error
+func errorInfo(err error) *errdetails.ErrorInfo {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	return nil
+}
+
+func TestToStatus(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantMessage string
+		wantReason  string
+	}{
+		{
+			name:        "unauthorized",
+			err:         evaluateError("UNAUTHORIZED: client with id AxisBankMSP is not authorized to close account hdfc1alice"),
+			wantCode:    codes.PermissionDenied,
+			wantMessage: "client with id AxisBankMSP is not authorized to close account hdfc1alice",
+			wantReason:  gwstatus.ReasonEvaluateFailed,
+		},
+		{
+			name:        "not found",
+			err:         evaluateError("NOT_FOUND: the alias priya@hdfc is not registered"),
+			wantCode:    codes.NotFound,
+			wantMessage: "the alias priya@hdfc is not registered",
+			wantReason:  gwstatus.ReasonEvaluateFailed,
+		},
+		{
+			name:        "already exists",
+			err:         evaluateError("ALREADY_EXISTS: the alias priya@hdfc is already registered"),
+			wantCode:    codes.AlreadyExists,
+			wantMessage: "the alias priya@hdfc is already registered",
+			wantReason:  gwstatus.ReasonEvaluateFailed,
+		},
+		{
+			name:        "invalid argument",
+			err:         evaluateError("INVALID_ARGUMENT: transfer amount cannot be negative"),
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "transfer amount cannot be negative",
+			wantReason:  gwstatus.ReasonEvaluateFailed,
+		},
+		{
+			name:        "code after the context added by the chaincode",
+			err:         evaluateError("failed to transfer: INVALID_ARGUMENT: the checksum of address hdfc1ab does not match, check it for typos"),
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "failed to transfer: the checksum of address hdfc1ab does not match, check it for typos",
+			wantReason:  gwstatus.ReasonEvaluateFailed,
+		},
+		{
+			name:        "business rule without a code",
+			err:         evaluateError("failed to transfer: recipient account hdfc1alice is frozen: court order"),
+			wantCode:    codes.FailedPrecondition,
+			wantMessage: "failed to transfer: recipient account hdfc1alice is frozen: court order",
+			wantReason:  gwstatus.ReasonEvaluateFailed,
+		},
+		{
+			name:        "wording alone does not pick the code",
+			err:         evaluateError("the proposal tx1 does not exist, client is not authorized"),
+			wantCode:    codes.FailedPrecondition,
+			wantMessage: "the proposal tx1 does not exist, client is not authorized",
+			wantReason:  gwstatus.ReasonEvaluateFailed,
+		},
+		{
+			name:        "same error of several peers",
+			err:         evaluateError("NOT_FOUND: the dispute d1 does not exist", "NOT_FOUND: the dispute d1 does not exist"),
+			wantCode:    codes.NotFound,
+			wantMessage: "the dispute d1 does not exist",
+			wantReason:  gwstatus.ReasonEvaluateFailed,
+		},
+		{
+			name:        "chaincode error in the message of the status",
+			err:         status.Error(codes.Unknown, "evaluate call to endorser returned error: chaincode response 500, NOT_FOUND: the htlc h1 does not exist"),
+			wantCode:    codes.NotFound,
+			wantMessage: "the htlc h1 does not exist",
+			wantReason:  gwstatus.ReasonEvaluateFailed,
+		},
+		{
+			name:        "network failure",
+			err:         unavailable,
+			wantCode:    codes.Unavailable,
+			wantMessage: "connection refused",
+		},
+		{
+			name:        "read conflict",
+			err:         &client.CommitError{TransactionID: "tx1", Code: peer.TxValidationCode_MVCC_READ_CONFLICT},
+			wantCode:    codes.Aborted,
+			wantMessage: "transaction tx1 failed to commit with status: 11",
+			wantReason:  gwstatus.ReasonCommitFailed,
+		},
+		{
+			name:        "endorsement policy failure",
+			err:         &client.CommitError{TransactionID: "tx1", Code: peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE},
+			wantCode:    codes.FailedPrecondition,
+			wantMessage: "transaction tx1 failed to commit with status: 10",
+			wantReason:  gwstatus.ReasonCommitFailed,
+		},
+		{
+			name:        "error without a status",
+			err:         errors.New("failed to parse balance"),
+			wantCode:    codes.Internal,
+			wantMessage: "failed to parse balance",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := gwstatus.ToStatus(tt.err)
+			st := status.Convert(err)
+			if st.Code() != tt.wantCode || st.Message() != tt.wantMessage {
+				t.Fatalf("ToStatus() = %s %q, want %s %q", st.Code(), st.Message(), tt.wantCode, tt.wantMessage)
+			}
+
+			info := errorInfo(err)
+			if tt.wantReason == "" {
+				if info != nil {
+					t.Fatalf("ToStatus() has ErrorInfo %v, want none", info)
+				}
+				return
+			}
+			if info == nil || info.Reason != tt.wantReason || info.Domain != gwstatus.ErrorDomain {
+				t.Fatalf("ToStatus() has ErrorInfo %v, want reason %s", info, tt.wantReason)
+			}
+		})
+	}
+
+	if err := gwstatus.ToStatus(nil); err != nil {
+		t.Fatalf("ToStatus(nil) = %v, want nil", err)
+	}
+}
+
+func TestTxID(t *testing.T) {
+	err := gwstatus.ToStatus(&client.CommitError{TransactionID: "tx1", Code: peer.TxValidationCode_MVCC_READ_CONFLICT})
+	if got := gwstatus.TxID(err); got != "tx1" {
+		t.Fatalf("TxID() = %s, want tx1", got)
+	}
+	if got := gwstatus.TxID(gwstatus.InvalidArgument(errors.New("amount must be positive"))); got != "xxxxx" {
+		t.Fatalf("TxID() of an error without a transaction = %s, want xxxxx", got)
+	}
+}
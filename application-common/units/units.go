@@ -0,0 +1,67 @@
+// Package units converts between the integer minor units of the token on the ledger and decimal amounts
+package units
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// Currency is the ISO 4217 code of the currency the CBDC is denominated in
+const Currency = "INR"
+
+// Decimals is the number of decimals of the token, amounts on the ledger are integer minor units
+var Decimals = 2
+
+// LoadDecimals loads the number of decimals of the token from the ledger
+func LoadDecimals(contract *client.Contract) error {
+	fmt.Println("\n--> Evaluate Transaction: Decimals, returns the number of decimals of the token")
+	evaluateResult, err := contract.EvaluateTransaction("Decimals")
+	if err != nil {
+		return fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+	decimals, err := strconv.Atoi(string(evaluateResult))
+	if err != nil {
+		return fmt.Errorf("failed to parse decimals %q: %w", evaluateResult, err)
+	}
+	Decimals = decimals
+
+	fmt.Printf("*** Decimals:%d\n", Decimals)
+	return nil
+}
+
+// Parse returns the amount of a request in minor units
+// A decimal amount such as "10.50" takes precedence over the minor unit amount
+func Parse(decimalAmount, currency string, minorUnits uint64) (uint64, error) {
+	if decimalAmount == "" {
+		return minorUnits, nil
+	}
+	if currency != "" && currency != Currency {
+		return 0, fmt.Errorf("unsupported currency %s, expected %s", currency, Currency)
+	}
+
+	whole, fraction, _ := strings.Cut(decimalAmount, ".")
+	if whole == "" || len(fraction) > Decimals || strings.ContainsAny(whole+fraction, "+-") {
+		return 0, fmt.Errorf("invalid amount %s, expected up to %d decimals", decimalAmount, Decimals)
+	}
+
+	amount, err := strconv.ParseUint(whole+fraction+strings.Repeat("0", Decimals-len(fraction)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %s: %w", decimalAmount, err)
+	}
+	return amount, nil
+}
+
+// Format formats an amount in minor units as a decimal string, e.g. 1050 as "10.50"
+func Format(minorUnits uint64) string {
+	digits := strconv.FormatUint(minorUnits, 10)
+	if Decimals == 0 {
+		return digits
+	}
+	if len(digits) <= Decimals {
+		digits = strings.Repeat("0", Decimals-len(digits)+1) + digits
+	}
+	return digits[:len(digits)-Decimals] + "." + digits[len(digits)-Decimals:]
+}
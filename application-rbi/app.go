@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"common/gwstatus"
 	"context"
 	"crypto/x509"
 	"encoding/json"
@@ -11,23 +12,18 @@ import (
 	"github.com/hyperledger/fabric-gateway/pkg/identity"
 	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/status"
 	"os"
 	"path"
-	"slices"
 	"strconv"
 )
 
 const (
-	HDFCBankAccount = "hdfc.cbdc"
-	AxisBankAccount = "axis.cbdc"
+	EscrowAccount = "escrow.cbdc"
 )
 
-func getCommercialBankAccounts() []string {
-	return []string{"hdfc.cbdc", "axis.cbdc"}
-}
-
 // newGrpcConnection creates a gRPC connection to the Gateway server.
 func newGrpcConnection() *grpc.ClientConn {
 	certificatePEM, err := os.ReadFile(tlsCertPath)
@@ -106,14 +102,25 @@ func readFirstFile(dirPath string) ([]byte, error) {
 	return os.ReadFile(path.Join(dirPath, fileNames[0]))
 }
 
-func initLedgerIfNotAlready(contract *client.Contract) {
+func initLedgerIfNotAlready(contract *client.Contract, banks []bankEntry) {
 	fmt.Println("\n--> Evaluate Transaction: Name, returns an abbreviated name for fungible tokens in the contract")
 	evaluateResult, err := contract.EvaluateTransaction("Symbol")
 	if err != nil {
 		initLedger(contract)
-		mint(contract)
-		transferHDFC(contract)
-		transferAxis(contract)
+		registerBanks(contract, banks)
+		registerEscrowAccount(contract)
+		var reserves uint64
+		for _, bank := range banks {
+			reserves += bank.InitialReserve
+		}
+		if reserves > 0 {
+			mint(contract, reserves)
+			fundReserves(contract, banks)
+		}
+	} else {
+		migrateBalances(contract)
+		registerBanks(contract, banks)
+		registerEscrowAccount(contract)
 	}
 	result := string(evaluateResult)
 
@@ -144,10 +151,11 @@ func initLedger(contract *client.Contract) {
 	fmt.Printf("*** Transaction committed successfully\n")
 }
 
-func mint(contract *client.Contract) {
+func mint(contract *client.Contract, amount uint64) {
 	fmt.Printf("\n--> Submit Transaction: Mint, creates new tokens and adds them to minter's account balance \n")
 
-	_, err := contract.SubmitTransaction("Mint", "20000")
+	// Amounts are in paise, this mints the initial reserves of the banks
+	_, err := contract.SubmitTransaction("Mint", strconv.FormatUint(amount, 10))
 	if err != nil {
 		panic(fmt.Errorf("failed to submit transaction: %w", err))
 	}
@@ -155,126 +163,101 @@ func mint(contract *client.Contract) {
 	fmt.Printf("*** Transaction committed successfully\n")
 }
 
-func mintRequest(contract *client.Contract, account string, amount uint64) (string, string, uint64, bool, string) {
-	if slices.Contains(getCommercialBankAccounts(), account) {
-		value := strconv.FormatUint(amount, 10)
-		_, commit, err := contract.SubmitAsync("Mint", client.WithArguments(value))
-		if err != nil {
-			return "xxxxx", account, amount, false, fmt.Sprintf("Failed to Submit due to error: %v", err)
-		}
-		fmt.Println("*** Waiting for transaction commit.")
-
-		commitStatus, err := commit.Status()
-		if err != nil {
-			panic(fmt.Errorf("failed to get commit status: %w", err))
-		} else if !commitStatus.Successful {
-			fmt.Printf("transaction %s failed to commit with status: %d", commitStatus.TransactionID, int32(commitStatus.Code))
-			return commitStatus.TransactionID, account, amount, false, fmt.Sprintf("transaction %s failed to commit with status: %d", commitStatus.TransactionID, int32(commitStatus.Code))
-		}
-		success := transferToAppropriateBank(contract, account, value)
-		if !success {
-			return commitStatus.TransactionID, account, amount, false, "Failed to transfer"
-		}
-		return commitStatus.TransactionID, account, amount, true, "Success!"
+// Rewrite balances stored as plain integers by earlier chaincode versions as balance records
+func migrateBalances(contract *client.Contract) {
+	// The migration scans the whole world state, so it only runs until the ledger records it as done
+	evaluateResult, err := contract.EvaluateTransaction("IsBalanceMigrationPending")
+	if err != nil {
+		panic(fmt.Errorf("failed to evaluate transaction: %w", err))
+	}
+	if string(evaluateResult) != "true" {
+		return
 	}
-	return "xxxxx", account, amount, false, "Not Authorized to Mint!"
-}
-
-func transferToAppropriateBank(contract *client.Contract, account, amount string) bool {
-	successFlag := false
-	switch account {
-	case HDFCBankAccount:
-		successFlag = transferHDFCAmount(contract, amount)
-		break
-	case AxisBankAccount:
-		successFlag = transferAxisAmount(contract, amount)
-		break
-	}
-	return successFlag
-}
 
-// Transfer to Axis
-func transferAxis(contract *client.Contract) {
-	fmt.Printf("\n--> Submit Transaction: Transfer, transfers tokens from client account to recipient account \n")
+	fmt.Printf("\n--> Submit Transaction: MigrateBalances, migrates legacy balances to balance records \n")
 
-	_, commit, err := contract.SubmitAsync("Transfer", client.WithArguments("axis.cbdc", "10000"))
+	result, err := contract.SubmitTransaction("MigrateBalances", "", "")
 	if err != nil {
 		panic(fmt.Errorf("failed to submit transaction: %w", err))
 	}
-	fmt.Println("*** Waiting for transaction commit.")
 
-	if commitStatus, err := commit.Status(); err != nil {
-		panic(fmt.Errorf("failed to get commit status: %w", err))
-	} else if !commitStatus.Successful {
-		panic(fmt.Errorf("transaction %s failed to commit with status: %d", commitStatus.TransactionID, int32(commitStatus.Code)))
-	}
-
-	fmt.Printf("*** Transaction committed successfully\n")
+	fmt.Printf("*** Migrated balances:%s\n", result)
 }
 
-// Transfer to Axis
-func transferAxisAmount(contract *client.Contract, amount string) bool {
-	fmt.Printf("\n--> Transferring %s to %s \n", amount, AxisBankAccount)
+// Register the escrow account unless it is already on the ledger
+func registerEscrowAccount(contract *client.Contract) {
+	// Hash time-locked payments are held in escrow
+	if _, err := contract.EvaluateTransaction("GetAccount", EscrowAccount); err != nil {
+		fmt.Printf("\n--> Submit Transaction: RegisterEscrowAccount, registers %s as escrow account \n", EscrowAccount)
 
-	_, commit, err := contract.SubmitAsync("Transfer", client.WithArguments(AxisBankAccount, amount))
-	if err != nil {
-		fmt.Printf("failed to submit transaction: %v\n", err)
-		return false
-	}
-	fmt.Println("*** Waiting for transaction commit.")
+		_, err := contract.SubmitTransaction("RegisterEscrowAccount")
+		if err != nil {
+			panic(fmt.Errorf("failed to submit transaction: %w", err))
+		}
 
-	if commitStatus, err := commit.Status(); err != nil {
-		fmt.Printf("failed to get commit status: %v\n", err)
-		return false
-	} else if !commitStatus.Successful {
-		fmt.Printf("transaction %s failed to commit with status: %d\n", commitStatus.TransactionID, int32(commitStatus.Code))
-		return false
+		fmt.Printf("*** Transaction committed successfully\n")
 	}
-
-	fmt.Printf("*** Transaction committed successfully\n")
-	return true
 }
 
-// Transfer to HDFC
-func transferHDFC(contract *client.Contract) {
-	fmt.Printf("\n--> Submit Transaction: Transfer, transfers tokens from client account to recipient account \n")
-
-	_, commit, err := contract.SubmitAsync("Transfer", client.WithArguments("hdfc.cbdc", "10000"))
-	if err != nil {
-		panic(fmt.Errorf("failed to submit transaction: %w", err))
+// Mint new tokens directly into the reserve account of a commercial bank in a single transaction
+func mintRequest(contract *client.Contract, account string, amount uint64) (string, string, uint64, error) {
+	if ok, err := isReserveAccount(contract, account); err != nil {
+		return "xxxxx", account, amount, gwstatus.ToStatus(err)
+	} else if !ok {
+		return "xxxxx", account, amount, status.Error(codes.PermissionDenied, "Not Authorized to Mint!")
 	}
-	fmt.Println("*** Waiting for transaction commit.")
+	_, txId, err := gwstatus.SubmitTransaction(contract, "MintTo", account, strconv.FormatUint(amount, 10))
+	return txId, account, amount, err
+}
 
-	if commitStatus, err := commit.Status(); err != nil {
-		panic(fmt.Errorf("failed to get commit status: %w", err))
-	} else if !commitStatus.Successful {
-		panic(fmt.Errorf("transaction %s failed to commit with status: %d", commitStatus.TransactionID, int32(commitStatus.Code)))
-	}
+// Mint new tokens bound to a purpose into a retail account, e.g. for a subsidy
+func mintPurposeBoundRequest(contract *client.Contract, account string, amount uint64, purpose string) (string, string, uint64, error) {
+	_, txId, err := gwstatus.SubmitTransaction(contract, "MintPurposeBound", account, strconv.FormatUint(amount, 10), purpose)
+	return txId, account, amount, err
+}
 
-	fmt.Printf("*** Transaction committed successfully\n")
+// Burn tokens returned from the reserve account of a commercial bank in a single transaction
+func redeemRequest(contract *client.Contract, account string, amount uint64) (string, string, uint64, error) {
+	if ok, err := isReserveAccount(contract, account); err != nil {
+		return "xxxxx", account, amount, gwstatus.ToStatus(err)
+	} else if !ok {
+		return "xxxxx", account, amount, status.Error(codes.PermissionDenied, "Not Authorized to Redeem!")
+	}
+	_, txId, err := gwstatus.SubmitTransaction(contract, "BurnFrom", account, strconv.FormatUint(amount, 10))
+	return txId, account, amount, err
 }
 
-// Transfer to Axis
-func transferHDFCAmount(contract *client.Contract, amount string) bool {
-	fmt.Printf("\n--> Transferring %s to %s \n", amount, HDFCBankAccount)
+// Revert the expired lots of all accounts to their issuers
+// Each lot is swept by its own transaction, which emits an Expired event for it
+// Lots of accounts under a freeze or lien order fail to sweep and are retried on the next run
+func sweepExpired(contract *client.Contract) {
+	fmt.Printf("\n--> Evaluate Transaction: GetExpiredLots, returns the lots past their expiry \n")
 
-	_, commit, err := contract.SubmitAsync("Transfer", client.WithArguments(HDFCBankAccount, amount))
+	evaluateResult, err := contract.EvaluateTransaction("GetExpiredLots")
 	if err != nil {
-		fmt.Printf("failed to submit transaction: %v\n", err)
-		return false
+		fmt.Printf("failed to evaluate transaction: %v\n", err)
+		return
+	}
+	var lots []struct {
+		ID      string `json:"id"`
+		Account string `json:"account"`
+	}
+	if err := json.Unmarshal(evaluateResult, &lots); err != nil {
+		fmt.Printf("failed to parse expired lots: %v\n", err)
+		return
 	}
-	fmt.Println("*** Waiting for transaction commit.")
 
-	if commitStatus, err := commit.Status(); err != nil {
-		fmt.Printf("failed to get commit status: %v\n", err)
-		return false
-	} else if !commitStatus.Successful {
-		fmt.Printf("transaction %s failed to commit with status: %d\n", commitStatus.TransactionID, int32(commitStatus.Code))
-		return false
+	swept := 0
+	for _, lot := range lots {
+		fmt.Printf("\n--> Submit Transaction: SweepExpiredLot, reverts lot %s of %s to its issuer \n", lot.ID, lot.Account)
+		if _, err := contract.SubmitTransaction("SweepExpiredLot", lot.Account, lot.ID); err != nil {
+			fmt.Printf("failed to sweep lot %s: %v\n", lot.ID, err)
+			continue
+		}
+		swept++
 	}
 
-	fmt.Printf("*** Transaction committed successfully\n")
-	return true
+	fmt.Printf("*** Swept lots:%d of %d\n", swept, len(lots))
 }
 
 // Get Name
@@ -438,7 +421,7 @@ func exampleErrorHandling(contract *client.Contract) {
 func formatJSON(data []byte) string {
 	var prettyJSON bytes.Buffer
 	if err := json.Indent(&prettyJSON, data, "", "  "); err != nil {
-		panic(fmt.Errorf("failed to parse JSON: %w", err))
+		return string(data)
 	}
 	return prettyJSON.String()
 }
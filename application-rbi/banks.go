@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultBanksConfig lists the commercial banks the central bank registers on the ledger, see config/banks.yaml
+const DefaultBanksConfig = "config/banks.yaml"
+
+// bankEntry is a commercial bank of the banks config
+// The ledger registry is the list of the banks every node reads, the config only feeds RegisterBank
+type bankEntry struct {
+	MSPID          string `yaml:"mspId" json:"mspId"`
+	AddressPrefix  string `yaml:"addressPrefix" json:"addressPrefix"`
+	ReserveAccount string `yaml:"reserveAccount" json:"reserveAccount"`
+
+	// Amount in paise transferred to the reserve account when the ledger is first initialized
+	InitialReserve uint64 `yaml:"initialReserve" json:"-"`
+}
+
+// Read the commercial banks to register from a YAML file
+func loadBanks(configPath string) ([]bankEntry, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read banks config %s: %w", configPath, err)
+	}
+	var config struct {
+		Banks []bankEntry `yaml:"banks"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse banks config %s: %w", configPath, err)
+	}
+	return config.Banks, nil
+}
+
+// Register the banks of the config that are not in the ledger registry yet, along with their reserve accounts
+func registerBanks(contract *client.Contract, banks []bankEntry) {
+	registered, err := getBanks(contract)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, bank := range banks {
+		if slices.ContainsFunc(registered, func(b bankEntry) bool { return b.MSPID == bank.MSPID }) {
+			continue
+		}
+
+		fmt.Printf("\n--> Submit Transaction: RegisterBank, registers %s with reserve account %s \n", bank.MSPID, bank.ReserveAccount)
+
+		_, err := contract.SubmitTransaction("RegisterBank", bank.MSPID, bank.AddressPrefix, bank.ReserveAccount)
+		if err != nil {
+			panic(fmt.Errorf("failed to submit transaction: %w", err))
+		}
+
+		fmt.Printf("*** Transaction committed successfully\n")
+	}
+}
+
+// Transfer the initial reserve of each bank of the config to its reserve account
+func fundReserves(contract *client.Contract, banks []bankEntry) {
+	for _, bank := range banks {
+		if bank.InitialReserve == 0 {
+			continue
+		}
+
+		fmt.Printf("\n--> Submit Transaction: Transfer, transfers the initial reserve of %s to %s \n", bank.MSPID, bank.ReserveAccount)
+
+		_, commit, err := contract.SubmitAsync("Transfer", client.WithArguments(bank.ReserveAccount, strconv.FormatUint(bank.InitialReserve, 10), ""))
+		if err != nil {
+			panic(fmt.Errorf("failed to submit transaction: %w", err))
+		}
+		fmt.Println("*** Waiting for transaction commit.")
+
+		if commitStatus, err := commit.Status(); err != nil {
+			panic(fmt.Errorf("failed to get commit status: %w", err))
+		} else if !commitStatus.Successful {
+			panic(fmt.Errorf("transaction %s failed to commit with status: %d", commitStatus.TransactionID, int32(commitStatus.Code)))
+		}
+
+		fmt.Printf("*** Transaction committed successfully\n")
+	}
+}
+
+// Read the commercial banks of the ledger registry
+func getBanks(contract *client.Contract) ([]bankEntry, error) {
+	evaluateResult, err := contract.EvaluateTransaction("GetBanks")
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate transaction: %w", err)
+	}
+	var banks []bankEntry
+	if err := json.Unmarshal(evaluateResult, &banks); err != nil {
+		return nil, fmt.Errorf("failed to parse banks: %w", err)
+	}
+	return banks, nil
+}
+
+// Check that an account is the reserve account of a bank of the ledger registry
+func isReserveAccount(contract *client.Contract, account string) (bool, error) {
+	banks, err := getBanks(contract)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(banks, func(b bankEntry) bool { return b.ReserveAccount == account }), nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	cbdc "app/api"
+	"common/gwstatus"
+	"common/units"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// Approve or reject an open dispute
+func resolveDispute(contract *client.Contract, disputeId string, approve bool, resolution string) (string, error) {
+	fmt.Printf("\n--> Submit Transaction: ResolveDispute, resolves dispute %s\n", disputeId)
+	_, txId, err := gwstatus.SubmitTransaction(contract, "ResolveDispute", disputeId, strconv.FormatBool(approve), resolution)
+	return txId, err
+}
+
+// Move the amount of an approved dispute back to the payer, overriding freezes, liens and limits if asked to
+func reverseDispute(contract *client.Contract, disputeId string, override bool) (string, error) {
+	fmt.Printf("\n--> Submit Transaction: Reverse, reverses dispute %s\n", disputeId)
+	_, txId, err := gwstatus.SubmitTransaction(contract, "Reverse", disputeId, strconv.FormatBool(override))
+	return txId, err
+}
+
+// disputeRecord mirrors the Dispute returned by the chaincode
+type disputeRecord struct {
+	ID           string `json:"id"`
+	TxID         string `json:"txId"`
+	Payer        string `json:"payer"`
+	Payee        string `json:"payee"`
+	Amount       uint64 `json:"amount"`
+	Reason       string `json:"reason"`
+	Status       string `json:"status"`
+	Resolution   string `json:"resolution"`
+	ReversalTxID string `json:"reversalTxId"`
+	Recovered    uint64 `json:"recovered"`
+	Shortfall    uint64 `json:"shortfall"`
+}
+
+// Get a dispute and its status
+func getDispute(contract *client.Contract, disputeId string) (*cbdc.Dispute, error) {
+	fmt.Printf("\n--> Evaluate Transaction: GetDispute, returns dispute %s\n", disputeId)
+	evaluateResult, err := contract.EvaluateTransaction("GetDispute", disputeId)
+	if err != nil {
+		return nil, gwstatus.ToStatus(err)
+	}
+
+	var dispute disputeRecord
+	if err := json.Unmarshal(evaluateResult, &dispute); err != nil {
+		return nil, gwstatus.ToStatus(fmt.Errorf("failed to parse dispute: %w", err))
+	}
+	return &cbdc.Dispute{
+		DisputeId:     dispute.ID,
+		TxId:          dispute.TxID,
+		Payer:         dispute.Payer,
+		Payee:         dispute.Payee,
+		Amount:        dispute.Amount,
+		DecimalAmount: units.Format(dispute.Amount),
+		Reason:        dispute.Reason,
+		Status:        dispute.Status,
+		Resolution:    dispute.Resolution,
+		ReversalTxId:  dispute.ReversalTxID,
+		Recovered:     dispute.Recovered,
+		Shortfall:     dispute.Shortfall,
+	}, nil
+}
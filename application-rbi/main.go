@@ -2,6 +2,8 @@ package main
 
 import (
 	cbdc "app/api"
+	"common/gwstatus"
+	"common/units"
 	"context"
 	"fmt"
 	"google.golang.org/grpc"
@@ -23,6 +25,7 @@ const (
 	peerEndpoint    = "dns:///localhost:7051"
 	gatewayPeer     = "peer0.rbi.cbdc"
 	ApplicationPort = 7999
+	SweepInterval   = time.Hour
 )
 
 type server struct {
@@ -70,14 +73,33 @@ func main() {
 		channelName = cname
 	}
 
+	banksConfig := DefaultBanksConfig
+	if config := os.Getenv("RBI_BANKS_CONFIG"); config != "" {
+		banksConfig = config
+	}
+	banks, err := loadBanks(banksConfig)
+	if err != nil {
+		panic(err)
+	}
+
 	network := gw.GetNetwork(channelName)
 	contract := network.GetContract(chaincodeName)
 	Contract = contract
-	initLedgerIfNotAlready(contract)
+	initLedgerIfNotAlready(contract, banks)
+	if err := units.LoadDecimals(contract); err != nil {
+		log.Fatalln("Failed to load token decimals", err)
+	}
+
+	// Expired funds are reverted periodically
+	go func() {
+		for range time.Tick(SweepInterval) {
+			sweepExpired(contract)
+		}
+	}()
 
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", ApplicationPort))
 	if err != nil {
-		fmt.Errorf(fmt.Sprintf("Failed to listen %v", err))
+		log.Fatalf("Failed to listen %v", err)
 	}
 	var grpcServer = grpc.NewServer()
 	cbdc.RegisterCBDCServer(grpcServer, &server{})
@@ -88,12 +110,79 @@ func main() {
 }
 
 func (s *server) Mint(ctx context.Context, req *cbdc.MintRequest) (*cbdc.MintResponse, error) {
-	txId, acc, amt, suc, msg := mintRequest(Contract, req.Account, req.Amount)
+	value, err := units.Parse(req.DecimalAmount, req.Currency, req.Amount)
+	if err != nil {
+		return nil, gwstatus.InvalidArgument(err)
+	}
+	var txId, acc string
+	var amt uint64
+	if req.Purpose != "" {
+		txId, acc, amt, err = mintPurposeBoundRequest(Contract, req.Account, value, req.Purpose)
+	} else {
+		txId, acc, amt, err = mintRequest(Contract, req.Account, value)
+	}
+	if err != nil {
+		return nil, err
+	}
 	return &cbdc.MintResponse{
-		TxId:    txId,
-		Account: acc,
-		Amount:  amt,
-		Success: suc,
-		Message: msg,
+		TxId:          txId,
+		Account:       acc,
+		Amount:        amt,
+		Success:       true,
+		Message:       "Success!",
+		DecimalAmount: units.Format(amt),
+		Currency:      units.Currency,
 	}, nil
 }
+
+func (s *server) Redeem(ctx context.Context, req *cbdc.RedeemRequest) (*cbdc.RedeemResponse, error) {
+	value, err := units.Parse(req.DecimalAmount, req.Currency, req.Amount)
+	if err != nil {
+		return nil, gwstatus.InvalidArgument(err)
+	}
+	txId, acc, amt, err := redeemRequest(Contract, req.Account, value)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.RedeemResponse{
+		TxId:          txId,
+		Account:       acc,
+		Amount:        amt,
+		Success:       true,
+		Message:       "Success!",
+		DecimalAmount: units.Format(amt),
+		Currency:      units.Currency,
+	}, nil
+}
+
+func (s *server) ResolveDispute(ctx context.Context, req *cbdc.ResolveDisputeRequest) (*cbdc.DisputeResponse, error) {
+	txId, err := resolveDispute(Contract, req.DisputeId, req.Approve, req.Resolution)
+	if err != nil {
+		return nil, err
+	}
+	res := &cbdc.DisputeResponse{TxId: txId, Success: true, Message: "Dispute Resolved Successfully"}
+	res.Dispute, _ = getDispute(Contract, req.DisputeId)
+	return res, nil
+}
+
+func (s *server) Reverse(ctx context.Context, req *cbdc.ReverseRequest) (*cbdc.DisputeResponse, error) {
+	txId, err := reverseDispute(Contract, req.DisputeId, req.Override)
+	if err != nil {
+		return nil, err
+	}
+	res := &cbdc.DisputeResponse{TxId: txId, Success: true, Message: "Dispute Reversed Successfully"}
+	res.Dispute, _ = getDispute(Contract, req.DisputeId)
+	return res, nil
+}
+
+func (s *server) GetDispute(ctx context.Context, req *cbdc.GetDisputeRequest) (*cbdc.DisputeResponse, error) {
+	dispute, err := getDispute(Contract, req.DisputeId)
+	if err != nil {
+		return nil, err
+	}
+	return &cbdc.DisputeResponse{Success: true, Dispute: dispute}, nil
+}
+
+func (s *server) GetNetPositions(ctx context.Context, req *cbdc.GetNetPositionsRequest) (*cbdc.GetNetPositionsResponse, error) {
+	return getNetPositions(Contract, req.Window)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	cbdc "app/api"
+	"common/gwstatus"
+	"common/units"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// netPositions mirrors the NetPositions returned by the chaincode
+type netPositions struct {
+	Window string `json:"window"`
+	Gross  []struct {
+		From   string `json:"from"`
+		To     string `json:"to"`
+		Amount uint64 `json:"amount"`
+	} `json:"gross"`
+	Bilateral []struct {
+		Debtor   string `json:"debtor"`
+		Creditor string `json:"creditor"`
+		Amount   uint64 `json:"amount"`
+	} `json:"bilateral"`
+	Multilateral []struct {
+		Bank   string `json:"bank"`
+		Amount int64  `json:"amount"`
+	} `json:"multilateral"`
+}
+
+// Get the gross interbank flows and the net positions of the banks for a settlement window
+func getNetPositions(contract *client.Contract, window string) (*cbdc.GetNetPositionsResponse, error) {
+	fmt.Printf("\n--> Evaluate Transaction: GetNetPositions, returns the net positions of window %q\n", window)
+	evaluateResult, err := contract.EvaluateTransaction("GetNetPositions", window)
+	if err != nil {
+		return nil, gwstatus.ToStatus(err)
+	}
+
+	var positions netPositions
+	if err := json.Unmarshal(evaluateResult, &positions); err != nil {
+		return nil, gwstatus.ToStatus(fmt.Errorf("failed to parse net positions: %w", err))
+	}
+
+	res := &cbdc.GetNetPositionsResponse{Window: positions.Window, Success: true}
+	for _, flow := range positions.Gross {
+		res.Gross = append(res.Gross, &cbdc.InterbankFlow{
+			FromBank:      flow.From,
+			ToBank:        flow.To,
+			Amount:        flow.Amount,
+			DecimalAmount: units.Format(flow.Amount),
+		})
+	}
+	for _, position := range positions.Bilateral {
+		res.Bilateral = append(res.Bilateral, &cbdc.BilateralPosition{
+			Debtor:        position.Debtor,
+			Creditor:      position.Creditor,
+			Amount:        position.Amount,
+			DecimalAmount: units.Format(position.Amount),
+		})
+	}
+	for _, position := range positions.Multilateral {
+		decimalAmount := units.Format(uint64(position.Amount))
+		if position.Amount < 0 {
+			decimalAmount = "-" + units.Format(uint64(-position.Amount))
+		}
+		res.Multilateral = append(res.Multilateral, &cbdc.BankPosition{
+			Bank:          position.Bank,
+			Amount:        position.Amount,
+			DecimalAmount: decimalAmount,
+		})
+	}
+	return res, nil
+}
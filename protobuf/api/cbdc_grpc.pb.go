@@ -19,24 +19,93 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CBDC_GetBalance_FullMethodName    = "/api.CBDC/GetBalance"
-	CBDC_Tx_FullMethodName            = "/api.CBDC/Tx"
-	CBDC_Fund_FullMethodName          = "/api.CBDC/Fund"
-	CBDC_CreateAccount_FullMethodName = "/api.CBDC/CreateAccount"
-	CBDC_Mint_FullMethodName          = "/api.CBDC/Mint"
+	CBDC_GetBalance_FullMethodName      = "/api.CBDC/GetBalance"
+	CBDC_Tx_FullMethodName              = "/api.CBDC/Tx"
+	CBDC_SubmitSignedTx_FullMethodName  = "/api.CBDC/SubmitSignedTx"
+	CBDC_BulkTx_FullMethodName          = "/api.CBDC/BulkTx"
+	CBDC_Fund_FullMethodName            = "/api.CBDC/Fund"
+	CBDC_GetFundStatus_FullMethodName   = "/api.CBDC/GetFundStatus"
+	CBDC_GetLiquidity_FullMethodName    = "/api.CBDC/GetLiquidity"
+	CBDC_SetLiquidity_FullMethodName    = "/api.CBDC/SetLiquidity"
+	CBDC_CreateAccount_FullMethodName   = "/api.CBDC/CreateAccount"
+	CBDC_Mint_FullMethodName            = "/api.CBDC/Mint"
+	CBDC_Redeem_FullMethodName          = "/api.CBDC/Redeem"
+	CBDC_CreateHTLC_FullMethodName      = "/api.CBDC/CreateHTLC"
+	CBDC_ClaimHTLC_FullMethodName       = "/api.CBDC/ClaimHTLC"
+	CBDC_RefundHTLC_FullMethodName      = "/api.CBDC/RefundHTLC"
+	CBDC_RaiseDispute_FullMethodName    = "/api.CBDC/RaiseDispute"
+	CBDC_ResolveDispute_FullMethodName  = "/api.CBDC/ResolveDispute"
+	CBDC_Reverse_FullMethodName         = "/api.CBDC/Reverse"
+	CBDC_GetDispute_FullMethodName      = "/api.CBDC/GetDispute"
+	CBDC_ProposeTransfer_FullMethodName = "/api.CBDC/ProposeTransfer"
+	CBDC_ApproveProposal_FullMethodName = "/api.CBDC/ApproveProposal"
+	CBDC_CancelProposal_FullMethodName  = "/api.CBDC/CancelProposal"
+	CBDC_ListProposals_FullMethodName   = "/api.CBDC/ListProposals"
+	CBDC_RotateSigners_FullMethodName   = "/api.CBDC/RotateSigners"
+	CBDC_RegisterAlias_FullMethodName   = "/api.CBDC/RegisterAlias"
+	CBDC_ResolveAlias_FullMethodName    = "/api.CBDC/ResolveAlias"
+	CBDC_DeleteAlias_FullMethodName     = "/api.CBDC/DeleteAlias"
+	CBDC_GetDepositLink_FullMethodName  = "/api.CBDC/GetDepositLink"
+	CBDC_UnlinkDeposit_FullMethodName   = "/api.CBDC/UnlinkDeposit"
+	CBDC_GetNetPositions_FullMethodName = "/api.CBDC/GetNetPositions"
+	CBDC_GetHistory_FullMethodName      = "/api.CBDC/GetHistory"
 )
 
 // CBDCClient is the client API for CBDC service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Failed requests return a gRPC status, mapped to an HTTP status by the gateway, instead of a response with success false
+// Errors of the ledger carry a google.rpc.ErrorInfo in domain "cbdc" with the reason and the transaction id, e.g.
+// NOT_FOUND for an unknown account, FAILED_PRECONDITION for insufficient funds, ABORTED for a read conflict to retry,
+// UNAVAILABLE when the transaction was not ordered and UNKNOWN or DEADLINE_EXCEEDED when its outcome is not known
 type CBDCClient interface {
 	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error)
 	// CBDC Transaction
 	Tx(ctx context.Context, in *TxRequest, opts ...grpc.CallOption) (*TxResponse, error)
+	// Relay a transfer signed by the holder of a self-custodial account
+	SubmitSignedTx(ctx context.Context, in *SignedTxRequest, opts ...grpc.CallOption) (*TxResponse, error)
+	// Atomic batch of CBDC transactions from one account, e.g. payroll
+	// The batch emits one TransferBatch chaincode event listing all its legs instead of a Transfer event per leg
+	BulkTx(ctx context.Context, in *BulkTxRequest, opts ...grpc.CallOption) (*BulkTxResponse, error)
 	// Deposit CBDC from UPI or Bank transfers
 	Fund(ctx context.Context, in *FundRequest, opts ...grpc.CallOption) (*FundResponse, error)
+	// Progress of a fund request, to poll it to completion
+	GetFundStatus(ctx context.Context, in *GetFundStatusRequest, opts ...grpc.CallOption) (*FundResponse, error)
+	// Reserve band of the bank reserve account, for the bank operators
+	GetLiquidity(ctx context.Context, in *GetLiquidityRequest, opts ...grpc.CallOption) (*LiquidityResponse, error)
+	SetLiquidity(ctx context.Context, in *SetLiquidityRequest, opts ...grpc.CallOption) (*LiquidityResponse, error)
 	CreateAccount(ctx context.Context, in *CreateAccountRequest, opts ...grpc.CallOption) (*CreateAccountResponse, error)
 	Mint(ctx context.Context, in *MintRequest, opts ...grpc.CallOption) (*MintResponse, error)
+	// Return CBDC from a commercial bank reserve to the central bank
+	Redeem(ctx context.Context, in *RedeemRequest, opts ...grpc.CallOption) (*RedeemResponse, error)
+	// Hash time-locked conditional payments
+	CreateHTLC(ctx context.Context, in *CreateHTLCRequest, opts ...grpc.CallOption) (*HTLCResponse, error)
+	ClaimHTLC(ctx context.Context, in *ClaimHTLCRequest, opts ...grpc.CallOption) (*HTLCResponse, error)
+	RefundHTLC(ctx context.Context, in *RefundHTLCRequest, opts ...grpc.CallOption) (*HTLCResponse, error)
+	// Disputes over fraudulent or erroneous transfers, raised by the payer's bank and settled by the central bank
+	RaiseDispute(ctx context.Context, in *RaiseDisputeRequest, opts ...grpc.CallOption) (*DisputeResponse, error)
+	ResolveDispute(ctx context.Context, in *ResolveDisputeRequest, opts ...grpc.CallOption) (*DisputeResponse, error)
+	Reverse(ctx context.Context, in *ReverseRequest, opts ...grpc.CallOption) (*DisputeResponse, error)
+	GetDispute(ctx context.Context, in *GetDisputeRequest, opts ...grpc.CallOption) (*DisputeResponse, error)
+	// Transfers from multisig accounts, proposed and approved by their signers and relayed by the bank
+	ProposeTransfer(ctx context.Context, in *ProposeTransferRequest, opts ...grpc.CallOption) (*ProposalResponse, error)
+	ApproveProposal(ctx context.Context, in *ApproveProposalRequest, opts ...grpc.CallOption) (*ProposalResponse, error)
+	CancelProposal(ctx context.Context, in *CancelProposalRequest, opts ...grpc.CallOption) (*ProposalResponse, error)
+	ListProposals(ctx context.Context, in *ListProposalsRequest, opts ...grpc.CallOption) (*ListProposalsResponse, error)
+	// Replace the signers and threshold of a multisig account, signed by the threshold of its current signers
+	RotateSigners(ctx context.Context, in *RotateSignersRequest, opts ...grpc.CallOption) (*RotateSignersResponse, error)
+	// Aliases such as priya@hdfc resolving to accounts, each bank manages the aliases with its own suffix
+	RegisterAlias(ctx context.Context, in *RegisterAliasRequest, opts ...grpc.CallOption) (*AliasResponse, error)
+	ResolveAlias(ctx context.Context, in *ResolveAliasRequest, opts ...grpc.CallOption) (*AliasResponse, error)
+	DeleteAlias(ctx context.Context, in *DeleteAliasRequest, opts ...grpc.CallOption) (*AliasResponse, error)
+	// Deposit accounts linked by Fund, the bank sweeps the linked wallets between a floor and a cap
+	GetDepositLink(ctx context.Context, in *GetDepositLinkRequest, opts ...grpc.CallOption) (*DepositLinkResponse, error)
+	UnlinkDeposit(ctx context.Context, in *UnlinkDepositRequest, opts ...grpc.CallOption) (*DepositLinkResponse, error)
+	// Gross interbank flows and net settlement positions of a settlement window
+	GetNetPositions(ctx context.Context, in *GetNetPositionsRequest, opts ...grpc.CallOption) (*GetNetPositionsResponse, error)
+	// Transaction history of an account
+	GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error)
 }
 
 type cBDCClient struct {
@@ -67,6 +136,26 @@ func (c *cBDCClient) Tx(ctx context.Context, in *TxRequest, opts ...grpc.CallOpt
 	return out, nil
 }
 
+func (c *cBDCClient) SubmitSignedTx(ctx context.Context, in *SignedTxRequest, opts ...grpc.CallOption) (*TxResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxResponse)
+	err := c.cc.Invoke(ctx, CBDC_SubmitSignedTx_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) BulkTx(ctx context.Context, in *BulkTxRequest, opts ...grpc.CallOption) (*BulkTxResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BulkTxResponse)
+	err := c.cc.Invoke(ctx, CBDC_BulkTx_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *cBDCClient) Fund(ctx context.Context, in *FundRequest, opts ...grpc.CallOption) (*FundResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(FundResponse)
@@ -77,6 +166,36 @@ func (c *cBDCClient) Fund(ctx context.Context, in *FundRequest, opts ...grpc.Cal
 	return out, nil
 }
 
+func (c *cBDCClient) GetFundStatus(ctx context.Context, in *GetFundStatusRequest, opts ...grpc.CallOption) (*FundResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FundResponse)
+	err := c.cc.Invoke(ctx, CBDC_GetFundStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) GetLiquidity(ctx context.Context, in *GetLiquidityRequest, opts ...grpc.CallOption) (*LiquidityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LiquidityResponse)
+	err := c.cc.Invoke(ctx, CBDC_GetLiquidity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) SetLiquidity(ctx context.Context, in *SetLiquidityRequest, opts ...grpc.CallOption) (*LiquidityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LiquidityResponse)
+	err := c.cc.Invoke(ctx, CBDC_SetLiquidity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *cBDCClient) CreateAccount(ctx context.Context, in *CreateAccountRequest, opts ...grpc.CallOption) (*CreateAccountResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CreateAccountResponse)
@@ -97,17 +216,261 @@ func (c *cBDCClient) Mint(ctx context.Context, in *MintRequest, opts ...grpc.Cal
 	return out, nil
 }
 
+func (c *cBDCClient) Redeem(ctx context.Context, in *RedeemRequest, opts ...grpc.CallOption) (*RedeemResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RedeemResponse)
+	err := c.cc.Invoke(ctx, CBDC_Redeem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) CreateHTLC(ctx context.Context, in *CreateHTLCRequest, opts ...grpc.CallOption) (*HTLCResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HTLCResponse)
+	err := c.cc.Invoke(ctx, CBDC_CreateHTLC_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) ClaimHTLC(ctx context.Context, in *ClaimHTLCRequest, opts ...grpc.CallOption) (*HTLCResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HTLCResponse)
+	err := c.cc.Invoke(ctx, CBDC_ClaimHTLC_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) RefundHTLC(ctx context.Context, in *RefundHTLCRequest, opts ...grpc.CallOption) (*HTLCResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HTLCResponse)
+	err := c.cc.Invoke(ctx, CBDC_RefundHTLC_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) RaiseDispute(ctx context.Context, in *RaiseDisputeRequest, opts ...grpc.CallOption) (*DisputeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisputeResponse)
+	err := c.cc.Invoke(ctx, CBDC_RaiseDispute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) ResolveDispute(ctx context.Context, in *ResolveDisputeRequest, opts ...grpc.CallOption) (*DisputeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisputeResponse)
+	err := c.cc.Invoke(ctx, CBDC_ResolveDispute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) Reverse(ctx context.Context, in *ReverseRequest, opts ...grpc.CallOption) (*DisputeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisputeResponse)
+	err := c.cc.Invoke(ctx, CBDC_Reverse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) GetDispute(ctx context.Context, in *GetDisputeRequest, opts ...grpc.CallOption) (*DisputeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisputeResponse)
+	err := c.cc.Invoke(ctx, CBDC_GetDispute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) ProposeTransfer(ctx context.Context, in *ProposeTransferRequest, opts ...grpc.CallOption) (*ProposalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProposalResponse)
+	err := c.cc.Invoke(ctx, CBDC_ProposeTransfer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) ApproveProposal(ctx context.Context, in *ApproveProposalRequest, opts ...grpc.CallOption) (*ProposalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProposalResponse)
+	err := c.cc.Invoke(ctx, CBDC_ApproveProposal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) CancelProposal(ctx context.Context, in *CancelProposalRequest, opts ...grpc.CallOption) (*ProposalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProposalResponse)
+	err := c.cc.Invoke(ctx, CBDC_CancelProposal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) ListProposals(ctx context.Context, in *ListProposalsRequest, opts ...grpc.CallOption) (*ListProposalsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProposalsResponse)
+	err := c.cc.Invoke(ctx, CBDC_ListProposals_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) RotateSigners(ctx context.Context, in *RotateSignersRequest, opts ...grpc.CallOption) (*RotateSignersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RotateSignersResponse)
+	err := c.cc.Invoke(ctx, CBDC_RotateSigners_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) RegisterAlias(ctx context.Context, in *RegisterAliasRequest, opts ...grpc.CallOption) (*AliasResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AliasResponse)
+	err := c.cc.Invoke(ctx, CBDC_RegisterAlias_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) ResolveAlias(ctx context.Context, in *ResolveAliasRequest, opts ...grpc.CallOption) (*AliasResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AliasResponse)
+	err := c.cc.Invoke(ctx, CBDC_ResolveAlias_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) DeleteAlias(ctx context.Context, in *DeleteAliasRequest, opts ...grpc.CallOption) (*AliasResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AliasResponse)
+	err := c.cc.Invoke(ctx, CBDC_DeleteAlias_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) GetDepositLink(ctx context.Context, in *GetDepositLinkRequest, opts ...grpc.CallOption) (*DepositLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DepositLinkResponse)
+	err := c.cc.Invoke(ctx, CBDC_GetDepositLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) UnlinkDeposit(ctx context.Context, in *UnlinkDepositRequest, opts ...grpc.CallOption) (*DepositLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DepositLinkResponse)
+	err := c.cc.Invoke(ctx, CBDC_UnlinkDeposit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) GetNetPositions(ctx context.Context, in *GetNetPositionsRequest, opts ...grpc.CallOption) (*GetNetPositionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetNetPositionsResponse)
+	err := c.cc.Invoke(ctx, CBDC_GetNetPositions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cBDCClient) GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetHistoryResponse)
+	err := c.cc.Invoke(ctx, CBDC_GetHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CBDCServer is the server API for CBDC service.
 // All implementations must embed UnimplementedCBDCServer
 // for forward compatibility.
+//
+// Failed requests return a gRPC status, mapped to an HTTP status by the gateway, instead of a response with success false
+// Errors of the ledger carry a google.rpc.ErrorInfo in domain "cbdc" with the reason and the transaction id, e.g.
+// NOT_FOUND for an unknown account, FAILED_PRECONDITION for insufficient funds, ABORTED for a read conflict to retry,
+// UNAVAILABLE when the transaction was not ordered and UNKNOWN or DEADLINE_EXCEEDED when its outcome is not known
 type CBDCServer interface {
 	GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error)
 	// CBDC Transaction
 	Tx(context.Context, *TxRequest) (*TxResponse, error)
+	// Relay a transfer signed by the holder of a self-custodial account
+	SubmitSignedTx(context.Context, *SignedTxRequest) (*TxResponse, error)
+	// Atomic batch of CBDC transactions from one account, e.g. payroll
+	// The batch emits one TransferBatch chaincode event listing all its legs instead of a Transfer event per leg
+	BulkTx(context.Context, *BulkTxRequest) (*BulkTxResponse, error)
 	// Deposit CBDC from UPI or Bank transfers
 	Fund(context.Context, *FundRequest) (*FundResponse, error)
+	// Progress of a fund request, to poll it to completion
+	GetFundStatus(context.Context, *GetFundStatusRequest) (*FundResponse, error)
+	// Reserve band of the bank reserve account, for the bank operators
+	GetLiquidity(context.Context, *GetLiquidityRequest) (*LiquidityResponse, error)
+	SetLiquidity(context.Context, *SetLiquidityRequest) (*LiquidityResponse, error)
 	CreateAccount(context.Context, *CreateAccountRequest) (*CreateAccountResponse, error)
 	Mint(context.Context, *MintRequest) (*MintResponse, error)
+	// Return CBDC from a commercial bank reserve to the central bank
+	Redeem(context.Context, *RedeemRequest) (*RedeemResponse, error)
+	// Hash time-locked conditional payments
+	CreateHTLC(context.Context, *CreateHTLCRequest) (*HTLCResponse, error)
+	ClaimHTLC(context.Context, *ClaimHTLCRequest) (*HTLCResponse, error)
+	RefundHTLC(context.Context, *RefundHTLCRequest) (*HTLCResponse, error)
+	// Disputes over fraudulent or erroneous transfers, raised by the payer's bank and settled by the central bank
+	RaiseDispute(context.Context, *RaiseDisputeRequest) (*DisputeResponse, error)
+	ResolveDispute(context.Context, *ResolveDisputeRequest) (*DisputeResponse, error)
+	Reverse(context.Context, *ReverseRequest) (*DisputeResponse, error)
+	GetDispute(context.Context, *GetDisputeRequest) (*DisputeResponse, error)
+	// Transfers from multisig accounts, proposed and approved by their signers and relayed by the bank
+	ProposeTransfer(context.Context, *ProposeTransferRequest) (*ProposalResponse, error)
+	ApproveProposal(context.Context, *ApproveProposalRequest) (*ProposalResponse, error)
+	CancelProposal(context.Context, *CancelProposalRequest) (*ProposalResponse, error)
+	ListProposals(context.Context, *ListProposalsRequest) (*ListProposalsResponse, error)
+	// Replace the signers and threshold of a multisig account, signed by the threshold of its current signers
+	RotateSigners(context.Context, *RotateSignersRequest) (*RotateSignersResponse, error)
+	// Aliases such as priya@hdfc resolving to accounts, each bank manages the aliases with its own suffix
+	RegisterAlias(context.Context, *RegisterAliasRequest) (*AliasResponse, error)
+	ResolveAlias(context.Context, *ResolveAliasRequest) (*AliasResponse, error)
+	DeleteAlias(context.Context, *DeleteAliasRequest) (*AliasResponse, error)
+	// Deposit accounts linked by Fund, the bank sweeps the linked wallets between a floor and a cap
+	GetDepositLink(context.Context, *GetDepositLinkRequest) (*DepositLinkResponse, error)
+	UnlinkDeposit(context.Context, *UnlinkDepositRequest) (*DepositLinkResponse, error)
+	// Gross interbank flows and net settlement positions of a settlement window
+	GetNetPositions(context.Context, *GetNetPositionsRequest) (*GetNetPositionsResponse, error)
+	// Transaction history of an account
+	GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error)
 	mustEmbedUnimplementedCBDCServer()
 }
 
@@ -124,15 +487,90 @@ func (UnimplementedCBDCServer) GetBalance(context.Context, *GetBalanceRequest) (
 func (UnimplementedCBDCServer) Tx(context.Context, *TxRequest) (*TxResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Tx not implemented")
 }
+func (UnimplementedCBDCServer) SubmitSignedTx(context.Context, *SignedTxRequest) (*TxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitSignedTx not implemented")
+}
+func (UnimplementedCBDCServer) BulkTx(context.Context, *BulkTxRequest) (*BulkTxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkTx not implemented")
+}
 func (UnimplementedCBDCServer) Fund(context.Context, *FundRequest) (*FundResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Fund not implemented")
 }
+func (UnimplementedCBDCServer) GetFundStatus(context.Context, *GetFundStatusRequest) (*FundResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFundStatus not implemented")
+}
+func (UnimplementedCBDCServer) GetLiquidity(context.Context, *GetLiquidityRequest) (*LiquidityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLiquidity not implemented")
+}
+func (UnimplementedCBDCServer) SetLiquidity(context.Context, *SetLiquidityRequest) (*LiquidityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLiquidity not implemented")
+}
 func (UnimplementedCBDCServer) CreateAccount(context.Context, *CreateAccountRequest) (*CreateAccountResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateAccount not implemented")
 }
 func (UnimplementedCBDCServer) Mint(context.Context, *MintRequest) (*MintResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Mint not implemented")
 }
+func (UnimplementedCBDCServer) Redeem(context.Context, *RedeemRequest) (*RedeemResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Redeem not implemented")
+}
+func (UnimplementedCBDCServer) CreateHTLC(context.Context, *CreateHTLCRequest) (*HTLCResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateHTLC not implemented")
+}
+func (UnimplementedCBDCServer) ClaimHTLC(context.Context, *ClaimHTLCRequest) (*HTLCResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClaimHTLC not implemented")
+}
+func (UnimplementedCBDCServer) RefundHTLC(context.Context, *RefundHTLCRequest) (*HTLCResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefundHTLC not implemented")
+}
+func (UnimplementedCBDCServer) RaiseDispute(context.Context, *RaiseDisputeRequest) (*DisputeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RaiseDispute not implemented")
+}
+func (UnimplementedCBDCServer) ResolveDispute(context.Context, *ResolveDisputeRequest) (*DisputeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveDispute not implemented")
+}
+func (UnimplementedCBDCServer) Reverse(context.Context, *ReverseRequest) (*DisputeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reverse not implemented")
+}
+func (UnimplementedCBDCServer) GetDispute(context.Context, *GetDisputeRequest) (*DisputeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDispute not implemented")
+}
+func (UnimplementedCBDCServer) ProposeTransfer(context.Context, *ProposeTransferRequest) (*ProposalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProposeTransfer not implemented")
+}
+func (UnimplementedCBDCServer) ApproveProposal(context.Context, *ApproveProposalRequest) (*ProposalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApproveProposal not implemented")
+}
+func (UnimplementedCBDCServer) CancelProposal(context.Context, *CancelProposalRequest) (*ProposalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelProposal not implemented")
+}
+func (UnimplementedCBDCServer) ListProposals(context.Context, *ListProposalsRequest) (*ListProposalsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProposals not implemented")
+}
+func (UnimplementedCBDCServer) RotateSigners(context.Context, *RotateSignersRequest) (*RotateSignersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateSigners not implemented")
+}
+func (UnimplementedCBDCServer) RegisterAlias(context.Context, *RegisterAliasRequest) (*AliasResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterAlias not implemented")
+}
+func (UnimplementedCBDCServer) ResolveAlias(context.Context, *ResolveAliasRequest) (*AliasResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveAlias not implemented")
+}
+func (UnimplementedCBDCServer) DeleteAlias(context.Context, *DeleteAliasRequest) (*AliasResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAlias not implemented")
+}
+func (UnimplementedCBDCServer) GetDepositLink(context.Context, *GetDepositLinkRequest) (*DepositLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDepositLink not implemented")
+}
+func (UnimplementedCBDCServer) UnlinkDeposit(context.Context, *UnlinkDepositRequest) (*DepositLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnlinkDeposit not implemented")
+}
+func (UnimplementedCBDCServer) GetNetPositions(context.Context, *GetNetPositionsRequest) (*GetNetPositionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNetPositions not implemented")
+}
+func (UnimplementedCBDCServer) GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHistory not implemented")
+}
 func (UnimplementedCBDCServer) mustEmbedUnimplementedCBDCServer() {}
 func (UnimplementedCBDCServer) testEmbeddedByValue()              {}
 
@@ -190,6 +628,42 @@ func _CBDC_Tx_Handler(srv interface{}, ctx context.Context, dec func(interface{}
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CBDC_SubmitSignedTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignedTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).SubmitSignedTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_SubmitSignedTx_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).SubmitSignedTx(ctx, req.(*SignedTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_BulkTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).BulkTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_BulkTx_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).BulkTx(ctx, req.(*BulkTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CBDC_Fund_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(FundRequest)
 	if err := dec(in); err != nil {
@@ -208,61 +682,495 @@ func _CBDC_Fund_Handler(srv interface{}, ctx context.Context, dec func(interface
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CBDC_CreateAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateAccountRequest)
+func _CBDC_GetFundStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFundStatusRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CBDCServer).CreateAccount(ctx, in)
+		return srv.(CBDCServer).GetFundStatus(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: CBDC_CreateAccount_FullMethodName,
+		FullMethod: CBDC_GetFundStatus_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CBDCServer).CreateAccount(ctx, req.(*CreateAccountRequest))
+		return srv.(CBDCServer).GetFundStatus(ctx, req.(*GetFundStatusRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CBDC_Mint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(MintRequest)
+func _CBDC_GetLiquidity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLiquidityRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CBDCServer).Mint(ctx, in)
+		return srv.(CBDCServer).GetLiquidity(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: CBDC_Mint_FullMethodName,
+		FullMethod: CBDC_GetLiquidity_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CBDCServer).Mint(ctx, req.(*MintRequest))
+		return srv.(CBDCServer).GetLiquidity(ctx, req.(*GetLiquidityRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// CBDC_ServiceDesc is the grpc.ServiceDesc for CBDC service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var CBDC_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "api.CBDC",
-	HandlerType: (*CBDCServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "GetBalance",
-			Handler:    _CBDC_GetBalance_Handler,
-		},
-		{
-			MethodName: "Tx",
-			Handler:    _CBDC_Tx_Handler,
-		},
-		{
+func _CBDC_SetLiquidity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLiquidityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).SetLiquidity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_SetLiquidity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).SetLiquidity(ctx, req.(*SetLiquidityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_CreateAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).CreateAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_CreateAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).CreateAccount(ctx, req.(*CreateAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_Mint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MintRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).Mint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_Mint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).Mint(ctx, req.(*MintRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_Redeem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RedeemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).Redeem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_Redeem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).Redeem(ctx, req.(*RedeemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_CreateHTLC_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateHTLCRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).CreateHTLC(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_CreateHTLC_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).CreateHTLC(ctx, req.(*CreateHTLCRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_ClaimHTLC_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClaimHTLCRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).ClaimHTLC(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_ClaimHTLC_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).ClaimHTLC(ctx, req.(*ClaimHTLCRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_RefundHTLC_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefundHTLCRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).RefundHTLC(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_RefundHTLC_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).RefundHTLC(ctx, req.(*RefundHTLCRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_RaiseDispute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RaiseDisputeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).RaiseDispute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_RaiseDispute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).RaiseDispute(ctx, req.(*RaiseDisputeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_ResolveDispute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveDisputeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).ResolveDispute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_ResolveDispute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).ResolveDispute(ctx, req.(*ResolveDisputeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_Reverse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReverseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).Reverse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_Reverse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).Reverse(ctx, req.(*ReverseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_GetDispute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDisputeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).GetDispute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_GetDispute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).GetDispute(ctx, req.(*GetDisputeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_ProposeTransfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProposeTransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).ProposeTransfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_ProposeTransfer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).ProposeTransfer(ctx, req.(*ProposeTransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_ApproveProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApproveProposalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).ApproveProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_ApproveProposal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).ApproveProposal(ctx, req.(*ApproveProposalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_CancelProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelProposalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).CancelProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_CancelProposal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).CancelProposal(ctx, req.(*CancelProposalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_ListProposals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProposalsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).ListProposals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_ListProposals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).ListProposals(ctx, req.(*ListProposalsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_RotateSigners_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateSignersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).RotateSigners(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_RotateSigners_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).RotateSigners(ctx, req.(*RotateSignersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_RegisterAlias_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterAliasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).RegisterAlias(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_RegisterAlias_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).RegisterAlias(ctx, req.(*RegisterAliasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_ResolveAlias_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveAliasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).ResolveAlias(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_ResolveAlias_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).ResolveAlias(ctx, req.(*ResolveAliasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_DeleteAlias_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAliasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).DeleteAlias(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_DeleteAlias_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).DeleteAlias(ctx, req.(*DeleteAliasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_GetDepositLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDepositLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).GetDepositLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_GetDepositLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).GetDepositLink(ctx, req.(*GetDepositLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_UnlinkDeposit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnlinkDepositRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).UnlinkDeposit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_UnlinkDeposit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).UnlinkDeposit(ctx, req.(*UnlinkDepositRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_GetNetPositions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNetPositionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).GetNetPositions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_GetNetPositions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).GetNetPositions(ctx, req.(*GetNetPositionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CBDC_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CBDCServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CBDC_GetHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CBDCServer).GetHistory(ctx, req.(*GetHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CBDC_ServiceDesc is the grpc.ServiceDesc for CBDC service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CBDC_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "api.CBDC",
+	HandlerType: (*CBDCServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetBalance",
+			Handler:    _CBDC_GetBalance_Handler,
+		},
+		{
+			MethodName: "Tx",
+			Handler:    _CBDC_Tx_Handler,
+		},
+		{
+			MethodName: "SubmitSignedTx",
+			Handler:    _CBDC_SubmitSignedTx_Handler,
+		},
+		{
+			MethodName: "BulkTx",
+			Handler:    _CBDC_BulkTx_Handler,
+		},
+		{
 			MethodName: "Fund",
 			Handler:    _CBDC_Fund_Handler,
 		},
+		{
+			MethodName: "GetFundStatus",
+			Handler:    _CBDC_GetFundStatus_Handler,
+		},
+		{
+			MethodName: "GetLiquidity",
+			Handler:    _CBDC_GetLiquidity_Handler,
+		},
+		{
+			MethodName: "SetLiquidity",
+			Handler:    _CBDC_SetLiquidity_Handler,
+		},
 		{
 			MethodName: "CreateAccount",
 			Handler:    _CBDC_CreateAccount_Handler,
@@ -271,6 +1179,86 @@ var CBDC_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Mint",
 			Handler:    _CBDC_Mint_Handler,
 		},
+		{
+			MethodName: "Redeem",
+			Handler:    _CBDC_Redeem_Handler,
+		},
+		{
+			MethodName: "CreateHTLC",
+			Handler:    _CBDC_CreateHTLC_Handler,
+		},
+		{
+			MethodName: "ClaimHTLC",
+			Handler:    _CBDC_ClaimHTLC_Handler,
+		},
+		{
+			MethodName: "RefundHTLC",
+			Handler:    _CBDC_RefundHTLC_Handler,
+		},
+		{
+			MethodName: "RaiseDispute",
+			Handler:    _CBDC_RaiseDispute_Handler,
+		},
+		{
+			MethodName: "ResolveDispute",
+			Handler:    _CBDC_ResolveDispute_Handler,
+		},
+		{
+			MethodName: "Reverse",
+			Handler:    _CBDC_Reverse_Handler,
+		},
+		{
+			MethodName: "GetDispute",
+			Handler:    _CBDC_GetDispute_Handler,
+		},
+		{
+			MethodName: "ProposeTransfer",
+			Handler:    _CBDC_ProposeTransfer_Handler,
+		},
+		{
+			MethodName: "ApproveProposal",
+			Handler:    _CBDC_ApproveProposal_Handler,
+		},
+		{
+			MethodName: "CancelProposal",
+			Handler:    _CBDC_CancelProposal_Handler,
+		},
+		{
+			MethodName: "ListProposals",
+			Handler:    _CBDC_ListProposals_Handler,
+		},
+		{
+			MethodName: "RotateSigners",
+			Handler:    _CBDC_RotateSigners_Handler,
+		},
+		{
+			MethodName: "RegisterAlias",
+			Handler:    _CBDC_RegisterAlias_Handler,
+		},
+		{
+			MethodName: "ResolveAlias",
+			Handler:    _CBDC_ResolveAlias_Handler,
+		},
+		{
+			MethodName: "DeleteAlias",
+			Handler:    _CBDC_DeleteAlias_Handler,
+		},
+		{
+			MethodName: "GetDepositLink",
+			Handler:    _CBDC_GetDepositLink_Handler,
+		},
+		{
+			MethodName: "UnlinkDeposit",
+			Handler:    _CBDC_UnlinkDeposit_Handler,
+		},
+		{
+			MethodName: "GetNetPositions",
+			Handler:    _CBDC_GetNetPositions_Handler,
+		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _CBDC_GetHistory_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/cbdc.proto",
@@ -0,0 +1,430 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Define objectType names for account records
+const accountPrefix = "account"
+
+// Define account types
+const accountTypeRetail = "RETAIL"
+const accountTypeReserve = "RESERVE"
+const accountTypeEscrow = "ESCROW"
+const accountTypeMultisig = "MULTISIG"
+
+// Define account statuses
+const accountStatusActive = "ACTIVE"
+const accountStatusFrozen = "FROZEN"
+const accountStatusDebitFrozen = "DEBIT_FROZEN"
+const accountStatusClosed = "CLOSED"
+
+// Account is the on-chain registration record of a CBDC account
+type Account struct {
+	ID        string `json:"id"`
+	Bank      string `json:"bank"`
+	Type      string `json:"type"`
+	KYCTier   int    `json:"kycTier"`
+	Status    string `json:"status"`
+	CreatedAt int64  `json:"createdAt"`
+
+	// MSP of the bank holding the mandate to debit the account on behalf of its holder
+	Custodian string `json:"custodian"`
+
+	// Reason recorded by the order that last changed the account status and the MSP that issued it
+	// Only the central bank and the issuer can lift a freeze
+	StatusReason string `json:"statusReason,omitempty"`
+	StatusIssuer string `json:"statusIssuer,omitempty"`
+
+	// Amount of the balance that cannot be debited while a lien is in place, the total of Liens
+	// Liens placed before issuers were recorded count in Lien only and can only be released by the central bank
+	Lien       int     `json:"lien"`
+	LienReason string  `json:"lienReason,omitempty"`
+	Liens      []*Lien `json:"liens,omitempty"`
+
+	// Merchant category code of a merchant account, empty for other accounts
+	MerchantCategory string `json:"merchantCategory,omitempty"`
+
+	// Public key of the holder of a self-custodial account as base64 DER, empty for other accounts
+	// Debits of a self-custodial account must be signed by its holder
+	PublicKey string `json:"publicKey,omitempty"`
+
+	// Signatories of a multisig account and the number of their approvals a debit needs
+	// SignerKeys holds the base64 DER public key of each signer, in the order of Signers
+	Signers    []string `json:"signers,omitempty"`
+	SignerKeys []string `json:"signerKeys,omitempty"`
+	Threshold  int      `json:"threshold,omitempty"`
+}
+
+// RegisterAccount registers a retail account owned by the calling commercial bank
+// The account must be registered before it can receive any funds
+// param {String} account The checksummed address of the account with the address prefix of the bank
+// param {String} merchantCategory The merchant category code of a merchant account, empty for other accounts
+func (s *SmartContract) RegisterAccount(ctx contractapi.TransactionContextInterface, account string, kycTier int, merchantCategory string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	// Only commercial banks onboard retail customers
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	isBank, err := isCommercialBank(ctx, clientMSPID)
+	if err != nil {
+		return err
+	}
+	if !isBank {
+		return fmt.Errorf("%w: client with id %s is not authorized to register accounts", ErrUnauthorized, clientMSPID)
+	}
+
+	if kycTier < 0 {
+		return fmt.Errorf("%w: kyc tier cannot be negative", ErrInvalidArgument)
+	}
+
+	// Retail accounts are registered under an address of the bank, mistyped ids cannot create new accounts
+	err = checkAddress(ctx, clientMSPID, account)
+	if err != nil {
+		return err
+	}
+
+	return registerAccount(ctx, account, clientMSPID, accountTypeRetail, kycTier, merchantCategory)
+}
+
+// RegisterReserveAccount registers the reserve account through which a commercial bank receives CBDC from the central bank
+func (s *SmartContract) RegisterReserveAccount(ctx contractapi.TransactionContextInterface, account string, bank string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	// Check central banker authorization
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != CentralBankerMSPId {
+		return fmt.Errorf("%w: client with id %s is not authorized to register reserve accounts", ErrUnauthorized, clientMSPID)
+	}
+
+	isBank, err := isCommercialBank(ctx, bank)
+	if err != nil {
+		return err
+	}
+	if !isBank {
+		return fmt.Errorf("%s is not a registered commercial bank", bank)
+	}
+
+	return registerAccount(ctx, account, bank, accountTypeReserve, 0, "")
+}
+
+// GetAccount returns the registration record of the given account
+func (s *SmartContract) GetAccount(ctx contractapi.TransactionContextInterface, account string) (*Account, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	acc, err := getAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	if acc == nil {
+		return nil, fmt.Errorf("%w: the account %s is not registered", ErrNotFound, account)
+	}
+
+	return acc, nil
+}
+
+// CloseAccount closes an account with a zero balance
+// Only the owning bank or the central bank can close an account
+func (s *SmartContract) CloseAccount(ctx contractapi.TransactionContextInterface, account string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+
+	acc, err := getAccount(ctx, account)
+	if err != nil {
+		return err
+	}
+	if acc == nil {
+		return fmt.Errorf("%w: the account %s is not registered", ErrNotFound, account)
+	}
+	if clientMSPID != acc.Bank && clientMSPID != CentralBankerMSPId {
+		return fmt.Errorf("%w: client with id %s is not authorized to close account %s", ErrUnauthorized, clientMSPID, account)
+	}
+	if acc.Status == accountStatusClosed {
+		return fmt.Errorf("the account %s is already closed", account)
+	}
+	if acc.Status != accountStatusActive || acc.Lien > 0 {
+		return fmt.Errorf("the account %s is under a freeze or lien order", account)
+	}
+
+	balance, _, err := readBalance(ctx, account)
+	if err != nil {
+		return err
+	}
+	if balance.Sign() != 0 {
+		return fmt.Errorf("the account %s still holds a balance of %s", account, balance)
+	}
+
+	acc.Status = accountStatusClosed
+	err = putAccount(ctx, acc)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("account %s closed by %s", account, clientMSPID)
+
+	return nil
+}
+
+// SetCustodian moves the custody mandate of an account to another commercial bank
+// Only the current custodian or the central bank can move the mandate
+func (s *SmartContract) SetCustodian(ctx contractapi.TransactionContextInterface, account string, custodian string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+
+	acc, err := getAccount(ctx, account)
+	if err != nil {
+		return err
+	}
+	if acc == nil {
+		return fmt.Errorf("%w: the account %s is not registered", ErrNotFound, account)
+	}
+	if clientMSPID != acc.custodian() && clientMSPID != CentralBankerMSPId {
+		return fmt.Errorf("%w: client with id %s is not authorized to move the custody mandate of account %s", ErrUnauthorized, clientMSPID, account)
+	}
+	isBank, err := isCommercialBank(ctx, custodian)
+	if err != nil {
+		return err
+	}
+	if !isBank {
+		return fmt.Errorf("%s is not a registered commercial bank", custodian)
+	}
+
+	previous := acc.custodian()
+	acc.Custodian = custodian
+	err = putAccount(ctx, acc)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("custody mandate of account %s moved from %s to %s", account, previous, custodian)
+
+	return nil
+}
+
+// SetMerchantCategory sets the merchant category code of a retail account, an empty code makes it a non-merchant account
+// Only the owning bank or the central bank can set the merchant category
+func (s *SmartContract) SetMerchantCategory(ctx contractapi.TransactionContextInterface, account string, merchantCategory string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+
+	acc, err := getAccount(ctx, account)
+	if err != nil {
+		return err
+	}
+	if acc == nil {
+		return fmt.Errorf("%w: the account %s is not registered", ErrNotFound, account)
+	}
+	if clientMSPID != acc.Bank && clientMSPID != CentralBankerMSPId {
+		return fmt.Errorf("%w: client with id %s is not authorized to set the merchant category of account %s", ErrUnauthorized, clientMSPID, account)
+	}
+	if acc.Type != accountTypeRetail {
+		return fmt.Errorf("%s is not a retail account", account)
+	}
+
+	acc.MerchantCategory = merchantCategory
+	err = putAccount(ctx, acc)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("merchant category of account %s set to %q", account, merchantCategory)
+
+	return nil
+}
+
+// custodian returns the MSP holding the custody mandate of the account
+// Accounts registered before custody mandates were recorded are held by their owning bank
+func (acc *Account) custodian() string {
+	if acc.Custodian == "" {
+		return acc.Bank
+	}
+	return acc.Custodian
+}
+
+// signerKey returns the registered public key of a signer of a multisig account
+func (acc *Account) signerKey(signer string) (string, error) {
+	i := slices.Index(acc.Signers, signer)
+	if i < 0 || i >= len(acc.SignerKeys) {
+		return "", fmt.Errorf("%s is not a signer of %s", signer, acc.ID)
+	}
+	return acc.SignerKeys[i], nil
+}
+
+// checkDirectDebit fails for the accounts its custodian cannot debit on its own:
+// self-custodial accounts are only debited by TransferSigned, multisig accounts by an approved proposal
+func checkDirectDebit(acc *Account) error {
+	if acc != nil && acc.PublicKey != "" {
+		return fmt.Errorf("%w: account %s is self-custodial, its debits must be signed by its holder", ErrUnauthorized, acc.ID)
+	}
+	if acc != nil && acc.Type == accountTypeMultisig {
+		return fmt.Errorf("%w: account %s is a multisig account, its debits must be proposed and approved by its signers", ErrUnauthorized, acc.ID)
+	}
+	return nil
+}
+
+// registerAccount stores a new account record, failing if the account is already registered
+func registerAccount(ctx contractapi.TransactionContextInterface, account string, bank string, accountType string, kycTier int, merchantCategory string) error {
+
+	acc, err := newAccount(ctx, account, bank, accountType, kycTier, merchantCategory)
+	if err != nil {
+		return err
+	}
+
+	err = putAccount(ctx, acc)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("%s account %s registered for %s", accountType, account, bank)
+
+	return nil
+}
+
+// newAccount creates the record of an account that is not registered yet
+func newAccount(ctx contractapi.TransactionContextInterface, account string, bank string, accountType string, kycTier int, merchantCategory string) (*Account, error) {
+
+	if account == "" {
+		return nil, fmt.Errorf("%w: account id cannot be empty", ErrInvalidArgument)
+	}
+
+	existing, err := getAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: the account %s is already registered", ErrAlreadyExists, account)
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	return &Account{
+		ID:        account,
+		Bank:      bank,
+		Type:      accountType,
+		KYCTier:   kycTier,
+		Status:    accountStatusActive,
+		CreatedAt: txTimestamp.GetSeconds(),
+		Custodian: bank,
+
+		MerchantCategory: merchantCategory,
+	}, nil
+}
+
+// getAccount reads an account record from the world state, returning nil if the account is not registered
+func getAccount(ctx contractapi.TransactionContextInterface, account string) (*Account, error) {
+
+	accountKey, err := ctx.GetStub().CreateCompositeKey(accountPrefix, []string{account})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", accountPrefix, err)
+	}
+
+	accountBytes, err := ctx.GetStub().GetState(accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account %s from world state: %v", account, err)
+	}
+	if accountBytes == nil {
+		return nil, nil
+	}
+
+	var acc Account
+	err = json.Unmarshal(accountBytes, &acc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse account %s: %v", account, err)
+	}
+
+	return &acc, nil
+}
+
+// putAccount writes an account record to the world state
+func putAccount(ctx contractapi.TransactionContextInterface, acc *Account) error {
+
+	accountKey, err := ctx.GetStub().CreateCompositeKey(accountPrefix, []string{acc.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", accountPrefix, err)
+	}
+
+	accountJSON, err := json.Marshal(acc)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(accountKey, accountJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", accountKey, err)
+	}
+
+	return nil
+}
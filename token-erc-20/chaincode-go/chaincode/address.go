@@ -0,0 +1,101 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Retail accounts are identified by checksummed addresses such as hdfc1<40 hex digits><8 hex digits>:
+// the address prefix of the bank in the registry, the separator "1", a 20 byte id and the first 4 bytes of the SHA-256 of what precedes it
+// The id of a self-custodial account is the start of the SHA-256 of its public key, other ids are random
+// Other ids, such as hdfc.cbdc, are the legacy free-text ids of system accounts and of accounts registered
+// before addresses were introduced, they are only accepted for accounts in the registry
+const addressSeparator = "1"
+const addressIDLength = 20
+const addressChecksumLength = 4
+
+// addressFromPublicKey derives the address of a self-custodial account of a bank from the base64 DER public key of its holder
+func addressFromPublicKey(ctx contractapi.TransactionContextInterface, bank string, publicKey string) (string, error) {
+
+	der, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("%w: public key must be base64 encoded: %v", ErrInvalidArgument, err)
+	}
+
+	prefix, err := getAddressPrefix(ctx, bank)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256(der)
+	return withChecksum(prefix + addressSeparator + hex.EncodeToString(digest[:addressIDLength])), nil
+}
+
+// checkAddress checks the format and checksum of an address of a bank
+func checkAddress(ctx contractapi.TransactionContextInterface, bank string, address string) error {
+
+	prefix, err := getAddressPrefix(ctx, bank)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(address, prefix+addressSeparator) {
+		return fmt.Errorf("%s is not an address of %s", address, bank)
+	}
+
+	return checkAddressChecksum(prefix, address)
+}
+
+// checkAccountID accepts the registered accounts, whatever the format of their id, and the addresses with a valid checksum
+// Legacy free-text ids such as hdfc.cbdc or the ids of accounts registered before addresses were introduced are only
+// accepted once in the registry, so the registry catches a mistyped one
+func checkAccountID(ctx contractapi.TransactionContextInterface, account string) error {
+
+	acc, err := getAccount(ctx, account)
+	if err != nil {
+		return err
+	}
+	if acc != nil {
+		return nil
+	}
+
+	banks, err := getBanks(ctx)
+	if err != nil {
+		return err
+	}
+	for _, b := range banks {
+		if strings.HasPrefix(account, b.AddressPrefix+addressSeparator) {
+			return checkAddressChecksum(b.AddressPrefix, account)
+		}
+	}
+
+	return fmt.Errorf("%w: %s is not a valid account address or a registered legacy id", ErrInvalidArgument, account)
+}
+
+// checkAddressChecksum checks the length, digits and checksum of an address with the given prefix
+func checkAddressChecksum(prefix string, address string) error {
+
+	digits := strings.TrimPrefix(address, prefix+addressSeparator)
+	if len(digits) != 2*(addressIDLength+addressChecksumLength) {
+		return fmt.Errorf("%w: %s is not a valid account address", ErrInvalidArgument, address)
+	}
+	if _, err := hex.DecodeString(digits); err != nil || strings.ToLower(digits) != digits {
+		return fmt.Errorf("%w: %s is not a valid account address", ErrInvalidArgument, address)
+	}
+
+	if withChecksum(address[:len(address)-2*addressChecksumLength]) != address {
+		return fmt.Errorf("%w: the checksum of address %s does not match, check it for typos", ErrInvalidArgument, address)
+	}
+
+	return nil
+}
+
+// withChecksum appends the checksum to the prefix, separator and id of an address
+func withChecksum(address string) string {
+	digest := sha256.Sum256([]byte(address))
+	return address + hex.EncodeToString(digest[:addressChecksumLength])
+}
@@ -0,0 +1,67 @@
+package chaincode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithChecksum(t *testing.T) {
+	address := withChecksum("hdfc1" + strings.Repeat("ab", addressIDLength))
+
+	require.Len(t, address, len("hdfc1")+2*(addressIDLength+addressChecksumLength))
+	require.Equal(t, "hdfc1"+strings.Repeat("ab", addressIDLength)+"9f02ede7", address)
+}
+
+func TestCheckAddressChecksum(t *testing.T) {
+	valid := withChecksum("hdfc1" + strings.Repeat("ab", addressIDLength))
+	body := valid[:len(valid)-2*addressChecksumLength]
+
+	tests := []struct {
+		name    string
+		address string
+		wantErr string
+	}{
+		{
+			name:    "valid address",
+			address: valid,
+		},
+		{
+			name:    "mistyped id",
+			address: "hdfc1ac" + valid[len("hdfc1ac"):],
+			wantErr: "does not match",
+		},
+		{
+			name:    "changed checksum",
+			address: body + "00000000",
+			wantErr: "does not match",
+		},
+		{
+			name:    "uppercase digits",
+			address: "hdfc1" + strings.ToUpper(valid[len("hdfc1"):]),
+			wantErr: "is not a valid account address",
+		},
+		{
+			name:    "missing checksum",
+			address: body,
+			wantErr: "is not a valid account address",
+		},
+		{
+			name:    "non-hex digits",
+			address: "hdfc1" + strings.Repeat("zz", addressIDLength+addressChecksumLength),
+			wantErr: "is not a valid account address",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAddressChecksum("hdfc", tt.address)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
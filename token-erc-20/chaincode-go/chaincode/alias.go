@@ -0,0 +1,267 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Define objectType names for aliases
+const aliasPrefix = "alias"
+const accountAliasPrefix = "accountalias"
+
+// Aliases are handles such as priya@hdfc: a lowercase handle, "@" and the address prefix of the bank owning the alias
+var aliasHandlePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// Alias is a human readable handle resolving to an account
+type Alias struct {
+	Alias       string `json:"alias"`
+	Account     string `json:"account"`
+	Bank        string `json:"bank"`
+	DisplayName string `json:"displayName,omitempty"`
+	CreatedAt   int64  `json:"createdAt"`
+}
+
+// RegisterAlias registers an alias with the suffix of the calling bank for an account of that bank
+// param {String} displayName The name shown for the account, e.g. in transaction histories
+func (s *SmartContract) RegisterAlias(ctx contractapi.TransactionContextInterface, alias string, account string, displayName string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	err = checkAliasOwner(ctx, clientMSPID, alias)
+	if err != nil {
+		return err
+	}
+
+	acc, err := getAccount(ctx, account)
+	if err != nil {
+		return err
+	}
+	if acc == nil || acc.Bank != clientMSPID {
+		return fmt.Errorf("%s is not an account of %s", account, clientMSPID)
+	}
+	if acc.Status == accountStatusClosed {
+		return fmt.Errorf("the account %s is closed", account)
+	}
+
+	existing, err := getAlias(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("%w: the alias %s is already registered", ErrAlreadyExists, alias)
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	a := &Alias{
+		Alias:       alias,
+		Account:     account,
+		Bank:        clientMSPID,
+		DisplayName: displayName,
+		CreatedAt:   txTimestamp.GetSeconds(),
+	}
+	aliasJSON, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	aliasKey, err := ctx.GetStub().CreateCompositeKey(aliasPrefix, []string{alias})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", aliasPrefix, err)
+	}
+	err = ctx.GetStub().PutState(aliasKey, aliasJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", aliasKey, err)
+	}
+
+	// Index the alias under its account for reverse lookups
+	accountAliasKey, err := ctx.GetStub().CreateCompositeKey(accountAliasPrefix, []string{account, alias})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", accountAliasPrefix, err)
+	}
+	err = ctx.GetStub().PutState(accountAliasKey, aliasJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", accountAliasKey, err)
+	}
+
+	log.Printf("alias %s registered for %s by %s", alias, account, clientMSPID)
+
+	return nil
+}
+
+// ResolveAlias returns the alias record, with the account it resolves to
+func (s *SmartContract) ResolveAlias(ctx contractapi.TransactionContextInterface, alias string) (*Alias, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	a, err := getAlias(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, fmt.Errorf("%w: the alias %s is not registered", ErrNotFound, alias)
+	}
+
+	return a, nil
+}
+
+// DeleteAlias deletes an alias, only the bank owning its suffix can delete it
+func (s *SmartContract) DeleteAlias(ctx contractapi.TransactionContextInterface, alias string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	err = checkAliasOwner(ctx, clientMSPID, alias)
+	if err != nil {
+		return err
+	}
+
+	a, err := getAlias(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if a == nil {
+		return fmt.Errorf("%w: the alias %s is not registered", ErrNotFound, alias)
+	}
+
+	aliasKey, err := ctx.GetStub().CreateCompositeKey(aliasPrefix, []string{alias})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", aliasPrefix, err)
+	}
+	err = ctx.GetStub().DelState(aliasKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete state of smart contract for key %s: %v", aliasKey, err)
+	}
+
+	accountAliasKey, err := ctx.GetStub().CreateCompositeKey(accountAliasPrefix, []string{a.Account, alias})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", accountAliasPrefix, err)
+	}
+	err = ctx.GetStub().DelState(accountAliasKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete state of smart contract for key %s: %v", accountAliasKey, err)
+	}
+
+	log.Printf("alias %s of %s deleted by %s", alias, a.Account, clientMSPID)
+
+	return nil
+}
+
+// GetAliases returns the aliases of an account, ordered by alias
+func (s *SmartContract) GetAliases(ctx contractapi.TransactionContextInterface, account string) ([]*Alias, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(accountAliasPrefix, []string{account})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read aliases of %s from world state: %v", account, err)
+	}
+	defer resultsIterator.Close()
+
+	aliases := []*Alias{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read alias of %s: %v", account, err)
+		}
+
+		var a Alias
+		err = json.Unmarshal(queryResponse.Value, &a)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse alias %s: %v", queryResponse.Key, err)
+		}
+		aliases = append(aliases, &a)
+	}
+
+	return aliases, nil
+}
+
+// checkAliasOwner checks the format of an alias and that its suffix belongs to the bank
+func checkAliasOwner(ctx contractapi.TransactionContextInterface, bank string, alias string) error {
+
+	b, err := getBank(ctx, bank)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		return fmt.Errorf("%w: client with id %s is not authorized to manage aliases", ErrUnauthorized, bank)
+	}
+
+	handle, suffix, ok := strings.Cut(alias, "@")
+	if !ok || !aliasHandlePattern.MatchString(handle) {
+		return fmt.Errorf("%w: alias %s must be a lowercase handle followed by @ and the bank suffix", ErrInvalidArgument, alias)
+	}
+	if suffix != b.AddressPrefix {
+		return fmt.Errorf("%w: client with id %s is not authorized to manage aliases ending in @%s", ErrUnauthorized, bank, suffix)
+	}
+
+	return nil
+}
+
+// getAlias reads an alias, returning nil if the alias is not registered
+func getAlias(ctx contractapi.TransactionContextInterface, alias string) (*Alias, error) {
+
+	aliasKey, err := ctx.GetStub().CreateCompositeKey(aliasPrefix, []string{alias})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", aliasPrefix, err)
+	}
+
+	aliasBytes, err := ctx.GetStub().GetState(aliasKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alias %s from world state: %v", alias, err)
+	}
+	if aliasBytes == nil {
+		return nil, nil
+	}
+
+	var a Alias
+	err = json.Unmarshal(aliasBytes, &a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse alias %s: %v", alias, err)
+	}
+
+	return &a, nil
+}
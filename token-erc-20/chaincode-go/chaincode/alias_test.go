@@ -0,0 +1,112 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterAlias(t *testing.T) {
+	n := newNetwork(t)
+	account := n.openAccount(hdfcMSP, 1, 0, "", 0)
+	other := n.openAccount(axisMSP, 2, 0, "", 0)
+
+	tests := []struct {
+		name    string
+		msp     string
+		alias   string
+		account string
+		wantErr string
+	}{
+		{
+			name:    "uppercase handle",
+			msp:     hdfcMSP,
+			alias:   "Priya@hdfc",
+			account: account,
+			wantErr: "must be a lowercase handle followed by @ and the bank suffix",
+		},
+		{
+			name:    "missing suffix",
+			msp:     hdfcMSP,
+			alias:   "priya",
+			account: account,
+			wantErr: "must be a lowercase handle followed by @ and the bank suffix",
+		},
+		{
+			name:    "suffix of another bank",
+			msp:     hdfcMSP,
+			alias:   "priya@axis",
+			account: account,
+			wantErr: "not authorized to manage aliases ending in @axis",
+		},
+		{
+			name:    "account of another bank",
+			msp:     hdfcMSP,
+			alias:   "priya@hdfc",
+			account: other,
+			wantErr: "is not an account of " + hdfcMSP,
+		},
+		{
+			name:    "not a bank",
+			msp:     centralBankMSP,
+			alias:   "priya@hdfc",
+			account: account,
+			wantErr: "not authorized to manage aliases",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := n.submit(tt.msp, func() error { return n.contract.RegisterAlias(n.ctx, tt.alias, tt.account, "") })
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAliasLifecycle(t *testing.T) {
+	n := newNetwork(t)
+	account := n.openAccount(hdfcMSP, 1, 0, "", 0)
+	other := n.openAccount(hdfcMSP, 2, 0, "", 0)
+
+	n.ok(hdfcMSP, func() error { return n.contract.RegisterAlias(n.ctx, "priya@hdfc", account, "Priya") })
+
+	alias, err := n.contract.ResolveAlias(n.ctx, "priya@hdfc")
+	require.NoError(t, err)
+	require.Equal(t, account, alias.Account)
+	require.Equal(t, hdfcMSP, alias.Bank)
+	require.Equal(t, "Priya", alias.DisplayName)
+	aliases, err := n.contract.GetAliases(n.ctx, account)
+	require.NoError(t, err)
+	require.Len(t, aliases, 1)
+
+	// An alias resolves to one account until it is deleted
+	err = n.submit(hdfcMSP, func() error { return n.contract.RegisterAlias(n.ctx, "priya@hdfc", other, "") })
+	require.ErrorContains(t, err, "the alias priya@hdfc is already registered")
+	require.ErrorIs(t, err, chaincode.ErrAlreadyExists)
+
+	// Only the bank owning the suffix deletes the alias
+	err = n.submit(axisMSP, func() error { return n.contract.DeleteAlias(n.ctx, "priya@hdfc") })
+	require.ErrorContains(t, err, "not authorized to manage aliases ending in @hdfc")
+	n.ok(hdfcMSP, func() error { return n.contract.DeleteAlias(n.ctx, "priya@hdfc") })
+
+	_, err = n.contract.ResolveAlias(n.ctx, "priya@hdfc")
+	require.ErrorContains(t, err, "the alias priya@hdfc is not registered")
+	require.ErrorIs(t, err, chaincode.ErrNotFound)
+	aliases, err = n.contract.GetAliases(n.ctx, account)
+	require.NoError(t, err)
+	require.Empty(t, aliases)
+	err = n.submit(hdfcMSP, func() error { return n.contract.DeleteAlias(n.ctx, "priya@hdfc") })
+	require.ErrorContains(t, err, "the alias priya@hdfc is not registered")
+
+	// A deleted alias can be registered again, for another account
+	n.ok(hdfcMSP, func() error { return n.contract.RegisterAlias(n.ctx, "priya@hdfc", other, "") })
+	alias, err = n.contract.ResolveAlias(n.ctx, "priya@hdfc")
+	require.NoError(t, err)
+	require.Equal(t, other, alias.Account)
+
+	// A closed account gets no new aliases
+	n.ok(hdfcMSP, func() error { return n.contract.CloseAccount(n.ctx, account) })
+	err = n.submit(hdfcMSP, func() error { return n.contract.RegisterAlias(n.ctx, "priya.s@hdfc", account, "") })
+	require.ErrorContains(t, err, "is closed")
+}
@@ -0,0 +1,286 @@
+package chaincode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// balanceSchema is the current schema of balance records
+const balanceSchema = 1
+
+// Define objectType names for the schema versions the ledger has been migrated to
+const migrationPrefix = "migration"
+const balanceMigration = "balances"
+
+// BalanceRecord is the persisted form of an account balance and of the total supply
+type BalanceRecord struct {
+	// Schema of the record, bumped whenever the record layout changes
+	Schema int `json:"schema"`
+
+	// Amount in minor units as a base 10 big integer
+	Amount string `json:"amount"`
+
+	// Id of the transaction that last updated the record
+	LastTxID string `json:"lastTxId"`
+
+	// Number of updates made to the record
+	Version uint64 `json:"version"`
+}
+
+// MigrateBalances rewrites the plain integer balances stored under keys in the range [startKey, endKey) as balance records
+// Empty keys leave the range unbounded, records already migrated are skipped
+// A migration of the unbounded range records the schema on the ledger, so it only has to run once, see IsBalanceMigrationPending
+// Returns the number of migrated keys
+func (s *SmartContract) MigrateBalances(ctx contractapi.TransactionContextInterface, startKey string, endKey string) (int, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return 0, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	// Check central banker authorization
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != CentralBankerMSPId {
+		return 0, fmt.Errorf("%w: client with id %s is not authorized to migrate balances", ErrUnauthorized, clientMSPID)
+	}
+
+	// Balances and the total supply are the only simple keys besides the contract options,
+	// which are skipped along with the balances already migrated
+	resultsIterator, err := ctx.GetStub().GetStateByRange(startKey, endKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read balances from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	migrated := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read balance: %v", err)
+		}
+
+		key := queryResponse.Key
+		if key == nameKey || key == symbolKey || key == decimalsKey || isBalanceRecord(queryResponse.Value) {
+			continue
+		}
+
+		amount, ok := new(big.Int).SetString(string(queryResponse.Value), 10)
+		if !ok || amount.Sign() < 0 {
+			return 0, fmt.Errorf("the balance stored under %s is corrupted: %q", key, queryResponse.Value)
+		}
+
+		err = writeBalance(ctx, key, amount, nil)
+		if err != nil {
+			return 0, err
+		}
+		migrated++
+
+		log.Printf("balance of %s migrated to schema %d", key, balanceSchema)
+	}
+
+	if startKey == "" && endKey == "" {
+		err = putMigratedSchema(ctx, balanceMigration, balanceSchema)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return migrated, nil
+}
+
+// IsBalanceMigrationPending reports whether the balances on the ledger may still need MigrateBalances
+// to reach the current schema of balance records
+func (s *SmartContract) IsBalanceMigrationPending(ctx contractapi.TransactionContextInterface) (bool, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return false, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	schema, err := getMigratedSchema(ctx, balanceMigration)
+	if err != nil {
+		return false, err
+	}
+
+	return schema < balanceSchema, nil
+}
+
+// getMigratedSchema reads the schema version a kind of record has been migrated to, 0 if it never was
+func getMigratedSchema(ctx contractapi.TransactionContextInterface, migration string) (int, error) {
+
+	migrationKey, err := ctx.GetStub().CreateCompositeKey(migrationPrefix, []string{migration})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create the composite key for prefix %s: %v", migrationPrefix, err)
+	}
+
+	schemaBytes, err := ctx.GetStub().GetState(migrationKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrated schema of %s from world state: %v", migration, err)
+	}
+	if schemaBytes == nil {
+		return 0, nil
+	}
+
+	schema, _ := strconv.Atoi(string(schemaBytes)) // Error handling not needed since Itoa() was used when setting the schema, guaranteeing it was an integer.
+
+	return schema, nil
+}
+
+// putMigratedSchema records the schema version a kind of record has been migrated to
+func putMigratedSchema(ctx contractapi.TransactionContextInterface, migration string, schema int) error {
+
+	migrationKey, err := ctx.GetStub().CreateCompositeKey(migrationPrefix, []string{migration})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", migrationPrefix, err)
+	}
+
+	err = ctx.GetStub().PutState(migrationKey, []byte(strconv.Itoa(schema)))
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", migrationKey, err)
+	}
+
+	log.Printf("%s migrated to schema %d", migration, schema)
+
+	return nil
+}
+
+// readBalance reads the balance stored under key, returning a nil record if there is none
+// A balance that cannot be parsed is reported as an error instead of being treated as zero
+func readBalance(ctx contractapi.TransactionContextInterface, key string) (*big.Int, *BalanceRecord, error) {
+
+	balanceBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read balance of %s from world state: %v", key, err)
+	}
+	if balanceBytes == nil {
+		return new(big.Int), nil, nil
+	}
+
+	if !isBalanceRecord(balanceBytes) {
+		return nil, nil, fmt.Errorf("the balance of %s is stored in a legacy format, call MigrateBalances() to migrate it", key)
+	}
+
+	var record BalanceRecord
+	decoder := json.NewDecoder(bytes.NewReader(balanceBytes))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&record)
+	if err != nil {
+		return nil, nil, fmt.Errorf("the balance record of %s is corrupted: %v", key, err)
+	}
+	if record.Schema != balanceSchema {
+		return nil, nil, fmt.Errorf("the balance record of %s has unsupported schema %d", key, record.Schema)
+	}
+
+	amount, ok := new(big.Int).SetString(record.Amount, 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, nil, fmt.Errorf("the balance record of %s is corrupted: invalid amount %q", key, record.Amount)
+	}
+
+	return amount, &record, nil
+}
+
+// writeBalance stores amount under key as the next version of the previous record, if any
+func writeBalance(ctx contractapi.TransactionContextInterface, key string, amount *big.Int, previous *BalanceRecord) error {
+
+	if amount.Sign() < 0 {
+		return fmt.Errorf("the balance of %s cannot become negative", key)
+	}
+
+	record := BalanceRecord{
+		Schema:   balanceSchema,
+		Amount:   amount.String(),
+		LastTxID: ctx.GetStub().GetTxID(),
+		Version:  1,
+	}
+	if previous != nil {
+		record.Version = previous.Version + 1
+	}
+
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(key, recordJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", key, err)
+	}
+
+	return nil
+}
+
+// addBalanceDelta adds a signed change to the pending change of the balance stored under key
+func addBalanceDelta(deltas map[string]*big.Int, key string, delta int64) {
+	if deltas[key] == nil {
+		deltas[key] = new(big.Int)
+	}
+	deltas[key].Add(deltas[key], big.NewInt(delta))
+}
+
+// applyBalanceDeltas writes the pending changes of several balances, each balance once and in key order
+// A transaction does not read its own writes, so functions moving funds between many accounts sum the changes first
+func applyBalanceDeltas(ctx contractapi.TransactionContextInterface, deltas map[string]*big.Int) error {
+
+	keys := make([]string, 0, len(deltas))
+	for key := range deltas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		balance, record, err := readBalance(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		err = writeBalance(ctx, key, new(big.Int).Add(balance, deltas[key]), record)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isBalanceRecord tells balance records apart from legacy plain integer balances
+func isBalanceRecord(value []byte) bool {
+	return len(value) > 0 && value[0] == '{'
+}
+
+// addBig adds an amount to a balance
+func addBig(b *big.Int, q int) (*big.Int, error) {
+	if q < 0 {
+		return nil, fmt.Errorf("Error: the addition number is %d, it should not be negative", q)
+	}
+	return new(big.Int).Add(b, big.NewInt(int64(q))), nil
+}
+
+// subBig subtracts an amount from a balance, failing if the balance is not enough
+func subBig(b *big.Int, q int) (*big.Int, error) {
+	if q < 0 {
+		return nil, fmt.Errorf("Error: the subtraction number is %d, it should not be negative", q)
+	}
+	diff := new(big.Int).Sub(b, big.NewInt(int64(q)))
+	if diff.Sign() < 0 {
+		return nil, fmt.Errorf("Error: the number %s is not enough to be subtracted by %d", b, q)
+	}
+	return diff, nil
+}
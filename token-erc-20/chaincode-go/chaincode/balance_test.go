@@ -0,0 +1,114 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+// legacyNetwork returns a network whose ledger predates balance records, with plain integer balances for
+// an account and the total supply and no migrated schema
+func legacyNetwork(t *testing.T) (*network, string, string) {
+	n := newNetwork(t)
+	account := testAddress("hdfc", 1)
+	migrationKey, err := n.stub.CreateCompositeKey("migration", []string{"balances"})
+	require.NoError(t, err)
+
+	n.stub.State[account] = []byte("750")
+	n.stub.State["totalSupply"] = []byte("2000750")
+	delete(n.stub.State, migrationKey)
+	return n, account, migrationKey
+}
+
+// balanceRecord reads the balance record stored under key
+func balanceRecord(t *testing.T, n *network, key string) chaincode.BalanceRecord {
+	var record chaincode.BalanceRecord
+	require.NoError(t, json.Unmarshal(n.stub.State[key], &record))
+	return record
+}
+
+func TestMigrateBalances(t *testing.T) {
+	n, account, migrationKey := legacyNetwork(t)
+	reserve := balanceRecord(t, n, hdfcReserve)
+
+	_, err := n.contract.BalanceOf(n.ctx, account)
+	require.ErrorContains(t, err, "stored in a legacy format")
+	pending, err := n.contract.IsBalanceMigrationPending(n.ctx)
+	require.NoError(t, err)
+	require.True(t, pending)
+
+	// Only the legacy balances are rewritten, balance records and the transfer records of the mints are skipped
+	var migrated int
+	n.ok(centralBankMSP, func() error {
+		migrated, err = n.contract.MigrateBalances(n.ctx, "", "")
+		return err
+	})
+	require.Equal(t, 2, migrated)
+	require.Equal(t, chaincode.BalanceRecord{Schema: 1, Amount: "750", LastTxID: n.stub.TxID, Version: 1}, balanceRecord(t, n, account))
+	require.Equal(t, chaincode.BalanceRecord{Schema: 1, Amount: "2000750", LastTxID: n.stub.TxID, Version: 1}, balanceRecord(t, n, "totalSupply"))
+	require.Equal(t, reserve, balanceRecord(t, n, hdfcReserve))
+	require.Equal(t, 750, n.balance(account))
+	total, err := n.contract.TotalSupply(n.ctx)
+	require.NoError(t, err)
+	require.Equal(t, "2000750", total)
+
+	// The migration of the unbounded range records the schema
+	require.Equal(t, "1", string(n.stub.State[migrationKey]))
+	pending, err = n.contract.IsBalanceMigrationPending(n.ctx)
+	require.NoError(t, err)
+	require.False(t, pending)
+
+	// Running it again leaves the ledger as it is
+	state := make(map[string]string)
+	for key, value := range n.stub.State {
+		state[key] = string(value)
+	}
+	n.ok(centralBankMSP, func() error {
+		migrated, err = n.contract.MigrateBalances(n.ctx, "", "")
+		return err
+	})
+	require.Equal(t, 0, migrated)
+	for key, value := range n.stub.State {
+		require.Equal(t, state[key], string(value), key)
+	}
+	require.Len(t, n.stub.State, len(state))
+}
+
+func TestMigrateBalancesRange(t *testing.T) {
+	n, account, migrationKey := legacyNetwork(t)
+
+	// A bounded range migrates the legacy balances within it but does not record the schema
+	var migrated int
+	var err error
+	n.ok(centralBankMSP, func() error {
+		migrated, err = n.contract.MigrateBalances(n.ctx, account, account+"\x00")
+		return err
+	})
+	require.Equal(t, 1, migrated)
+	require.Equal(t, 750, n.balance(account))
+	require.Equal(t, "2000750", string(n.stub.State["totalSupply"]))
+	require.NotContains(t, n.stub.State, migrationKey)
+	pending, err := n.contract.IsBalanceMigrationPending(n.ctx)
+	require.NoError(t, err)
+	require.True(t, pending)
+}
+
+func TestMigrateBalancesRejects(t *testing.T) {
+	n, account, _ := legacyNetwork(t)
+
+	err := n.submit(hdfcMSP, func() error {
+		_, err := n.contract.MigrateBalances(n.ctx, "", "")
+		return err
+	})
+	require.ErrorContains(t, err, "not authorized to migrate balances")
+
+	n.stub.State[account] = []byte("-5")
+	err = n.submit(centralBankMSP, func() error {
+		_, err := n.contract.MigrateBalances(n.ctx, "", "")
+		return err
+	})
+	require.ErrorContains(t, err, "the balance stored under "+account+" is corrupted")
+	require.Equal(t, "2000750", string(n.stub.State["totalSupply"]))
+}
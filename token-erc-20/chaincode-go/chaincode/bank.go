@@ -0,0 +1,217 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Define objectType names for the bank registry
+const bankPrefix = "bank"
+
+// Address prefixes are lowercase letters only, so the separator "1" always ends the prefix of an address
+var addressPrefixPattern = regexp.MustCompile(`^[a-z]{1,16}$`)
+
+// Bank is the registry record of a commercial bank of the network
+// The registry is the single list of the banks, onboarding a bank only takes a RegisterBank by the central bank
+type Bank struct {
+	MSPID string `json:"mspId"`
+
+	// Prefix of the addresses of the accounts of the bank and suffix of its aliases
+	AddressPrefix string `json:"addressPrefix"`
+
+	// Account through which the bank receives CBDC from the central bank
+	ReserveAccount string `json:"reserveAccount"`
+}
+
+// RegisterBank adds a commercial bank to the registry and registers its reserve account
+// A reserve account registered before the registry existed is kept if it belongs to the bank
+// param {String} bank The MSP of the bank
+// param {String} addressPrefix The lowercase prefix of the addresses of the accounts of the bank, unique to the bank
+func (s *SmartContract) RegisterBank(ctx contractapi.TransactionContextInterface, bank string, addressPrefix string, reserveAccount string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	// Check central banker authorization
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != CentralBankerMSPId {
+		return fmt.Errorf("%w: client with id %s is not authorized to register banks", ErrUnauthorized, clientMSPID)
+	}
+
+	if bank == "" || bank == CentralBankerMSPId {
+		return fmt.Errorf("%q cannot be registered as a commercial bank", bank)
+	}
+	if !addressPrefixPattern.MatchString(addressPrefix) {
+		return fmt.Errorf("%w: address prefix %q must be 1 to 16 lowercase letters", ErrInvalidArgument, addressPrefix)
+	}
+
+	banks, err := getBanks(ctx)
+	if err != nil {
+		return err
+	}
+	for _, b := range banks {
+		if b.MSPID == bank {
+			return fmt.Errorf("%w: the bank %s is already registered", ErrAlreadyExists, bank)
+		}
+		if b.AddressPrefix == addressPrefix {
+			return fmt.Errorf("the address prefix %s is already used by %s", addressPrefix, b.MSPID)
+		}
+	}
+
+	err = putBank(ctx, &Bank{MSPID: bank, AddressPrefix: addressPrefix, ReserveAccount: reserveAccount})
+	if err != nil {
+		return err
+	}
+
+	acc, err := getAccount(ctx, reserveAccount)
+	if err != nil {
+		return err
+	}
+	if acc == nil {
+		err = registerAccount(ctx, reserveAccount, bank, accountTypeReserve, 0, "")
+		if err != nil {
+			return err
+		}
+	} else if acc.Type != accountTypeReserve || acc.Bank != bank {
+		return fmt.Errorf("%w: the account %s is already registered and is not a reserve account of %s", ErrAlreadyExists, reserveAccount, bank)
+	}
+
+	log.Printf("bank %s registered with address prefix %s and reserve account %s", bank, addressPrefix, reserveAccount)
+
+	return nil
+}
+
+// GetBanks returns the commercial banks of the registry, ordered by MSP
+func (s *SmartContract) GetBanks(ctx contractapi.TransactionContextInterface) ([]*Bank, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	return getBanks(ctx)
+}
+
+// getBanks reads the bank registry from the world state, ordered by MSP
+func getBanks(ctx contractapi.TransactionContextInterface) ([]*Bank, error) {
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(bankPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the bank registry from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	banks := []*Bank{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bank: %v", err)
+		}
+
+		var b Bank
+		err = json.Unmarshal(queryResponse.Value, &b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse bank %s: %v", queryResponse.Key, err)
+		}
+		banks = append(banks, &b)
+	}
+
+	return banks, nil
+}
+
+// getBank reads the registry record of a bank from the world state, returning nil if the bank is not registered
+func getBank(ctx contractapi.TransactionContextInterface, bank string) (*Bank, error) {
+
+	bankKey, err := ctx.GetStub().CreateCompositeKey(bankPrefix, []string{bank})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", bankPrefix, err)
+	}
+
+	bankBytes, err := ctx.GetStub().GetState(bankKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bank %s from world state: %v", bank, err)
+	}
+	if bankBytes == nil {
+		return nil, nil
+	}
+
+	var b Bank
+	err = json.Unmarshal(bankBytes, &b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bank %s: %v", bank, err)
+	}
+
+	return &b, nil
+}
+
+// putBank writes the registry record of a bank to the world state
+func putBank(ctx contractapi.TransactionContextInterface, b *Bank) error {
+
+	bankKey, err := ctx.GetStub().CreateCompositeKey(bankPrefix, []string{b.MSPID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", bankPrefix, err)
+	}
+
+	bankJSON, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(bankKey, bankJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", bankKey, err)
+	}
+
+	return nil
+}
+
+// isCommercialBank checks whether an MSP is a bank of the registry
+func isCommercialBank(ctx contractapi.TransactionContextInterface, bank string) (bool, error) {
+	b, err := getBank(ctx, bank)
+	if err != nil {
+		return false, err
+	}
+	return b != nil, nil
+}
+
+// getCommercialBankMSPIds lists the MSPs of the banks of the registry in order
+func getCommercialBankMSPIds(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	banks, err := getBanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mspIDs := make([]string, 0, len(banks))
+	for _, b := range banks {
+		mspIDs = append(mspIDs, b.MSPID)
+	}
+	return mspIDs, nil
+}
+
+// getAddressPrefix returns the prefix of the account addresses of a bank of the registry
+func getAddressPrefix(ctx contractapi.TransactionContextInterface, bank string) (string, error) {
+	b, err := getBank(ctx, bank)
+	if err != nil {
+		return "", err
+	}
+	if b == nil {
+		return "", fmt.Errorf("%s has no address prefix", bank)
+	}
+	return b.AddressPrefix, nil
+}
@@ -0,0 +1,198 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// TransferLeg is a single payment of a batch transfer
+type TransferLeg struct {
+	To     string `json:"to"`
+	Amount int    `json:"amount"`
+}
+
+// batchEvent provides an organized struct for emitting TransferBatch events
+type batchEvent struct {
+	Transfers []event `json:"transfers"`
+}
+
+// TransferBatch transfers amounts from the "from" account to several recipients atomically, e.g. for payroll
+// The caller must be the bank holding the custody mandate of the "from" account or have an allowance covering the total
+// Each recipient can appear once per batch, the total is checked against the funds and limits of the "from" account as a single debit
+// paymentRef is an optional client supplied reference, a batch reusing the reference of an earlier transfer is rejected
+// Fabric keeps a single event per transaction, so one TransferBatch event lists the transfers of all legs
+func (s *SmartContract) TransferBatch(ctx contractapi.TransactionContextInterface, from string, legs []TransferLeg, paymentRef string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	if len(legs) == 0 {
+		return fmt.Errorf("a batch needs at least one leg")
+	}
+
+	// Validate the legs before moving any funds
+	total := 0
+	recipients := make(map[string]*Account, len(legs))
+	for i, leg := range legs {
+		if leg.Amount <= 0 {
+			return fmt.Errorf("%w: leg %d: transfer amount must be a positive integer", ErrInvalidArgument, i)
+		}
+		if leg.To == from {
+			return fmt.Errorf("leg %d: cannot transfer to and from same client account", i)
+		}
+		if _, ok := recipients[leg.To]; ok {
+			return fmt.Errorf("%w: leg %d: recipient account %s appears more than once", ErrInvalidArgument, i, leg.To)
+		}
+		err = checkAccountID(ctx, leg.To)
+		if err != nil {
+			return fmt.Errorf("leg %d: %v", i, err)
+		}
+
+		toAccount, err := getAccount(ctx, leg.To)
+		if err != nil {
+			return err
+		}
+		if toAccount == nil {
+			return fmt.Errorf("%w: leg %d: recipient account %s is not registered", ErrNotFound, i, leg.To)
+		}
+		err = checkCreditAllowed(toAccount)
+		if err != nil {
+			return fmt.Errorf("leg %d: %v", i, err)
+		}
+		recipients[leg.To] = toAccount
+
+		total, err = add(total, leg.Amount)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = authorizeDebit(ctx, from, total)
+	if err != nil {
+		return err
+	}
+
+	err = claimPaymentReference(ctx, from, paymentRef)
+	if err != nil {
+		return err
+	}
+
+	// Debit the total once, the world state does not reflect the writes of the transaction to its own reads
+	fromAccount, err := getAccount(ctx, from)
+	if err != nil {
+		return err
+	}
+
+	fromCurrentBalance, fromRecord, err := readBalance(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed to read client account %s: %v", from, err)
+	}
+	if fromRecord == nil {
+		return fmt.Errorf("client account %s has no balance", from)
+	}
+	if fromCurrentBalance.Cmp(big.NewInt(int64(total))) < 0 {
+		return fmt.Errorf("client account %s has insufficient funds for a batch of %d", from, total)
+	}
+
+	// Court and regulatory orders take precedence over the account holder's instructions
+	err = checkDebitAllowed(fromAccount, fromCurrentBalance, total)
+	if err != nil {
+		return err
+	}
+
+	// Batches are only paid from free funds
+	err = spendPurposeBound(ctx, from, nil, fromCurrentBalance, total)
+	if err != nil {
+		return err
+	}
+
+	err = spendExpiringLots(ctx, from, fromCurrentBalance, total)
+	if err != nil {
+		return err
+	}
+
+	err = enforceOutflowLimits(ctx, fromAccount, total)
+	if err != nil {
+		return err
+	}
+
+	fromUpdatedBalance, err := subBig(fromCurrentBalance, total)
+	if err != nil {
+		return err
+	}
+	err = writeBalance(ctx, from, fromUpdatedBalance, fromRecord)
+	if err != nil {
+		return err
+	}
+
+	// Credit each recipient
+	batchTransfers := batchEvent{Transfers: make([]event, 0, len(legs))}
+	flows := make(map[[2]string]int)
+	for i, leg := range legs {
+		toCurrentBalance, toRecord, err := readBalance(ctx, leg.To)
+		if err != nil {
+			return fmt.Errorf("failed to read recipient account %s: %v", leg.To, err)
+		}
+
+		toUpdatedBalance, err := addBig(toCurrentBalance, leg.Amount)
+		if err != nil {
+			return err
+		}
+
+		err = enforceHoldingLimit(ctx, recipients[leg.To], toUpdatedBalance)
+		if err != nil {
+			return fmt.Errorf("leg %d: %w", i, err)
+		}
+
+		err = writeBalance(ctx, leg.To, toUpdatedBalance, toRecord)
+		if err != nil {
+			return err
+		}
+
+		// Record the transfer in the history of the accounts
+		err = recordTransfer(ctx, from, leg.To, leg.Amount)
+		if err != nil {
+			return err
+		}
+
+		batchTransfers.Transfers = append(batchTransfers.Transfers, event{from, leg.To, leg.Amount})
+
+		if fromAccount != nil {
+			pair := [2]string{fromAccount.Bank, recipients[leg.To].Bank}
+			flows[pair], err = add(flows[pair], leg.Amount)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Track the obligations between the banks of the accounts
+	err = addInterbankFlows(ctx, flows)
+	if err != nil {
+		return err
+	}
+
+	// Emit the TransferBatch event
+	batchEventJSON, err := json.Marshal(batchTransfers)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().SetEvent("TransferBatch", batchEventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	log.Printf("client %s balance updated from %d to %d by a batch of %d transfers", from, fromCurrentBalance, fromUpdatedBalance, len(legs))
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferBatch(t *testing.T) {
+	n := newNetwork(t)
+	employer := n.openAccount(hdfcMSP, 1, 0, "", 10000)
+	alice := n.openAccount(hdfcMSP, 2, 0, "", 0)
+	bob := n.openAccount(axisMSP, 3, 0, "", 0)
+
+	legs := []chaincode.TransferLeg{{To: alice, Amount: 3000}, {To: bob, Amount: 4000}}
+	n.ok(hdfcMSP, func() error { return n.contract.TransferBatch(n.ctx, employer, legs, "payroll-2024-01") })
+
+	require.Equal(t, 3000, n.balance(employer))
+	require.Equal(t, 3000, n.balance(alice))
+	require.Equal(t, 4000, n.balance(bob))
+	require.Equal(t, "TransferBatch", n.stub.EventName)
+	require.JSONEq(t, `{"transfers":[
+		{"from":"`+employer+`","to":"`+alice+`","value":3000},
+		{"from":"`+employer+`","to":"`+bob+`","value":4000}
+	]}`, string(n.stub.EventPayload))
+
+	err := n.submit(hdfcMSP, func() error { return n.contract.TransferBatch(n.ctx, employer, legs[:1], "payroll-2024-01") })
+	require.ErrorContains(t, err, "was already used")
+}
+
+func TestTransferBatchIsAtomic(t *testing.T) {
+	tests := []struct {
+		name    string
+		legs    func(alice, bob string) []chaincode.TransferLeg
+		wantErr string
+	}{
+		{
+			name: "unregistered recipient",
+			legs: func(alice, bob string) []chaincode.TransferLeg {
+				return []chaincode.TransferLeg{{To: alice, Amount: 1000}, {To: testAddress("axis", 9), Amount: 1000}}
+			},
+			wantErr: "leg 1: recipient account " + testAddress("axis", 9) + " is not registered",
+		},
+		{
+			name: "recipient listed twice",
+			legs: func(alice, bob string) []chaincode.TransferLeg {
+				return []chaincode.TransferLeg{{To: alice, Amount: 1000}, {To: alice, Amount: 1000}}
+			},
+			wantErr: "leg 1: recipient account " + testAddress("hdfc", 2) + " appears more than once",
+		},
+		{
+			name: "total above the funds",
+			legs: func(alice, bob string) []chaincode.TransferLeg {
+				return []chaincode.TransferLeg{{To: alice, Amount: 6000}, {To: bob, Amount: 4001}}
+			},
+			wantErr: "insufficient funds for a batch of 10001",
+		},
+		{
+			name: "holding limit of a later recipient",
+			legs: func(alice, bob string) []chaincode.TransferLeg {
+				return []chaincode.TransferLeg{{To: alice, Amount: 1000}, {To: bob, Amount: 5000}}
+			},
+			wantErr: "leg 1: " + chaincode.ErrMaxBalanceExceeded.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNetwork(t)
+			n.ok(centralBankMSP, func() error { return n.contract.SetTier(n.ctx, 1, 5000, 5000, 10000, 50000) })
+			employer := n.openAccount(hdfcMSP, 1, 0, "", 10000)
+			alice := n.openAccount(hdfcMSP, 2, 0, "", 0)
+			bob := n.openAccount(axisMSP, 3, 1, "", 1000)
+
+			err := n.submit(hdfcMSP, func() error { return n.contract.TransferBatch(n.ctx, employer, tt.legs(alice, bob), "") })
+			require.ErrorContains(t, err, tt.wantErr)
+
+			// No leg of a failed batch is paid
+			require.Equal(t, 10000, n.balance(employer))
+			require.Equal(t, 0, n.balance(alice))
+			require.Equal(t, 1000, n.balance(bob))
+		})
+	}
+}
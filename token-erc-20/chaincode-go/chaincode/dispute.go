@@ -0,0 +1,563 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Define objectType names for disputes
+const disputePrefix = "dispute"
+const disputedTransferPrefix = "disputedtransfer"
+const claimPrefix = "claim"
+
+// Define dispute statuses
+const disputeStatusOpen = "OPEN"
+const disputeStatusApproved = "APPROVED"
+const disputeStatusRejected = "REJECTED"
+const disputeStatusReversed = "REVERSED"
+
+// Dispute is a claim by the payer's bank that a transfer was fraudulent or erroneous
+type Dispute struct {
+	ID       string `json:"id"`
+	TxID     string `json:"txId"`
+	Payer    string `json:"payer"`
+	Payee    string `json:"payee"`
+	Amount   int    `json:"amount"`
+	Reason   string `json:"reason"`
+	Status   string `json:"status"`
+	RaisedBy string `json:"raisedBy"`
+	RaisedAt int64  `json:"raisedAt"`
+
+	// Decision of the central bank
+	Resolution string `json:"resolution,omitempty"`
+	ResolvedAt int64  `json:"resolvedAt,omitempty"`
+
+	// Id of the transaction that moved the disputed amount back to the payer
+	ReversalTxID string `json:"reversalTxId,omitempty"`
+
+	// Amount moved back to the payer, and the part of the disputed amount the payee could not cover
+	Recovered int `json:"recovered"`
+	Shortfall int `json:"shortfall"`
+}
+
+// Claim is the part of a reversed amount still owed by the payee
+type Claim struct {
+	DisputeID string `json:"disputeId"`
+	Account   string `json:"account"`
+	Creditor  string `json:"creditor"`
+	Amount    int    `json:"amount"`
+}
+
+// RaiseDispute disputes the amount of a transfer from the payer to the payee made by the transaction txID
+// Only the bank holding the custody mandate of the payer account can raise the dispute
+// Returns the id of the dispute
+func (s *SmartContract) RaiseDispute(ctx contractapi.TransactionContextInterface, txID string, payer string, payee string, amount int, reason string) (string, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return "", fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get MSPID: %v", err)
+	}
+
+	payerAccount, err := getAccount(ctx, payer)
+	if err != nil {
+		return "", err
+	}
+	if payerAccount == nil || payerAccount.custodian() != clientMSPID {
+		return "", fmt.Errorf("%w: client with id %s is not authorized to dispute transfers of %s", ErrUnauthorized, clientMSPID, payer)
+	}
+
+	record, err := getTransferRecord(ctx, txID, payer, payee)
+	if err != nil {
+		return "", err
+	}
+	if record == nil {
+		return "", fmt.Errorf("transaction %s did not transfer funds from %s to %s", txID, payer, payee)
+	}
+	if amount <= 0 || amount > record.Value {
+		return "", fmt.Errorf("%w: disputed amount must be positive and at most the transferred %d", ErrInvalidArgument, record.Value)
+	}
+
+	// A transfer can only be disputed once
+	disputedKey, err := ctx.GetStub().CreateCompositeKey(disputedTransferPrefix, []string{txID, payer, payee})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the composite key for prefix %s: %v", disputedTransferPrefix, err)
+	}
+	existing, err := ctx.GetStub().GetState(disputedKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read disputes of transaction %s from world state: %v", txID, err)
+	}
+	if existing != nil {
+		return "", fmt.Errorf("%w: the transfer was already disputed by dispute %s", ErrAlreadyExists, string(existing))
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return "", fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	dispute := &Dispute{
+		ID:       ctx.GetStub().GetTxID(),
+		TxID:     txID,
+		Payer:    payer,
+		Payee:    payee,
+		Amount:   amount,
+		Reason:   reason,
+		Status:   disputeStatusOpen,
+		RaisedBy: clientMSPID,
+		RaisedAt: txTimestamp.GetSeconds(),
+	}
+	err = putDispute(ctx, dispute)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().PutState(disputedKey, []byte(dispute.ID))
+	if err != nil {
+		return "", fmt.Errorf("failed to update state of smart contract for key %s: %v", disputedKey, err)
+	}
+
+	log.Printf("dispute %s raised by %s over %d of transaction %s: %s", dispute.ID, clientMSPID, amount, txID, reason)
+
+	return dispute.ID, nil
+}
+
+// ResolveDispute approves or rejects an open dispute
+// An approved dispute can then be settled with Reverse
+func (s *SmartContract) ResolveDispute(ctx contractapi.TransactionContextInterface, id string, approve bool, resolution string) error {
+
+	dispute, err := getDisputeForCentralBank(ctx, id)
+	if err != nil {
+		return err
+	}
+	if dispute.Status != disputeStatusOpen {
+		return fmt.Errorf("the dispute %s is not open", id)
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	dispute.Status = disputeStatusRejected
+	if approve {
+		dispute.Status = disputeStatusApproved
+	}
+	dispute.Resolution = resolution
+	dispute.ResolvedAt = txTimestamp.GetSeconds()
+
+	err = putDispute(ctx, dispute)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("dispute %s %s: %s", id, dispute.Status, resolution)
+
+	return nil
+}
+
+// Reverse moves the amount of an approved dispute back from the payee to the payer
+// By default the reversal is a transfer from the payee limited to its free funds: freezes, liens, wallet limits,
+// purpose-bound funds and expired lots all apply as they do to any transfer
+// With override the central bank takes up to the whole balance of the payee regardless of freezes, liens and limits,
+// and the purpose-bound balances and lots of the payee are reduced to fit what remains
+// If the payee cannot cover the amount, the rest is recorded as a claim against the payee
+// This function triggers a Transfer event
+func (s *SmartContract) Reverse(ctx contractapi.TransactionContextInterface, id string, override bool) error {
+
+	dispute, err := getDisputeForCentralBank(ctx, id)
+	if err != nil {
+		return err
+	}
+	if dispute.Status != disputeStatusApproved {
+		return fmt.Errorf("the dispute %s is not approved", id)
+	}
+
+	payeeBalance, _, err := readBalance(ctx, dispute.Payee)
+	if err != nil {
+		return err
+	}
+
+	available := new(big.Int).Set(payeeBalance)
+	if !override {
+		available, err = freeBalance(ctx, dispute.Payee, payeeBalance)
+		if err != nil {
+			return err
+		}
+	}
+
+	recovered := dispute.Amount
+	if available.Cmp(big.NewInt(int64(recovered))) < 0 {
+		recovered = int(available.Int64())
+	}
+
+	if recovered > 0 {
+		if override {
+			err = forceReverse(ctx, dispute, payeeBalance, recovered)
+		} else {
+			err = transferHelper(ctx, dispute.Payee, dispute.Payer, recovered)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to reverse: %v", err)
+		}
+
+		// Record the transfer in the history of the accounts
+		err = recordTransfer(ctx, dispute.Payee, dispute.Payer, recovered)
+		if err != nil {
+			return err
+		}
+
+		// Emit the Transfer event
+		transferEvent := event{dispute.Payee, dispute.Payer, recovered}
+		transferEventJSON, err := json.Marshal(transferEvent)
+		if err != nil {
+			return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+		}
+		err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
+		if err != nil {
+			return fmt.Errorf("failed to set event: %v", err)
+		}
+	}
+
+	dispute.Status = disputeStatusReversed
+	dispute.ReversalTxID = ctx.GetStub().GetTxID()
+	dispute.Recovered = recovered
+	dispute.Shortfall = dispute.Amount - recovered
+
+	if dispute.Shortfall > 0 {
+		claimKey, err := ctx.GetStub().CreateCompositeKey(claimPrefix, []string{dispute.Payee, dispute.ID})
+		if err != nil {
+			return fmt.Errorf("failed to create the composite key for prefix %s: %v", claimPrefix, err)
+		}
+
+		claimJSON, err := json.Marshal(Claim{dispute.ID, dispute.Payee, dispute.Payer, dispute.Shortfall})
+		if err != nil {
+			return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+		}
+
+		err = ctx.GetStub().PutState(claimKey, claimJSON)
+		if err != nil {
+			return fmt.Errorf("failed to update state of smart contract for key %s: %v", claimKey, err)
+		}
+	}
+
+	err = putDispute(ctx, dispute)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("dispute %s reversed %d of transaction %s from %s to %s, shortfall %d, override %t", id, recovered, dispute.TxID, dispute.Payee, dispute.Payer, dispute.Shortfall, override)
+
+	return nil
+}
+
+// GetDispute returns a dispute
+func (s *SmartContract) GetDispute(ctx contractapi.TransactionContextInterface, id string) (*Dispute, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	return getDispute(ctx, id)
+}
+
+// GetClaims returns the shortfall claims against an account
+func (s *SmartContract) GetClaims(ctx contractapi.TransactionContextInterface, account string) ([]*Claim, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(claimPrefix, []string{account})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read claims against %s from world state: %v", account, err)
+	}
+	defer resultsIterator.Close()
+
+	claims := []*Claim{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read claim against %s: %v", account, err)
+		}
+
+		var claim Claim
+		err = json.Unmarshal(queryResponse.Value, &claim)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse claim %s: %v", queryResponse.Key, err)
+		}
+		claims = append(claims, &claim)
+	}
+
+	return claims, nil
+}
+
+// getDisputeForCentralBank checks that the client is the central bank and reads a dispute
+func getDisputeForCentralBank(ctx contractapi.TransactionContextInterface, id string) (*Dispute, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	// Check central banker authorization
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != CentralBankerMSPId {
+		return nil, fmt.Errorf("%w: client with id %s is not authorized to settle disputes", ErrUnauthorized, clientMSPID)
+	}
+
+	return getDispute(ctx, id)
+}
+
+// getDispute reads a dispute from the world state
+func getDispute(ctx contractapi.TransactionContextInterface, id string) (*Dispute, error) {
+
+	disputeKey, err := ctx.GetStub().CreateCompositeKey(disputePrefix, []string{id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", disputePrefix, err)
+	}
+
+	disputeBytes, err := ctx.GetStub().GetState(disputeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dispute %s from world state: %v", id, err)
+	}
+	if disputeBytes == nil {
+		return nil, fmt.Errorf("%w: the dispute %s does not exist", ErrNotFound, id)
+	}
+
+	var dispute Dispute
+	err = json.Unmarshal(disputeBytes, &dispute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dispute %s: %v", id, err)
+	}
+
+	return &dispute, nil
+}
+
+// putDispute writes a dispute to the world state
+func putDispute(ctx contractapi.TransactionContextInterface, dispute *Dispute) error {
+
+	disputeKey, err := ctx.GetStub().CreateCompositeKey(disputePrefix, []string{dispute.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", disputePrefix, err)
+	}
+
+	disputeJSON, err := json.Marshal(dispute)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(disputeKey, disputeJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", disputeKey, err)
+	}
+
+	return nil
+}
+
+// freeBalance returns the part of balance an account can spend on its own: the balance less its lien,
+// its purpose-bound funds and its expired lots
+func freeBalance(ctx contractapi.TransactionContextInterface, account string, balance *big.Int) (*big.Int, error) {
+
+	acc, err := getAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	free := new(big.Int).Set(balance)
+	if acc != nil {
+		free.Sub(free, big.NewInt(int64(acc.Lien)))
+	}
+
+	buckets, err := readPurposeBuckets(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	for _, bucket := range buckets {
+		free.Sub(free, bucket.amount)
+	}
+
+	lots, _, err := readLots(ctx, []string{account})
+	if err != nil {
+		return nil, err
+	}
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	for _, lot := range lots {
+		if lot.Expiry <= txTimestamp.GetSeconds() {
+			free.Sub(free, big.NewInt(int64(lot.Amount)))
+		}
+	}
+
+	if free.Sign() < 0 {
+		free.SetInt64(0)
+	}
+
+	return free, nil
+}
+
+// forceReverse moves value from the payee to the payer of a dispute regardless of freezes, liens and wallet limits
+func forceReverse(ctx contractapi.TransactionContextInterface, dispute *Dispute, payeeBalance *big.Int, value int) error {
+
+	err := forceSpend(ctx, dispute.Payee, payeeBalance, value)
+	if err != nil {
+		return err
+	}
+
+	_, payeeRecord, err := readBalance(ctx, dispute.Payee)
+	if err != nil {
+		return err
+	}
+	payeeUpdatedBalance, err := subBig(payeeBalance, value)
+	if err != nil {
+		return err
+	}
+
+	payerBalance, payerRecord, err := readBalance(ctx, dispute.Payer)
+	if err != nil {
+		return err
+	}
+	payerUpdatedBalance, err := addBig(payerBalance, value)
+	if err != nil {
+		return err
+	}
+
+	// Track the obligation between the banks of the accounts
+	payeeAccount, err := getAccount(ctx, dispute.Payee)
+	if err != nil {
+		return err
+	}
+	payerAccount, err := getAccount(ctx, dispute.Payer)
+	if err != nil {
+		return err
+	}
+	err = addInterbankFlow(ctx, payeeAccount, payerAccount, value)
+	if err != nil {
+		return err
+	}
+
+	err = writeBalance(ctx, dispute.Payee, payeeUpdatedBalance, payeeRecord)
+	if err != nil {
+		return err
+	}
+	err = writeBalance(ctx, dispute.Payer, payerUpdatedBalance, payerRecord)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("dispute %s forced the reversal of %d from %s", dispute.ID, value, dispute.Payee)
+
+	return nil
+}
+
+// forceSpend settles a forced debit of value from an account holding balance against its lots and purpose-bound funds
+// Funds that are neither in a lot nor bound to a purpose are taken first, then lots soonest-expiring first,
+// then purpose-bound funds, so the lots and purpose-bound balances never exceed the balance left
+func forceSpend(ctx contractapi.TransactionContextInterface, account string, balance *big.Int, value int) error {
+
+	buckets, err := readPurposeBuckets(ctx, account)
+	if err != nil {
+		return err
+	}
+	lots, keys, err := readLots(ctx, []string{account})
+	if err != nil {
+		return err
+	}
+
+	unencumbered := new(big.Int).Set(balance)
+	for _, bucket := range buckets {
+		unencumbered.Sub(unencumbered, bucket.amount)
+	}
+	for _, lot := range lots {
+		unencumbered.Sub(unencumbered, big.NewInt(int64(lot.Amount)))
+	}
+
+	remaining := value
+	if unencumbered.Sign() > 0 {
+		remaining -= int(min(unencumbered.Int64(), int64(remaining)))
+	}
+
+	for i, lot := range lots {
+		if remaining == 0 {
+			break
+		}
+
+		spent := min(remaining, lot.Amount)
+		remaining -= spent
+		lot.Amount -= spent
+
+		if lot.Amount == 0 {
+			err = ctx.GetStub().DelState(keys[i])
+			if err != nil {
+				return fmt.Errorf("failed to delete state of smart contract for key %s: %v", keys[i], err)
+			}
+		} else {
+			err = putLot(ctx, keys[i], lot)
+			if err != nil {
+				return err
+			}
+		}
+
+		log.Printf("account %s forced to spend %d of lot %s", account, spent, lot.ID)
+	}
+
+	for _, bucket := range buckets {
+		if remaining == 0 {
+			break
+		}
+
+		spent := remaining
+		if bucket.amount.Cmp(big.NewInt(int64(spent))) < 0 {
+			spent = int(bucket.amount.Int64())
+		}
+		remaining -= spent
+
+		updatedAmount := new(big.Int).Sub(bucket.amount, big.NewInt(int64(spent)))
+		if updatedAmount.Sign() == 0 {
+			err = ctx.GetStub().DelState(bucket.key)
+			if err != nil {
+				return fmt.Errorf("failed to delete state of smart contract for key %s: %v", bucket.key, err)
+			}
+		} else {
+			err = writeBalance(ctx, bucket.key, updatedAmount, bucket.record)
+			if err != nil {
+				return err
+			}
+		}
+
+		log.Printf("account %s forced to spend %d bound to purpose %s", account, spent, bucket.purpose)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverse(t *testing.T) {
+	tests := []struct {
+		name          string
+		afterPayment  func(n *network, payee string, other string)
+		override      bool
+		wantErr       string
+		wantRecovered int
+		wantShortfall int
+	}{
+		{
+			name:          "payee holds the amount",
+			wantRecovered: 2000,
+		},
+		{
+			name: "payee spent part of the amount",
+			afterPayment: func(n *network, payee string, other string) {
+				n.transfer(axisMSP, payee, other, 1500)
+			},
+			wantRecovered: 500,
+			wantShortfall: 1500,
+		},
+		{
+			name: "lien is not recovered",
+			afterPayment: func(n *network, payee string, other string) {
+				n.ok(centralBankMSP, func() error { return n.contract.PlaceLien(n.ctx, payee, 1200, "tax demand") })
+			},
+			wantRecovered: 800,
+			wantShortfall: 1200,
+		},
+		{
+			name: "frozen payee",
+			afterPayment: func(n *network, payee string, other string) {
+				n.ok(centralBankMSP, func() error { return n.contract.FreezeAccount(n.ctx, payee, false, "fraud") })
+			},
+			wantErr: "failed to reverse",
+		},
+		{
+			name: "frozen payee with override",
+			afterPayment: func(n *network, payee string, other string) {
+				n.ok(centralBankMSP, func() error { return n.contract.FreezeAccount(n.ctx, payee, false, "fraud") })
+			},
+			override:      true,
+			wantRecovered: 2000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNetwork(t)
+			payer := n.openAccount(hdfcMSP, 1, 0, "", 5000)
+			payee := n.openAccount(axisMSP, 2, 0, "", 0)
+			other := n.openAccount(axisMSP, 3, 0, "", 0)
+
+			n.transfer(hdfcMSP, payer, payee, 2000)
+			txID := n.stub.TxID
+			if tt.afterPayment != nil {
+				tt.afterPayment(n, payee, other)
+			}
+
+			var id string
+			n.ok(hdfcMSP, func() (err error) {
+				id, err = n.contract.RaiseDispute(n.ctx, txID, payer, payee, 2000, "goods not delivered")
+				return err
+			})
+			n.ok(centralBankMSP, func() error { return n.contract.ResolveDispute(n.ctx, id, true, "refund the payer") })
+
+			payeeBalance := n.balance(payee)
+			err := n.submit(centralBankMSP, func() error { return n.contract.Reverse(n.ctx, id, tt.override) })
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				require.Equal(t, 3000, n.balance(payer))
+				require.Equal(t, payeeBalance, n.balance(payee))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 3000+tt.wantRecovered, n.balance(payer))
+			require.Equal(t, payeeBalance-tt.wantRecovered, n.balance(payee))
+
+			n.stub.NextTx(0)
+			n.ctx.ClientIdentity.MSPID = centralBankMSP
+			dispute, err := n.contract.GetDispute(n.ctx, id)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantRecovered, dispute.Recovered)
+			require.Equal(t, tt.wantShortfall, dispute.Shortfall)
+
+			claims, err := n.contract.GetClaims(n.ctx, payee)
+			require.NoError(t, err)
+			if tt.wantShortfall == 0 {
+				require.Empty(t, claims)
+				return
+			}
+			require.Equal(t, []*chaincode.Claim{{DisputeID: id, Account: payee, Creditor: payer, Amount: tt.wantShortfall}}, claims)
+		})
+	}
+}
+
+func TestRaiseDispute(t *testing.T) {
+	n := newNetwork(t)
+	payer := n.openAccount(hdfcMSP, 1, 0, "", 5000)
+	payee := n.openAccount(axisMSP, 2, 0, "", 0)
+	n.transfer(hdfcMSP, payer, payee, 2000)
+	txID := n.stub.TxID
+
+	err := n.submit(axisMSP, func() error {
+		_, err := n.contract.RaiseDispute(n.ctx, txID, payer, payee, 2000, "goods not delivered")
+		return err
+	})
+	require.ErrorContains(t, err, "not authorized to dispute transfers")
+
+	err = n.submit(hdfcMSP, func() error {
+		_, err := n.contract.RaiseDispute(n.ctx, txID, payer, payee, 2001, "goods not delivered")
+		return err
+	})
+	require.ErrorContains(t, err, "at most the transferred 2000")
+
+	err = n.submit(hdfcMSP, func() error {
+		_, err := n.contract.RaiseDispute(n.ctx, "tx1", payer, payee, 2000, "goods not delivered")
+		return err
+	})
+	require.ErrorContains(t, err, "did not transfer funds")
+
+	var id string
+	n.ok(hdfcMSP, func() (err error) {
+		id, err = n.contract.RaiseDispute(n.ctx, txID, payer, payee, 2000, "goods not delivered")
+		return err
+	})
+
+	err = n.submit(hdfcMSP, func() error {
+		_, err := n.contract.RaiseDispute(n.ctx, txID, payer, payee, 2000, "goods not delivered")
+		return err
+	})
+	require.ErrorContains(t, err, "already disputed by dispute "+id)
+
+	// Only approved disputes are reversed
+	err = n.submit(centralBankMSP, func() error { return n.contract.Reverse(n.ctx, id, false) })
+	require.ErrorContains(t, err, "is not approved")
+}
@@ -0,0 +1,13 @@
+package chaincode
+
+import "errors"
+
+// Codes of the errors returned by the chaincode, wrapped at the start of their message as "<code>: <message>"
+// Clients classify an error by its code rather than by the wording of its message
+// Errors without a code are business rules the transaction breaks, such as a frozen account or an exceeded tier limit
+var (
+	ErrUnauthorized    = errors.New("UNAUTHORIZED")
+	ErrNotFound        = errors.New("NOT_FOUND")
+	ErrAlreadyExists   = errors.New("ALREADY_EXISTS")
+	ErrInvalidArgument = errors.New("INVALID_ARGUMENT")
+)
@@ -0,0 +1,272 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"slices"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// statusEvent provides an organized struct for emitting AccountStatusChanged events
+type statusEvent struct {
+	Account string `json:"account"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Lien    int    `json:"lien"`
+}
+
+// Lien is an amount of an account balance held by an order of the central bank or of the bank owning the account
+type Lien struct {
+	Issuer string `json:"issuer"`
+	Amount int    `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+// FreezeAccount blocks an account in execution of a court or regulatory order
+// A debit freeze only blocks debits, a full freeze blocks debits and credits
+// The freeze records the MSP issuing it, a bank cannot replace a freeze issued by the central bank
+// This function triggers an AccountStatusChanged event
+func (s *SmartContract) FreezeAccount(ctx contractapi.TransactionContextInterface, account string, debitOnly bool, reason string) error {
+
+	acc, clientMSPID, err := getOrderTarget(ctx, account)
+	if err != nil {
+		return err
+	}
+	if acc.Status == accountStatusClosed {
+		return fmt.Errorf("the account %s is closed", account)
+	}
+	if isFrozen(acc) {
+		err = checkOrderIssuer(clientMSPID, acc.StatusIssuer, account)
+		if err != nil {
+			return err
+		}
+	}
+
+	if debitOnly {
+		acc.Status = accountStatusDebitFrozen
+	} else {
+		acc.Status = accountStatusFrozen
+	}
+	acc.StatusReason = reason
+	acc.StatusIssuer = clientMSPID
+
+	return updateAccountStatus(ctx, acc, reason)
+}
+
+// UnfreezeAccount lifts a freeze or debit freeze placed on an account
+// Only the central bank and the MSP that issued the freeze can lift it
+// This function triggers an AccountStatusChanged event
+func (s *SmartContract) UnfreezeAccount(ctx contractapi.TransactionContextInterface, account string) error {
+
+	acc, clientMSPID, err := getOrderTarget(ctx, account)
+	if err != nil {
+		return err
+	}
+	if !isFrozen(acc) {
+		return fmt.Errorf("the account %s is not frozen", account)
+	}
+	err = checkOrderIssuer(clientMSPID, acc.StatusIssuer, account)
+	if err != nil {
+		return err
+	}
+
+	acc.Status = accountStatusActive
+	acc.StatusReason = ""
+	acc.StatusIssuer = ""
+
+	return updateAccountStatus(ctx, acc, "unfrozen")
+}
+
+// PlaceLien marks part of an account balance as not debitable
+// Liens are cumulative, the balance remaining after any debit must cover the total lien
+// Each lien records the MSP issuing it
+// This function triggers an AccountStatusChanged event
+func (s *SmartContract) PlaceLien(ctx contractapi.TransactionContextInterface, account string, amount int, reason string) error {
+
+	acc, clientMSPID, err := getOrderTarget(ctx, account)
+	if err != nil {
+		return err
+	}
+	if acc.Status == accountStatusClosed {
+		return fmt.Errorf("the account %s is closed", account)
+	}
+
+	if amount <= 0 {
+		return fmt.Errorf("%w: lien amount must be a positive integer", ErrInvalidArgument)
+	}
+
+	acc.Lien, err = add(acc.Lien, amount)
+	if err != nil {
+		return err
+	}
+	acc.LienReason = reason
+	acc.Liens = append(acc.Liens, &Lien{clientMSPID, amount, reason})
+
+	return updateAccountStatus(ctx, acc, reason)
+}
+
+// ReleaseLien releases the given amount from the liens placed on an account, most recent lien first
+// The central bank can release any lien, a bank only the liens it issued
+// This function triggers an AccountStatusChanged event
+func (s *SmartContract) ReleaseLien(ctx contractapi.TransactionContextInterface, account string, amount int) error {
+
+	acc, clientMSPID, err := getOrderTarget(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	if amount <= 0 {
+		return fmt.Errorf("%w: released amount must be a positive integer", ErrInvalidArgument)
+	}
+
+	// Liens without a record predate the issuer tracking and count as issued by the central bank
+	releasable := 0
+	unrecorded := acc.Lien
+	for _, lien := range acc.Liens {
+		unrecorded -= lien.Amount
+		if clientMSPID == CentralBankerMSPId || clientMSPID == lien.Issuer {
+			releasable += lien.Amount
+		}
+	}
+	if clientMSPID == CentralBankerMSPId {
+		releasable += unrecorded
+	}
+	if amount > releasable {
+		return fmt.Errorf("%w: client with id %s is not authorized to release more than %d of the lien on account %s", ErrUnauthorized, clientMSPID, releasable, account)
+	}
+
+	acc.Lien, err = sub(acc.Lien, amount)
+	if err != nil {
+		return err
+	}
+
+	remaining := amount
+	for i := len(acc.Liens) - 1; i >= 0 && remaining > 0; i-- {
+		lien := acc.Liens[i]
+		if clientMSPID != CentralBankerMSPId && clientMSPID != lien.Issuer {
+			continue
+		}
+		released := min(remaining, lien.Amount)
+		lien.Amount -= released
+		remaining -= released
+		if lien.Amount == 0 {
+			acc.Liens = slices.Delete(acc.Liens, i, i+1)
+		}
+	}
+
+	acc.LienReason = ""
+	if len(acc.Liens) > 0 {
+		acc.LienReason = acc.Liens[len(acc.Liens)-1].Reason
+	}
+
+	return updateAccountStatus(ctx, acc, "lien released")
+}
+
+// getOrderTarget checks that the client may issue orders against an account and returns the account record and the client MSP
+// Orders can be issued by the central bank and by the bank owning the account
+func getOrderTarget(ctx contractapi.TransactionContextInterface, account string) (*Account, string, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, "", fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get MSPID: %v", err)
+	}
+
+	acc, err := getAccount(ctx, account)
+	if err != nil {
+		return nil, "", err
+	}
+	if acc == nil {
+		return nil, "", fmt.Errorf("%w: the account %s is not registered", ErrNotFound, account)
+	}
+	if clientMSPID != CentralBankerMSPId && clientMSPID != acc.Bank {
+		return nil, "", fmt.Errorf("%w: client with id %s is not authorized to issue orders against account %s", ErrUnauthorized, clientMSPID, account)
+	}
+
+	return acc, clientMSPID, nil
+}
+
+// checkOrderIssuer checks that the client may lift or replace an order, only the central bank and the issuer can
+// Orders without a recorded issuer predate the issuer tracking and count as issued by the central bank
+func checkOrderIssuer(clientMSPID string, issuer string, account string) error {
+	if clientMSPID == CentralBankerMSPId || clientMSPID == issuer {
+		return nil
+	}
+	if issuer == "" {
+		issuer = CentralBankerMSPId
+	}
+	return fmt.Errorf("%w: client with id %s is not authorized to lift the order of %s against account %s", ErrUnauthorized, clientMSPID, issuer, account)
+}
+
+// isFrozen reports whether a freeze or debit freeze is placed on an account
+func isFrozen(acc *Account) bool {
+	return acc.Status == accountStatusFrozen || acc.Status == accountStatusDebitFrozen
+}
+
+// updateAccountStatus stores an account changed by an order and emits the AccountStatusChanged event
+func updateAccountStatus(ctx contractapi.TransactionContextInterface, acc *Account, reason string) error {
+
+	err := putAccount(ctx, acc)
+	if err != nil {
+		return err
+	}
+
+	statusChangedEvent := statusEvent{acc.ID, acc.Status, reason, acc.Lien}
+	statusChangedEventJSON, err := json.Marshal(statusChangedEvent)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().SetEvent("AccountStatusChanged", statusChangedEventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	log.Printf("account %s status %s with lien %d: %s", acc.ID, acc.Status, acc.Lien, reason)
+
+	return nil
+}
+
+// checkDebitAllowed checks that no freeze or lien prevents debiting value from an account holding balance
+func checkDebitAllowed(acc *Account, balance *big.Int, value int) error {
+	if acc == nil {
+		return nil
+	}
+
+	switch acc.Status {
+	case accountStatusFrozen, accountStatusDebitFrozen:
+		return fmt.Errorf("account %s is frozen: %s", acc.ID, acc.StatusReason)
+	case accountStatusClosed:
+		return fmt.Errorf("account %s is closed", acc.ID)
+	}
+
+	// The balance remaining after the debit must still cover the lien
+	remaining := new(big.Int).Sub(balance, big.NewInt(int64(value)))
+	if acc.Lien > 0 && remaining.Cmp(big.NewInt(int64(acc.Lien))) < 0 {
+		return fmt.Errorf("account %s has %d under lien: %s", acc.ID, acc.Lien, acc.LienReason)
+	}
+
+	return nil
+}
+
+// checkCreditAllowed checks that an account can receive funds
+func checkCreditAllowed(acc *Account) error {
+	switch acc.Status {
+	case accountStatusFrozen:
+		return fmt.Errorf("recipient account %s is frozen: %s", acc.ID, acc.StatusReason)
+	case accountStatusClosed:
+		return fmt.Errorf("recipient account %s is closed", acc.ID)
+	}
+
+	return nil
+}
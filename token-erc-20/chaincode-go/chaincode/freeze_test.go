@@ -0,0 +1,181 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferFreezes(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   func(n *network, payer string, payee string) error
+		amount  int
+		wantErr string
+	}{
+		{
+			name: "debit freeze blocks debits",
+			order: func(n *network, payer string, payee string) error {
+				return n.contract.FreezeAccount(n.ctx, payer, true, "court order")
+			},
+			amount:  1000,
+			wantErr: "is frozen: court order",
+		},
+		{
+			name: "debit freeze lets credits in",
+			order: func(n *network, payer string, payee string) error {
+				return n.contract.FreezeAccount(n.ctx, payee, true, "court order")
+			},
+			amount: 1000,
+		},
+		{
+			name: "freeze blocks credits",
+			order: func(n *network, payer string, payee string) error {
+				return n.contract.FreezeAccount(n.ctx, payee, false, "sanctions")
+			},
+			amount:  1000,
+			wantErr: "recipient account " + testAddress("axis", 2) + " is frozen: sanctions",
+		},
+		{
+			name: "lien must stay covered",
+			order: func(n *network, payer string, payee string) error {
+				return n.contract.PlaceLien(n.ctx, payer, 25000, "tax demand")
+			},
+			amount:  5001,
+			wantErr: "has 25000 under lien: tax demand",
+		},
+		{
+			name: "debits above the lien go through",
+			order: func(n *network, payer string, payee string) error {
+				return n.contract.PlaceLien(n.ctx, payer, 25000, "tax demand")
+			},
+			amount: 5000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNetwork(t)
+			payer := n.openAccount(hdfcMSP, 1, 0, "", 30000)
+			payee := n.openAccount(axisMSP, 2, 0, "", 0)
+
+			n.ok(centralBankMSP, func() error { return tt.order(n, payer, payee) })
+
+			err := n.submit(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, payer, payee, tt.amount, "") })
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				require.Equal(t, 30000, n.balance(payer))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 30000-tt.amount, n.balance(payer))
+			require.Equal(t, tt.amount, n.balance(payee))
+		})
+	}
+}
+
+func TestLiftOrders(t *testing.T) {
+	tests := []struct {
+		name    string
+		issuer  string
+		lifter  string
+		wantErr string
+	}{
+		{
+			name:   "central bank lifts its own freeze",
+			issuer: centralBankMSP,
+			lifter: centralBankMSP,
+		},
+		{
+			name:    "bank cannot lift a central bank freeze on its customer",
+			issuer:  centralBankMSP,
+			lifter:  hdfcMSP,
+			wantErr: "not authorized to lift the order of " + centralBankMSP,
+		},
+		{
+			name:   "bank lifts its own freeze",
+			issuer: hdfcMSP,
+			lifter: hdfcMSP,
+		},
+		{
+			name:   "central bank lifts a bank freeze",
+			issuer: hdfcMSP,
+			lifter: centralBankMSP,
+		},
+		{
+			name:    "another bank issues no orders against the account",
+			issuer:  hdfcMSP,
+			lifter:  axisMSP,
+			wantErr: "not authorized to issue orders",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNetwork(t)
+			account := n.openAccount(hdfcMSP, 1, 0, "", 30000)
+
+			n.ok(tt.issuer, func() error { return n.contract.FreezeAccount(n.ctx, account, false, "court order") })
+			err := n.submit(tt.lifter, func() error { return n.contract.UnfreezeAccount(n.ctx, account) })
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				acc, err := n.contract.GetAccount(n.ctx, account)
+				require.NoError(t, err)
+				require.Equal(t, "FROZEN", acc.Status)
+				require.Equal(t, tt.issuer, acc.StatusIssuer)
+				return
+			}
+			require.NoError(t, err)
+
+			// The unfrozen account is debited again
+			n.transfer(hdfcMSP, account, hdfcReserve, 1000)
+		})
+	}
+}
+
+func TestFreezeKeepsCentralBankOrders(t *testing.T) {
+	n := newNetwork(t)
+	account := n.openAccount(hdfcMSP, 1, 0, "", 30000)
+
+	// A bank cannot take over a central bank freeze by replacing it with its own
+	n.ok(centralBankMSP, func() error { return n.contract.FreezeAccount(n.ctx, account, false, "court order") })
+	err := n.submit(hdfcMSP, func() error { return n.contract.FreezeAccount(n.ctx, account, true, "review") })
+	require.ErrorContains(t, err, "not authorized to lift the order of "+centralBankMSP)
+
+	// The central bank can replace a bank freeze, which the bank then cannot lift
+	n.ok(centralBankMSP, func() error { return n.contract.UnfreezeAccount(n.ctx, account) })
+	n.ok(hdfcMSP, func() error { return n.contract.FreezeAccount(n.ctx, account, true, "review") })
+	n.ok(centralBankMSP, func() error { return n.contract.FreezeAccount(n.ctx, account, false, "court order") })
+	err = n.submit(hdfcMSP, func() error { return n.contract.UnfreezeAccount(n.ctx, account) })
+	require.ErrorContains(t, err, "not authorized to lift the order of "+centralBankMSP)
+}
+
+func TestReleaseLien(t *testing.T) {
+	n := newNetwork(t)
+	account := n.openAccount(hdfcMSP, 1, 0, "", 30000)
+
+	n.ok(centralBankMSP, func() error { return n.contract.PlaceLien(n.ctx, account, 10000, "tax demand") })
+	n.ok(hdfcMSP, func() error { return n.contract.PlaceLien(n.ctx, account, 5000, "loan default") })
+
+	// A bank can only release the liens it placed
+	err := n.submit(hdfcMSP, func() error { return n.contract.ReleaseLien(n.ctx, account, 5001) })
+	require.ErrorContains(t, err, "not authorized to release more than 5000")
+
+	n.ok(hdfcMSP, func() error { return n.contract.ReleaseLien(n.ctx, account, 5000) })
+	acc, err := n.contract.GetAccount(n.ctx, account)
+	require.NoError(t, err)
+	require.Equal(t, 10000, acc.Lien)
+	require.Equal(t, "tax demand", acc.LienReason)
+	require.Equal(t, []*chaincode.Lien{{Issuer: centralBankMSP, Amount: 10000, Reason: "tax demand"}}, acc.Liens)
+
+	err = n.submit(hdfcMSP, func() error { return n.contract.ReleaseLien(n.ctx, account, 1) })
+	require.ErrorContains(t, err, "not authorized to release more than 0")
+
+	n.ok(centralBankMSP, func() error { return n.contract.ReleaseLien(n.ctx, account, 10000) })
+	acc, err = n.contract.GetAccount(n.ctx, account)
+	require.NoError(t, err)
+	require.Zero(t, acc.Lien)
+	require.Empty(t, acc.LienReason)
+	require.Empty(t, acc.Liens)
+}
@@ -0,0 +1,195 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Define objectType names for transfer records
+const transferRecordPrefix = "transfer"
+const transferByTxPrefix = "transfertx"
+
+// Latest Unix time whose nanoseconds fit in an int64, the record keys hold times in nanoseconds
+const maxRecordTime = math.MaxInt64 / int64(time.Second)
+
+// TransferRecord is the immutable record of a balance movement, stored once for each account involved
+type TransferRecord struct {
+	TxID      string `json:"txId"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Value     int    `json:"value"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// TransferHistory is a page of transfer records
+type TransferHistory struct {
+	Records  []*TransferRecord `json:"records"`
+	Bookmark string            `json:"bookmark"`
+}
+
+// GetTransactionHistory returns a page of the transfer records of an account in chronological order
+// Records are sorted by the time of their transaction in nanoseconds, then by transaction id and counterparty,
+// so the records a transaction writes for several counterparties are adjacent
+// The page ends early with an empty bookmark once it reaches a record past toTime
+// param {Integer} fromTime Unix time of the oldest record to return, 0 for no lower bound
+// param {Integer} toTime Unix time of the newest record to return, inclusive of that whole second, 0 for no upper bound
+// param {Integer} pageSize The number of records to read per page
+// param {String} bookmark The bookmark returned with the previous page, empty for the first page
+func (s *SmartContract) GetTransactionHistory(ctx contractapi.TransactionContextInterface, account string, fromTime int64, toTime int64, pageSize int32, bookmark string) (*TransferHistory, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("%w: page size must be a positive integer", ErrInvalidArgument)
+	}
+	if fromTime < 0 || toTime < 0 {
+		return nil, fmt.Errorf("%w: time bounds cannot be negative", ErrInvalidArgument)
+	}
+	if toTime != 0 && toTime < fromTime {
+		return nil, fmt.Errorf("toTime must not be before fromTime")
+	}
+
+	accountKey, err := ctx.GetStub().CreateCompositeKey(transferRecordPrefix, []string{account})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", transferRecordPrefix, err)
+	}
+
+	// The bookmark is the key the page starts at, so the first page starts at the key of fromTime
+	// A bookmark outside the records of the account would page through the records of other accounts
+	if bookmark == "" && fromTime > 0 {
+		bookmark, err = ctx.GetStub().CreateCompositeKey(transferRecordPrefix, []string{account, sortableTime(time.Unix(min(fromTime, maxRecordTime), 0))})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", transferRecordPrefix, err)
+		}
+	} else if bookmark != "" && !strings.HasPrefix(bookmark, accountKey) {
+		return nil, fmt.Errorf("the bookmark is not a bookmark of the history of %s", account)
+	}
+
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(transferRecordPrefix, []string{account}, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transfer records of %s from world state: %v", account, err)
+	}
+	defer resultsIterator.Close()
+
+	history := &TransferHistory{Records: []*TransferRecord{}, Bookmark: metadata.GetBookmark()}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transfer record of %s: %v", account, err)
+		}
+
+		var record TransferRecord
+		err = json.Unmarshal(queryResponse.Value, &record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse transfer record %s: %v", queryResponse.Key, err)
+		}
+
+		// Records are in chronological order, none of the following ones are within the time bounds
+		if toTime != 0 && record.Timestamp > toTime {
+			history.Bookmark = ""
+			break
+		}
+
+		history.Records = append(history.Records, &record)
+	}
+
+	return history, nil
+}
+
+// recordTransfer writes the transfer record of a balance movement for the accounts on both sides of it
+// The "0x0" account used for mint and burn does not get any records
+func recordTransfer(ctx contractapi.TransactionContextInterface, from string, to string, value int) error {
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	record := TransferRecord{
+		TxID:      ctx.GetStub().GetTxID(),
+		From:      from,
+		To:        to,
+		Value:     value,
+		Timestamp: txTimestamp.GetSeconds(),
+	}
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	recordTime := sortableTime(txTimestamp.AsTime())
+
+	// The counterparty keeps the records of an account apart when a transaction moves funds with several accounts
+	for _, pair := range [][2]string{{from, to}, {to, from}} {
+		account, counterparty := pair[0], pair[1]
+		if account == "0x0" {
+			continue
+		}
+
+		recordKey, err := ctx.GetStub().CreateCompositeKey(transferRecordPrefix, []string{account, recordTime, record.TxID, counterparty})
+		if err != nil {
+			return fmt.Errorf("failed to create the composite key for prefix %s: %v", transferRecordPrefix, err)
+		}
+
+		err = ctx.GetStub().PutState(recordKey, recordJSON)
+		if err != nil {
+			return fmt.Errorf("failed to update state of smart contract for key %s: %v", recordKey, err)
+		}
+	}
+
+	// Index the record by transaction for looking up a transfer, e.g. when it is disputed
+	txKey, err := ctx.GetStub().CreateCompositeKey(transferByTxPrefix, []string{record.TxID, from, to})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", transferByTxPrefix, err)
+	}
+
+	err = ctx.GetStub().PutState(txKey, recordJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", txKey, err)
+	}
+
+	return nil
+}
+
+// sortableTime formats a time as zero padded Unix nanoseconds, which sort in chronological order
+func sortableTime(t time.Time) string {
+	return fmt.Sprintf("%020d", t.UnixNano())
+}
+
+// getTransferRecord reads the record of a transfer between two accounts made by a transaction, returning nil if there is none
+// Transfers recorded before the records were indexed by transaction are not found
+func getTransferRecord(ctx contractapi.TransactionContextInterface, txID string, from string, to string) (*TransferRecord, error) {
+
+	txKey, err := ctx.GetStub().CreateCompositeKey(transferByTxPrefix, []string{txID, from, to})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", transferByTxPrefix, err)
+	}
+
+	recordBytes, err := ctx.GetStub().GetState(txKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transfer record of transaction %s from world state: %v", txID, err)
+	}
+	if recordBytes == nil {
+		return nil, nil
+	}
+
+	var record TransferRecord
+	err = json.Unmarshal(recordBytes, &record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transfer record of transaction %s: %v", txID, err)
+	}
+
+	return &record, nil
+}
@@ -0,0 +1,160 @@
+package chaincode_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+// historyNetwork funds an account and pays 100, 200 and 300 from it an hour apart, returning the account and the times of the payments
+func historyNetwork(t *testing.T) (*network, string, []int64) {
+	n := newNetwork(t)
+	payer := n.openAccount(hdfcMSP, 1, 0, "", 1000)
+	payee := n.openAccount(axisMSP, 2, 0, "", 0)
+
+	var times []int64
+	for _, amount := range []int{100, 200, 300} {
+		n.wait(time.Hour)
+		n.transfer(hdfcMSP, payer, payee, amount)
+		times = append(times, n.now())
+	}
+	return n, payer, times
+}
+
+// values lists the values of transfer records
+func values(records []*chaincode.TransferRecord) []int {
+	var values []int
+	for _, record := range records {
+		values = append(values, record.Value)
+	}
+	return values
+}
+
+func TestGetTransactionHistoryWindow(t *testing.T) {
+	n, payer, times := historyNetwork(t)
+	funded := times[0] - int64(time.Hour.Seconds())
+
+	tests := []struct {
+		name     string
+		fromTime int64
+		toTime   int64
+		want     []int
+	}{
+		{
+			name: "whole history",
+			want: []int{1000, 100, 200, 300},
+		},
+		{
+			name:     "from the time of a record",
+			fromTime: times[1],
+			want:     []int{200, 300},
+		},
+		{
+			name:   "to the time of a record, inclusive of that second",
+			toTime: times[1],
+			want:   []int{1000, 100, 200},
+		},
+		{
+			name:     "between records",
+			fromTime: times[0] + 1,
+			toTime:   times[2] - 1,
+			want:     []int{200},
+		},
+		{
+			name:     "window without records",
+			fromTime: funded + 1,
+			toTime:   times[0] - 1,
+		},
+		{
+			name:   "latest time",
+			toTime: math.MaxInt64,
+			want:   []int{1000, 100, 200, 300},
+		},
+		{
+			name:     "earliest time past the records",
+			fromTime: math.MaxInt64,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history, err := n.contract.GetTransactionHistory(n.ctx, payer, tt.fromTime, tt.toTime, 10, "")
+			require.NoError(t, err)
+			require.Equal(t, tt.want, values(history.Records))
+			require.Empty(t, history.Bookmark)
+		})
+	}
+}
+
+func TestGetTransactionHistoryPages(t *testing.T) {
+	n, payer, times := historyNetwork(t)
+
+	tests := []struct {
+		name     string
+		fromTime int64
+		toTime   int64
+		pageSize int32
+		want     [][]int
+	}{
+		{
+			name:     "pages of two",
+			pageSize: 2,
+			want:     [][]int{{1000, 100}, {200, 300}},
+		},
+		{
+			name:     "pages of one within a window",
+			fromTime: times[0],
+			toTime:   times[1],
+			pageSize: 1,
+			want:     [][]int{{100}, {200}, nil},
+		},
+		{
+			name:     "page larger than the history",
+			pageSize: 5,
+			want:     [][]int{{1000, 100, 200, 300}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pages [][]int
+			bookmark := ""
+			for {
+				history, err := n.contract.GetTransactionHistory(n.ctx, payer, tt.fromTime, tt.toTime, tt.pageSize, bookmark)
+				require.NoError(t, err)
+				require.LessOrEqual(t, len(history.Records), int(tt.pageSize))
+				pages = append(pages, values(history.Records))
+
+				bookmark = history.Bookmark
+				if bookmark == "" {
+					break
+				}
+			}
+			require.Equal(t, tt.want, pages)
+		})
+	}
+}
+
+func TestGetTransactionHistoryRejects(t *testing.T) {
+	n, payer, _ := historyNetwork(t)
+	other := testAddress("axis", 2)
+
+	_, err := n.contract.GetTransactionHistory(n.ctx, payer, 0, 0, 0, "")
+	require.ErrorContains(t, err, "page size must be a positive integer")
+
+	_, err = n.contract.GetTransactionHistory(n.ctx, payer, 10, 5, 10, "")
+	require.ErrorContains(t, err, "toTime must not be before fromTime")
+
+	_, err = n.contract.GetTransactionHistory(n.ctx, payer, -1, 0, 10, "")
+	require.ErrorContains(t, err, "time bounds cannot be negative")
+
+	// A bookmark of another account does not page through its records
+	history, err := n.contract.GetTransactionHistory(n.ctx, other, 0, 0, 1, "")
+	require.NoError(t, err)
+	require.NotEmpty(t, history.Bookmark)
+	_, err = n.contract.GetTransactionHistory(n.ctx, payer, 0, 0, 1, history.Bookmark)
+	require.ErrorContains(t, err, "is not a bookmark of the history of "+payer)
+}
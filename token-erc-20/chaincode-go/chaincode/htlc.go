@@ -0,0 +1,329 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Define objectType names for hash time-locked contracts
+const htlcPrefix = "htlc"
+
+// escrowAccount holds the funds locked by hash time-locked contracts, it is registered with RegisterEscrowAccount
+const escrowAccount = "escrow.cbdc"
+
+// Define hash time-locked contract statuses
+const htlcStatusLocked = "LOCKED"
+const htlcStatusClaimed = "CLAIMED"
+const htlcStatusRefunded = "REFUNDED"
+
+// HTLC is a hash time-locked conditional payment
+// The locked amount is paid to the recipient on reveal of the preimage of the hashlock before the timeout,
+// and refunded to the sender after it
+type HTLC struct {
+	ID        string `json:"id"`
+	Sender    string `json:"sender"`
+	Recipient string `json:"recipient"`
+	Amount    int    `json:"amount"`
+
+	// Hex encoded SHA-256 hash of the preimage
+	Hashlock string `json:"hashlock"`
+
+	// Unix time from which the amount can be refunded and no longer claimed
+	Timeout int64 `json:"timeout"`
+
+	Status   string `json:"status"`
+	Preimage string `json:"preimage,omitempty"`
+}
+
+// RegisterEscrowAccount registers the account holding the funds locked by hash time-locked contracts
+func (s *SmartContract) RegisterEscrowAccount(ctx contractapi.TransactionContextInterface) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	// Check central banker authorization
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != CentralBankerMSPId {
+		return fmt.Errorf("%w: client with id %s is not authorized to register the escrow account", ErrUnauthorized, clientMSPID)
+	}
+
+	return registerAccount(ctx, escrowAccount, CentralBankerMSPId, accountTypeEscrow, 0, "")
+}
+
+// CreateHTLC locks an amount of the sender account in escrow for the recipient
+// The caller must be the bank holding the custody mandate of the sender account
+// param {String} hashlock Hex encoded SHA-256 hash of the preimage releasing the payment
+// param {Integer} timeout Unix time from which the sender can get the amount refunded
+// Returns the id of the hash time-locked contract
+// This function triggers a Transfer event
+func (s *SmartContract) CreateHTLC(ctx contractapi.TransactionContextInterface, sender string, recipient string, amount int, hashlock string, timeout int64) (string, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return "", fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get MSPID: %v", err)
+	}
+
+	senderAccount, err := getAccount(ctx, sender)
+	if err != nil {
+		return "", err
+	}
+	if senderAccount == nil || senderAccount.custodian() != clientMSPID {
+		return "", fmt.Errorf("%w: client with id %s is not authorized to lock funds of %s", ErrUnauthorized, clientMSPID, sender)
+	}
+	err = checkDirectDebit(senderAccount)
+	if err != nil {
+		return "", err
+	}
+
+	// The recipient must be able to receive the payment when it is claimed
+	recipientAccount, err := getAccount(ctx, recipient)
+	if err != nil {
+		return "", err
+	}
+	if recipientAccount == nil {
+		return "", fmt.Errorf("%w: recipient account %s is not registered", ErrNotFound, recipient)
+	}
+	if sender == recipient {
+		return "", fmt.Errorf("cannot lock a payment to and from same client account")
+	}
+
+	if amount <= 0 {
+		return "", fmt.Errorf("%w: locked amount must be a positive integer", ErrInvalidArgument)
+	}
+	hashlock = strings.ToLower(hashlock)
+	if decoded, err := hex.DecodeString(hashlock); err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("%w: hashlock must be a hex encoded SHA-256 hash", ErrInvalidArgument)
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return "", fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	if timeout <= txTimestamp.GetSeconds() {
+		return "", fmt.Errorf("%w: timeout must be in the future", ErrInvalidArgument)
+	}
+
+	htlc := &HTLC{
+		ID:        ctx.GetStub().GetTxID(),
+		Sender:    sender,
+		Recipient: recipient,
+		Amount:    amount,
+		Hashlock:  hashlock,
+		Timeout:   timeout,
+		Status:    htlcStatusLocked,
+	}
+
+	err = moveHTLCFunds(ctx, htlc, sender, escrowAccount)
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("htlc %s locked %d of %s for %s until %d", htlc.ID, amount, sender, recipient, timeout)
+
+	return htlc.ID, nil
+}
+
+// ClaimHTLC pays the amount locked by a hash time-locked contract to its recipient
+// Anyone knowing the preimage of the hashlock can claim the payment before the timeout
+// This function triggers a Transfer event
+func (s *SmartContract) ClaimHTLC(ctx contractapi.TransactionContextInterface, id string, preimage string) error {
+
+	htlc, now, err := getLockedHTLC(ctx, id)
+	if err != nil {
+		return err
+	}
+	if now >= htlc.Timeout {
+		return fmt.Errorf("htlc %s timed out at %d", id, htlc.Timeout)
+	}
+
+	hash := sha256.Sum256([]byte(preimage))
+	if hex.EncodeToString(hash[:]) != htlc.Hashlock {
+		return fmt.Errorf("the preimage does not match the hashlock of htlc %s", id)
+	}
+
+	htlc.Status = htlcStatusClaimed
+	htlc.Preimage = preimage
+
+	err = moveHTLCFunds(ctx, htlc, escrowAccount, htlc.Recipient)
+	if err != nil {
+		return err
+	}
+
+	// The payment settles between the banks of the sender and the recipient, not with the escrow
+	senderAccount, err := getAccount(ctx, htlc.Sender)
+	if err != nil {
+		return err
+	}
+	recipientAccount, err := getAccount(ctx, htlc.Recipient)
+	if err != nil {
+		return err
+	}
+	err = addInterbankFlow(ctx, senderAccount, recipientAccount, htlc.Amount)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("htlc %s claimed by %s", id, htlc.Recipient)
+
+	return nil
+}
+
+// RefundHTLC returns the amount locked by a hash time-locked contract to its sender
+// Anyone can trigger the refund once the timeout has passed
+// This function triggers a Transfer event
+func (s *SmartContract) RefundHTLC(ctx contractapi.TransactionContextInterface, id string) error {
+
+	htlc, now, err := getLockedHTLC(ctx, id)
+	if err != nil {
+		return err
+	}
+	if now < htlc.Timeout {
+		return fmt.Errorf("htlc %s cannot be refunded before %d", id, htlc.Timeout)
+	}
+
+	htlc.Status = htlcStatusRefunded
+
+	err = moveHTLCFunds(ctx, htlc, escrowAccount, htlc.Sender)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("htlc %s refunded to %s", id, htlc.Sender)
+
+	return nil
+}
+
+// GetHTLC returns a hash time-locked contract
+func (s *SmartContract) GetHTLC(ctx contractapi.TransactionContextInterface, id string) (*HTLC, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	return getHTLC(ctx, id)
+}
+
+// getHTLC reads a hash time-locked contract from the world state
+func getHTLC(ctx contractapi.TransactionContextInterface, id string) (*HTLC, error) {
+
+	htlcKey, err := ctx.GetStub().CreateCompositeKey(htlcPrefix, []string{id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", htlcPrefix, err)
+	}
+
+	htlcBytes, err := ctx.GetStub().GetState(htlcKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read htlc %s from world state: %v", id, err)
+	}
+	if htlcBytes == nil {
+		return nil, fmt.Errorf("%w: the htlc %s does not exist", ErrNotFound, id)
+	}
+
+	var htlc HTLC
+	err = json.Unmarshal(htlcBytes, &htlc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse htlc %s: %v", id, err)
+	}
+
+	return &htlc, nil
+}
+
+// getLockedHTLC reads a hash time-locked contract that still locks its amount, along with the transaction time
+func getLockedHTLC(ctx contractapi.TransactionContextInterface, id string) (*HTLC, int64, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, 0, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	htlc, err := getHTLC(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if htlc.Status != htlcStatusLocked {
+		return nil, 0, fmt.Errorf("the htlc %s is already %s", id, strings.ToLower(htlc.Status))
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	return htlc, txTimestamp.GetSeconds(), nil
+}
+
+// moveHTLCFunds moves the amount of a hash time-locked contract between escrow and an account and stores the contract
+func moveHTLCFunds(ctx contractapi.TransactionContextInterface, htlc *HTLC, from string, to string) error {
+
+	err := transferHelper(ctx, from, to, htlc.Amount)
+	if err != nil {
+		return fmt.Errorf("failed to transfer: %v", err)
+	}
+
+	// Record the transfer in the history of the accounts
+	err = recordTransfer(ctx, from, to, htlc.Amount)
+	if err != nil {
+		return err
+	}
+
+	htlcKey, err := ctx.GetStub().CreateCompositeKey(htlcPrefix, []string{htlc.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", htlcPrefix, err)
+	}
+
+	htlcJSON, err := json.Marshal(htlc)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(htlcKey, htlcJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", htlcKey, err)
+	}
+
+	// Emit the Transfer event
+	transferEvent := event{from, to, htlc.Amount}
+	transferEventJSON, err := json.Marshal(transferEvent)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	return nil
+}
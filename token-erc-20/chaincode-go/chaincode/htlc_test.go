@@ -0,0 +1,98 @@
+package chaincode_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTLCTiming(t *testing.T) {
+	const preimage = "invoice-42 secret"
+
+	tests := []struct {
+		name          string
+		after         time.Duration
+		refund        bool
+		preimage      string
+		wantErr       string
+		wantSender    int
+		wantRecipient int
+	}{
+		{
+			name:          "claim before the timeout",
+			after:         30 * time.Minute,
+			preimage:      preimage,
+			wantSender:    4000,
+			wantRecipient: 1000,
+		},
+		{
+			name:     "claim with a wrong preimage",
+			after:    30 * time.Minute,
+			preimage: "guess",
+			wantErr:  "the preimage does not match the hashlock",
+		},
+		{
+			name:     "claim at the timeout",
+			after:    time.Hour,
+			preimage: preimage,
+			wantErr:  "timed out at",
+		},
+		{
+			name:    "refund before the timeout",
+			after:   30 * time.Minute,
+			refund:  true,
+			wantErr: "cannot be refunded before",
+		},
+		{
+			name:       "refund at the timeout",
+			after:      time.Hour,
+			refund:     true,
+			wantSender: 5000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNetwork(t)
+			n.ok(centralBankMSP, func() error { return n.contract.RegisterEscrowAccount(n.ctx) })
+			sender := n.openAccount(hdfcMSP, 1, 0, "", 5000)
+			recipient := n.openAccount(axisMSP, 2, 0, "", 0)
+
+			hash := sha256.Sum256([]byte(preimage))
+			timeout := n.now() + 1 + int64(time.Hour.Seconds())
+			var id string
+			n.ok(hdfcMSP, func() (err error) {
+				id, err = n.contract.CreateHTLC(n.ctx, sender, recipient, 1000, hex.EncodeToString(hash[:]), timeout)
+				return err
+			})
+			require.Equal(t, 4000, n.balance(sender))
+
+			// The next transaction runs a second after the clock is moved
+			n.wait(tt.after - time.Second)
+			err := n.submit(axisMSP, func() error {
+				if tt.refund {
+					return n.contract.RefundHTLC(n.ctx, id)
+				}
+				return n.contract.ClaimHTLC(n.ctx, id, tt.preimage)
+			})
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				require.Equal(t, 4000, n.balance(sender))
+				require.Equal(t, 0, n.balance(recipient))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantSender, n.balance(sender))
+			require.Equal(t, tt.wantRecipient, n.balance(recipient))
+
+			// A settled contract cannot be claimed or refunded again
+			err = n.submit(axisMSP, func() error { return n.contract.ClaimHTLC(n.ctx, id, preimage) })
+			require.ErrorContains(t, err, "is already")
+			err = n.submit(axisMSP, func() error { return n.contract.RefundHTLC(n.ctx, id) })
+			require.ErrorContains(t, err, "is already")
+		})
+	}
+}
@@ -0,0 +1,498 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"slices"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Define objectType names for expiring lots
+const lotPrefix = "lot"
+
+// ErrFundsExpired is returned when a transfer would spend funds of lots past their expiry
+var ErrFundsExpired = errors.New("expired funds cannot be spent")
+
+// Lot is an amount credited to an account that reverts to its issuer if it is not spent before the expiry
+// Lots minted by the central bank have the "0x0" issuer and are burnt when they expire
+type Lot struct {
+	ID      string `json:"id"`
+	Account string `json:"account"`
+	Issuer  string `json:"issuer"`
+	Amount  int    `json:"amount"`
+	Expiry  int64  `json:"expiry"`
+}
+
+// MintExpiring creates new tokens in a retail account that are burnt if they are not spent before the expiry
+// param {Integer} expiry Unix time after which the unspent amount expires
+// This function triggers a Transfer event
+func (s *SmartContract) MintExpiring(ctx contractapi.TransactionContextInterface, account string, amount int, expiry int64) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	// Check minter authorization
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != CentralBankerMSPId {
+		return fmt.Errorf("%w: client is not authorized to mint new tokens", ErrUnauthorized)
+	}
+
+	// Expiring funds are issued to beneficiaries, not to bank reserves
+	acc, err := getAccount(ctx, account)
+	if err != nil {
+		return err
+	}
+	if acc == nil || acc.Type != accountTypeRetail {
+		return fmt.Errorf("%s is not a retail account", account)
+	}
+	err = checkCreditAllowed(acc)
+	if err != nil {
+		return err
+	}
+
+	currentBalance, _, err := readBalance(ctx, account)
+	if err != nil {
+		return err
+	}
+	updatedBalance, err := addBig(currentBalance, amount)
+	if err != nil {
+		return err
+	}
+	err = enforceHoldingLimit(ctx, acc, updatedBalance)
+	if err != nil {
+		return err
+	}
+
+	err = mintHelper(ctx, account, amount)
+	if err != nil {
+		return err
+	}
+
+	return addLot(ctx, account, "0x0", amount, expiry)
+}
+
+// AllocateExpiring transfers tokens from a bank reserve account to a retail account that revert to the reserve
+// if they are not spent before the expiry
+// The caller must be the bank holding the custody mandate of the reserve account
+// param {Integer} expiry Unix time after which the unspent amount expires
+// This function triggers a Transfer event
+func (s *SmartContract) AllocateExpiring(ctx contractapi.TransactionContextInterface, from string, to string, amount int, expiry int64) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+
+	fromAccount, err := getAccount(ctx, from)
+	if err != nil {
+		return err
+	}
+	if fromAccount == nil || fromAccount.Type != accountTypeReserve {
+		return fmt.Errorf("%s is not a reserve account", from)
+	}
+	if fromAccount.custodian() != clientMSPID {
+		return fmt.Errorf("%w: client with id %s is not authorized to allocate funds from %s", ErrUnauthorized, clientMSPID, from)
+	}
+
+	if amount <= 0 {
+		return fmt.Errorf("%w: allocation amount must be a positive integer", ErrInvalidArgument)
+	}
+
+	// Initiate the transfer
+	err = transferHelper(ctx, from, to, amount)
+	if err != nil {
+		return fmt.Errorf("failed to transfer: %v", err)
+	}
+
+	// Record the transfer in the history of the accounts
+	err = recordTransfer(ctx, from, to, amount)
+	if err != nil {
+		return err
+	}
+
+	// Emit the Transfer event
+	transferEvent := event{from, to, amount}
+	transferEventJSON, err := json.Marshal(transferEvent)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	return addLot(ctx, to, from, amount, expiry)
+}
+
+// GetLots returns the expiring lots held by an account, soonest-expiring first
+func (s *SmartContract) GetLots(ctx contractapi.TransactionContextInterface, account string) ([]*Lot, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	lots, _, err := readLots(ctx, []string{account})
+	if err != nil {
+		return nil, err
+	}
+	if lots == nil {
+		lots = []*Lot{}
+	}
+
+	return lots, nil
+}
+
+// GetExpiredLots returns the lots of all accounts past their expiry, waiting to be swept
+func (s *SmartContract) GetExpiredLots(ctx contractapi.TransactionContextInterface) ([]*Lot, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	lots, _, err := readLots(ctx, []string{})
+	if err != nil {
+		return nil, err
+	}
+
+	expired := []*Lot{}
+	for _, lot := range lots {
+		if lot.Expiry <= txTimestamp.GetSeconds() {
+			expired = append(expired, lot)
+		}
+	}
+
+	return expired, nil
+}
+
+// SweepExpiredLot reverts the unspent amount of a lot past its expiry to the lot issuer
+// Amounts minted by the central bank are burnt and reduce the total supply, amounts allocated from a reserve are credited back to it
+// Lots of accounts under a freeze or lien order cannot be swept until the order is lifted
+// Fabric keeps a single event per transaction, so each lot is swept by its own transaction to emit its own Expired event
+// This function triggers an Expired event carrying the swept lot
+func (s *SmartContract) SweepExpiredLot(ctx contractapi.TransactionContextInterface, account string, id string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	// Check central banker authorization
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != CentralBankerMSPId {
+		return fmt.Errorf("%w: client with id %s is not authorized to sweep expired funds", ErrUnauthorized, clientMSPID)
+	}
+
+	lots, keys, err := readLots(ctx, []string{account})
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(lots, func(lot *Lot) bool { return lot.ID == id })
+	if i < 0 {
+		return fmt.Errorf("%w: the lot %s of %s does not exist", ErrNotFound, id, account)
+	}
+	lot := lots[i]
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	if lot.Expiry > txTimestamp.GetSeconds() {
+		return fmt.Errorf("the lot %s of %s expires at %d", id, account, lot.Expiry)
+	}
+
+	acc, err := getAccount(ctx, account)
+	if err != nil {
+		return err
+	}
+	balance, _, err := readBalance(ctx, account)
+	if err != nil {
+		return err
+	}
+	err = checkDebitAllowed(acc, balance, lot.Amount)
+	if err != nil {
+		return fmt.Errorf("lot %s cannot be swept: %v", id, err)
+	}
+
+	err = ctx.GetStub().DelState(keys[i])
+	if err != nil {
+		return fmt.Errorf("failed to delete state of smart contract for key %s: %v", keys[i], err)
+	}
+
+	deltas := make(map[string]*big.Int)
+	flows := make(map[[2]string]int)
+	err = revertExpired(ctx, account, map[string]int{lot.Issuer: lot.Amount}, deltas, flows)
+	if err != nil {
+		return err
+	}
+
+	err = applyBalanceDeltas(ctx, deltas)
+	if err != nil {
+		return err
+	}
+
+	// Track the obligations between the banks of the accounts
+	err = addInterbankFlows(ctx, flows)
+	if err != nil {
+		return err
+	}
+
+	expiredEventJSON, err := json.Marshal(lot)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().SetEvent("Expired", expiredEventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	return nil
+}
+
+// addLot records an amount credited to an account as a lot expiring at the given Unix time
+func addLot(ctx contractapi.TransactionContextInterface, account string, issuer string, amount int, expiry int64) error {
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	if expiry <= txTimestamp.GetSeconds() {
+		return fmt.Errorf("%w: expiry must be in the future", ErrInvalidArgument)
+	}
+
+	lot := Lot{
+		ID:      ctx.GetStub().GetTxID(),
+		Account: account,
+		Issuer:  issuer,
+		Amount:  amount,
+		Expiry:  expiry,
+	}
+
+	// Zero padded expiries keep the lots of an account in expiry order
+	lotKey, err := ctx.GetStub().CreateCompositeKey(lotPrefix, []string{account, fmt.Sprintf("%020d", expiry), lot.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", lotPrefix, err)
+	}
+
+	err = putLot(ctx, lotKey, &lot)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("account %s holds %d from %s expiring at %d", account, amount, issuer, expiry)
+
+	return nil
+}
+
+// readLots reads the lots under a partial key along with their keys, each account's lots soonest-expiring first
+func readLots(ctx contractapi.TransactionContextInterface, attributes []string) ([]*Lot, []string, error) {
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(lotPrefix, attributes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read lots from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	var lots []*Lot
+	var keys []string
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read lot: %v", err)
+		}
+
+		var lot Lot
+		err = json.Unmarshal(queryResponse.Value, &lot)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse lot %s: %v", queryResponse.Key, err)
+		}
+		lots = append(lots, &lot)
+		keys = append(keys, queryResponse.Key)
+	}
+
+	return lots, keys, nil
+}
+
+// putLot writes a lot to the world state
+func putLot(ctx contractapi.TransactionContextInterface, lotKey string, lot *Lot) error {
+
+	lotJSON, err := json.Marshal(lot)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(lotKey, lotJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", lotKey, err)
+	}
+
+	return nil
+}
+
+// spendExpiringLots settles a debit of value from an account holding balance against its lots
+// Funds of expired lots cannot be spent, unexpired lots are spent soonest-expiring first and before any other funds
+func spendExpiringLots(ctx contractapi.TransactionContextInterface, from string, balance *big.Int, value int) error {
+
+	lots, keys, err := readLots(ctx, []string{from})
+	if err != nil {
+		return err
+	}
+	if len(lots) == 0 {
+		return nil
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	now := txTimestamp.GetSeconds()
+
+	available := new(big.Int).Set(balance)
+	for _, lot := range lots {
+		if lot.Expiry <= now {
+			available.Sub(available, big.NewInt(int64(lot.Amount)))
+		}
+	}
+	if available.Cmp(big.NewInt(int64(value))) < 0 {
+		return fmt.Errorf("%w: account %s has %s of unexpired funds", ErrFundsExpired, from, available)
+	}
+
+	remaining := value
+	for i, lot := range lots {
+		if remaining == 0 {
+			break
+		}
+		if lot.Expiry <= now {
+			continue
+		}
+
+		spent := min(remaining, lot.Amount)
+		remaining -= spent
+		lot.Amount -= spent
+
+		if lot.Amount == 0 {
+			err = ctx.GetStub().DelState(keys[i])
+			if err != nil {
+				return fmt.Errorf("failed to delete state of smart contract for key %s: %v", keys[i], err)
+			}
+		} else {
+			err = putLot(ctx, keys[i], lot)
+			if err != nil {
+				return err
+			}
+		}
+
+		log.Printf("account %s spent %d of lot %s", from, spent, lot.ID)
+	}
+
+	return nil
+}
+
+// revertExpired adds the balance changes moving the expired amounts of an account back to their issuers to deltas,
+// and the resulting payments between banks to flows
+// Amounts of the "0x0" issuer are burnt
+func revertExpired(ctx contractapi.TransactionContextInterface, account string, byIssuer map[string]int, deltas map[string]*big.Int, flows map[[2]string]int) error {
+
+	available, _, err := readBalance(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	acc, err := getAccount(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	issuers := make([]string, 0, len(byIssuer))
+	for issuer := range byIssuer {
+		issuers = append(issuers, issuer)
+	}
+	sort.Strings(issuers)
+
+	for _, issuer := range issuers {
+		amount := byIssuer[issuer]
+
+		// Never take more than the account holds
+		if available.Cmp(big.NewInt(int64(amount))) < 0 {
+			amount = int(available.Int64())
+		}
+		if amount == 0 {
+			continue
+		}
+		available.Sub(available, big.NewInt(int64(amount)))
+
+		// Burnt amounts leave the total supply, reverted ones return to the issuing reserve
+		addBalanceDelta(deltas, account, -int64(amount))
+		if issuer == "0x0" {
+			addBalanceDelta(deltas, totalSupplyKey, -int64(amount))
+		} else {
+			addBalanceDelta(deltas, issuer, int64(amount))
+
+			issuerAccount, err := getAccount(ctx, issuer)
+			if err != nil {
+				return err
+			}
+			if acc != nil && issuerAccount != nil {
+				pair := [2]string{acc.Bank, issuerAccount.Bank}
+				flows[pair], err = add(flows[pair], amount)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// Record the transfer in the history of the accounts
+		err = recordTransfer(ctx, account, issuer, amount)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("expired %d of account %s reverted to %s", amount, account, issuer)
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiringLotSpending(t *testing.T) {
+	tests := []struct {
+		name     string
+		after    time.Duration
+		amount   int
+		wantErr  string
+		wantLots []int
+	}{
+		{
+			name:     "lots are spent soonest-expiring first",
+			amount:   1500,
+			wantLots: []int{1500},
+		},
+		{
+			name:     "free funds are spent after the lots",
+			amount:   3500,
+			wantLots: nil,
+		},
+		{
+			name:     "expired lots are not spent",
+			after:    time.Hour,
+			amount:   1500,
+			wantLots: []int{1000, 500},
+		},
+		{
+			name:    "expired funds cannot be spent",
+			after:   time.Hour,
+			amount:  3501,
+			wantErr: chaincode.ErrFundsExpired.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNetwork(t)
+			beneficiary := n.openAccount(hdfcMSP, 1, 0, "", 1500)
+			payee := n.openAccount(axisMSP, 2, 0, "", 0)
+
+			expiry := n.now() + int64(time.Hour.Seconds())
+			n.ok(centralBankMSP, func() error { return n.contract.MintExpiring(n.ctx, beneficiary, 1000, expiry) })
+			n.ok(hdfcMSP, func() error {
+				return n.contract.AllocateExpiring(n.ctx, hdfcReserve, beneficiary, 2000, expiry+int64(time.Hour.Seconds()))
+			})
+			n.wait(tt.after)
+
+			err := n.submit(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, beneficiary, payee, tt.amount, "") })
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				require.Equal(t, 4500, n.balance(beneficiary))
+				return
+			}
+			require.NoError(t, err)
+
+			lots, err := n.contract.GetLots(n.ctx, beneficiary)
+			require.NoError(t, err)
+			var amounts []int
+			for _, lot := range lots {
+				amounts = append(amounts, lot.Amount)
+			}
+			require.Equal(t, tt.wantLots, amounts)
+		})
+	}
+}
+
+func TestSweepExpiredLot(t *testing.T) {
+	tests := []struct {
+		name         string
+		issuer       string
+		wantSupply   string
+		wantReserve  int
+		sweepTooSoon bool
+	}{
+		{
+			name:        "minted lots are burnt",
+			issuer:      centralBankMSP,
+			wantSupply:  "2000000",
+			wantReserve: reserveFunds,
+		},
+		{
+			name:        "allocated lots return to the reserve",
+			issuer:      hdfcMSP,
+			wantSupply:  "2000000",
+			wantReserve: reserveFunds,
+		},
+		{
+			name:         "lots cannot be swept before their expiry",
+			issuer:       centralBankMSP,
+			sweepTooSoon: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNetwork(t)
+			beneficiary := n.openAccount(hdfcMSP, 1, 0, "", 0)
+
+			expiry := n.now() + int64(time.Hour.Seconds())
+			n.ok(tt.issuer, func() error {
+				if tt.issuer == centralBankMSP {
+					return n.contract.MintExpiring(n.ctx, beneficiary, 1000, expiry)
+				}
+				return n.contract.AllocateExpiring(n.ctx, hdfcReserve, beneficiary, 1000, expiry)
+			})
+			id := n.stub.TxID
+
+			if tt.sweepTooSoon {
+				err := n.submit(centralBankMSP, func() error { return n.contract.SweepExpiredLot(n.ctx, beneficiary, id) })
+				require.ErrorContains(t, err, "expires at")
+				return
+			}
+
+			n.wait(time.Hour)
+			n.stub.NextTx(0)
+			expired, err := n.contract.GetExpiredLots(n.ctx)
+			require.NoError(t, err)
+			require.Len(t, expired, 1)
+			require.Equal(t, id, expired[0].ID)
+
+			n.ok(centralBankMSP, func() error { return n.contract.SweepExpiredLot(n.ctx, beneficiary, id) })
+			require.Equal(t, "Expired", n.stub.EventName)
+			var event chaincode.Lot
+			require.NoError(t, json.Unmarshal(n.stub.EventPayload, &event))
+			require.Equal(t, id, event.ID)
+			require.Equal(t, 1000, event.Amount)
+
+			require.Equal(t, 0, n.balance(beneficiary))
+			require.Equal(t, tt.wantReserve, n.balance(hdfcReserve))
+			supply, err := n.contract.TotalSupply(n.ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantSupply, supply)
+
+			expired, err = n.contract.GetExpiredLots(n.ctx)
+			require.NoError(t, err)
+			require.Empty(t, expired)
+		})
+	}
+}
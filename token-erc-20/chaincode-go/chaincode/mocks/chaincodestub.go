@@ -0,0 +1,223 @@
+package mocks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ChaincodeStub is an in-memory world state for unit tests of the chaincode
+// As on a peer, the writes of a transaction go to its write set and are only visible to reads once it is committed
+// The stub methods the chaincode does not use are left to the embedded interface and panic if called
+type ChaincodeStub struct {
+	shim.ChaincodeStubInterface
+
+	// Committed world state
+	State map[string][]byte
+
+	ChannelID      string
+	TxID           string
+	TxTimestamp    time.Time
+	SignedProposal *peer.SignedProposal
+
+	// Event set by the current transaction, Fabric keeps a single event per transaction
+	EventName    string
+	EventPayload []byte
+
+	// Write set of the current transaction, a nil value deletes the key as it does on a peer
+	writes map[string][]byte
+}
+
+// NewChaincodeStub creates an empty world state with a transaction on the given channel
+func NewChaincodeStub(channelID string) *ChaincodeStub {
+	return &ChaincodeStub{
+		State:       make(map[string][]byte),
+		ChannelID:   channelID,
+		TxID:        "tx1",
+		TxTimestamp: time.Unix(1700000000, 0).UTC(),
+		writes:      make(map[string][]byte),
+	}
+}
+
+// NextTx starts a new transaction a given duration after the current one, dropping the write set and event of the current one
+func (s *ChaincodeStub) NextTx(elapsed time.Duration) {
+	var n int
+	fmt.Sscanf(s.TxID, "tx%d", &n)
+	s.TxID = fmt.Sprintf("tx%d", n+1)
+	s.TxTimestamp = s.TxTimestamp.Add(elapsed)
+	s.EventName, s.EventPayload = "", nil
+	s.writes = make(map[string][]byte)
+}
+
+// Commit applies the write set of the current transaction to the world state, as a peer does for a valid transaction
+func (s *ChaincodeStub) Commit() {
+	for key, value := range s.writes {
+		if value == nil {
+			delete(s.State, key)
+		} else {
+			s.State[key] = value
+		}
+	}
+	s.writes = make(map[string][]byte)
+}
+
+func (s *ChaincodeStub) GetTxID() string {
+	return s.TxID
+}
+
+func (s *ChaincodeStub) GetChannelID() string {
+	return s.ChannelID
+}
+
+func (s *ChaincodeStub) GetTxTimestamp() (*timestamppb.Timestamp, error) {
+	return timestamppb.New(s.TxTimestamp), nil
+}
+
+func (s *ChaincodeStub) GetSignedProposal() (*peer.SignedProposal, error) {
+	return s.SignedProposal, nil
+}
+
+func (s *ChaincodeStub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return fmt.Errorf("event name can not be empty string")
+	}
+	s.EventName, s.EventPayload = name, payload
+	return nil
+}
+
+// GetState reads the committed world state, not the writes of the current transaction
+func (s *ChaincodeStub) GetState(key string) ([]byte, error) {
+	return s.State[key], nil
+}
+
+func (s *ChaincodeStub) PutState(key string, value []byte) error {
+	if key == "" {
+		return fmt.Errorf("key must not be an empty string")
+	}
+	s.writes[key] = value
+	return nil
+}
+
+func (s *ChaincodeStub) DelState(key string) error {
+	s.writes[key] = nil
+	return nil
+}
+
+func (s *ChaincodeStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return shim.CreateCompositeKey(objectType, attributes)
+}
+
+func (s *ChaincodeStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	components := strings.Split(compositeKey, "\x00")
+	if len(components) < 3 || components[0] != "" || components[len(components)-1] != "" {
+		return "", nil, fmt.Errorf("%q is not a composite key", compositeKey)
+	}
+	return components[1], components[2 : len(components)-1], nil
+}
+
+// GetStateByRange iterates over the committed simple keys in [startKey, endKey), an empty bound leaves the range open
+// Like the peer, it rejects composite keys and never returns them
+func (s *ChaincodeStub) GetStateByRange(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, err
+	}
+	return &StateQueryIterator{results: s.keyRange(startKey, endKey, false)}, nil
+}
+
+// GetStateByRangeWithPagination returns a page of GetStateByRange, the bookmark being the first key of the next page
+func (s *ChaincodeStub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	if err := validateSimpleKeys(startKey, endKey, bookmark); err != nil {
+		return nil, nil, err
+	}
+	if bookmark != "" {
+		startKey = bookmark
+	}
+	return s.page(s.keyRange(startKey, endKey, false), pageSize)
+}
+
+func (s *ChaincodeStub) GetStateByPartialCompositeKey(objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	partialKey, err := shim.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+	return &StateQueryIterator{results: s.keyRange(partialKey, partialKey+string(utf8.MaxRune), true)}, nil
+}
+
+// GetStateByPartialCompositeKeyWithPagination returns a page of GetStateByPartialCompositeKey, the bookmark being the first key of the next page
+// As on a peer, a bookmark is the key the page starts at
+func (s *ChaincodeStub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	partialKey, err := shim.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	startKey := partialKey
+	if bookmark != "" {
+		startKey = bookmark
+	}
+	return s.page(s.keyRange(startKey, partialKey+string(utf8.MaxRune), true), pageSize)
+}
+
+// page splits the first page of pageSize entries off range query results
+func (s *ChaincodeStub) page(results []*queryresult.KV, pageSize int32) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	next := ""
+	if pageSize > 0 && len(results) > int(pageSize) {
+		next = results[pageSize].Key
+		results = results[:pageSize]
+	}
+	metadata := &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(results)), Bookmark: next}
+	return &StateQueryIterator{results: results}, metadata, nil
+}
+
+// keyRange lists the entries of the committed world state in [startKey, endKey) in key order, either composite or simple keys only
+func (s *ChaincodeStub) keyRange(startKey, endKey string, composite bool) []*queryresult.KV {
+	var results []*queryresult.KV
+	for key, value := range s.State {
+		if strings.HasPrefix(key, "\x00") != composite {
+			continue
+		}
+		if key < startKey || (endKey != "" && key >= endKey) {
+			continue
+		}
+		results = append(results, &queryresult.KV{Key: key, Value: value})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Key < results[j].Key })
+	return results
+}
+
+func validateSimpleKeys(keys ...string) error {
+	for _, key := range keys {
+		if strings.HasPrefix(key, "\x00") {
+			return fmt.Errorf("first character of the key [%s] contains a null character which is not allowed", key)
+		}
+	}
+	return nil
+}
+
+// StateQueryIterator iterates over the results of a range query of ChaincodeStub
+type StateQueryIterator struct {
+	results []*queryresult.KV
+}
+
+func (it *StateQueryIterator) HasNext() bool {
+	return len(it.results) > 0
+}
+
+func (it *StateQueryIterator) Next() (*queryresult.KV, error) {
+	if len(it.results) == 0 {
+		return nil, fmt.Errorf("no more results")
+	}
+	next := it.results[0]
+	it.results = it.results[1:]
+	return next, nil
+}
+
+func (it *StateQueryIterator) Close() error {
+	return nil
+}
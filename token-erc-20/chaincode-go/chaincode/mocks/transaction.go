@@ -0,0 +1,49 @@
+package mocks
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+)
+
+// TransactionContext hands the contract a ChaincodeStub and the identity of the calling client
+type TransactionContext struct {
+	Stub           *ChaincodeStub
+	ClientIdentity *ClientIdentity
+}
+
+func (ctx *TransactionContext) GetStub() shim.ChaincodeStubInterface {
+	return ctx.Stub
+}
+
+func (ctx *TransactionContext) GetClientIdentity() cid.ClientIdentity {
+	return ctx.ClientIdentity
+}
+
+// ClientIdentity is the identity of the client calling the contract, switch MSPID and ID to call as another client
+type ClientIdentity struct {
+	MSPID string
+	ID    string
+}
+
+func (c *ClientIdentity) GetID() (string, error) {
+	return c.ID, nil
+}
+
+func (c *ClientIdentity) GetMSPID() (string, error) {
+	return c.MSPID, nil
+}
+
+func (c *ClientIdentity) GetAttributeValue(attrName string) (string, bool, error) {
+	return "", false, nil
+}
+
+func (c *ClientIdentity) AssertAttributeValue(attrName, attrValue string) error {
+	return fmt.Errorf("attribute %s was not found", attrName)
+}
+
+func (c *ClientIdentity) GetX509Certificate() (*x509.Certificate, error) {
+	return nil, fmt.Errorf("the mock identity has no certificate")
+}
@@ -0,0 +1,664 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Define objectType names for multisig proposals and the nonces of their accounts
+const proposalPrefix = "proposal"
+const proposalNoncePrefix = "proposalnonce"
+
+// Define the actions signers sign
+const multisigActionPropose = "PROPOSE"
+const multisigActionApprove = "APPROVE"
+const multisigActionCancel = "CANCEL"
+
+// Define proposal statuses
+const proposalStatusPending = "PENDING"
+const proposalStatusExecuted = "EXECUTED"
+const proposalStatusCancelled = "CANCELLED"
+
+// Proposal is a transfer from a multisig account waiting for the approvals of its signers
+type Proposal struct {
+	ID        string   `json:"id"`
+	Account   string   `json:"account"`
+	To        string   `json:"to"`
+	Amount    int      `json:"amount"`
+	Proposer  string   `json:"proposer"`
+	Approvals []string `json:"approvals"`
+	Status    string   `json:"status"`
+	CreatedAt int64    `json:"createdAt"`
+
+	// Nonce signed by the proposer, approvals and cancellations sign it too
+	Nonce uint64 `json:"nonce"`
+
+	// Signer who cancelled the proposal
+	CancelledBy string `json:"cancelledBy,omitempty"`
+
+	// Id of the transaction that executed the transfer
+	ExecutedTxID string `json:"executedTxId,omitempty"`
+}
+
+// MultisigAction is the payload a signer of a multisig account signs to propose, approve or cancel a transfer
+// Channel and Chaincode name the deployment the payload is meant for, so a signature cannot be replayed on another one
+// A proposal carries the account, recipient and amount, and a nonce greater than the nonce of the last proposal of the account
+// An approval or cancellation carries the id and nonce of the proposal it acts on
+type MultisigAction struct {
+	Channel   string `json:"channel"`
+	Chaincode string `json:"chaincode"`
+	Action    string `json:"action"`
+	Account   string `json:"account"`
+	Signer    string `json:"signer"`
+	Proposal  string `json:"proposal,omitempty"`
+	To        string `json:"to,omitempty"`
+	Amount    int    `json:"amount,omitempty"`
+	Nonce     uint64 `json:"nonce"`
+}
+
+// SignerRotation is the payload the signers of a multisig account sign to replace its signers and threshold
+// Nonce must be greater than the nonce of the last proposal or rotation of the account
+type SignerRotation struct {
+	Channel    string   `json:"channel"`
+	Chaincode  string   `json:"chaincode"`
+	Account    string   `json:"account"`
+	Signers    []string `json:"signers"`
+	PublicKeys []string `json:"publicKeys"`
+	Threshold  int      `json:"threshold"`
+	Nonce      uint64   `json:"nonce"`
+}
+
+// RegisterMultisigAccount registers a corporate account of the calling commercial bank whose debits need the approval of
+// threshold of its signers
+// The bank relays proposals and approvals signed by the signers, it cannot sign for them and so cannot debit the account on its own
+// param {String[]} signers The ids of the authorised signatories
+// param {String[]} publicKeys The ECDSA P-256 or Ed25519 public keys of the signers as base64 DER, in the order of signers
+func (s *SmartContract) RegisterMultisigAccount(ctx contractapi.TransactionContextInterface, account string, signers []string, publicKeys []string, threshold int) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	// Only commercial banks onboard corporate customers
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	isBank, err := isCommercialBank(ctx, clientMSPID)
+	if err != nil {
+		return err
+	}
+	if !isBank {
+		return fmt.Errorf("%w: client with id %s is not authorized to register accounts", ErrUnauthorized, clientMSPID)
+	}
+
+	err = checkAddress(ctx, clientMSPID, account)
+	if err != nil {
+		return err
+	}
+
+	err = checkSigners(signers, publicKeys, threshold)
+	if err != nil {
+		return err
+	}
+
+	acc, err := newAccount(ctx, account, clientMSPID, accountTypeMultisig, 0, "")
+	if err != nil {
+		return err
+	}
+	acc.Signers = signers
+	acc.SignerKeys = publicKeys
+	acc.Threshold = threshold
+
+	err = putAccount(ctx, acc)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("multisig account %s registered for %s with %d of %d signers", account, clientMSPID, threshold, len(signers))
+
+	return nil
+}
+
+// ProposeTransfer proposes a transfer from a multisig account signed by one of its signers, whose approval it counts
+// param {String} payload The MultisigAction with the PROPOSE action as the JSON signed by the signer
+// param {String} signature The base64 signature of the payload by the registered public key of the signer
+// Returns the id of the proposal
+func (s *SmartContract) ProposeTransfer(ctx contractapi.TransactionContextInterface, payload string, signature string) (string, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return "", fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	action, err := getSignedMultisigAction(ctx, payload, signature, multisigActionPropose)
+	if err != nil {
+		return "", err
+	}
+
+	if action.Amount <= 0 {
+		return "", fmt.Errorf("%w: transfer amount must be a positive integer", ErrInvalidArgument)
+	}
+	if action.To == action.Account {
+		return "", fmt.Errorf("cannot transfer to and from same client account")
+	}
+	err = checkAccountID(ctx, action.To)
+	if err != nil {
+		return "", err
+	}
+
+	// A signed proposal can only be submitted once
+	err = advanceProposalNonce(ctx, action.Account, action.Nonce)
+	if err != nil {
+		return "", err
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return "", fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	proposal := &Proposal{
+		ID:        ctx.GetStub().GetTxID(),
+		Account:   action.Account,
+		To:        action.To,
+		Amount:    action.Amount,
+		Proposer:  action.Signer,
+		Approvals: []string{action.Signer},
+		Status:    proposalStatusPending,
+		CreatedAt: txTimestamp.GetSeconds(),
+		Nonce:     action.Nonce,
+	}
+	err = putProposal(ctx, proposal)
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("proposal %s by %s to transfer %d from %s to %s", proposal.ID, action.Signer, action.Amount, action.Account, action.To)
+
+	return proposal.ID, nil
+}
+
+// ApproveTransfer records the approval of a pending proposal signed by a signer of the multisig account
+// param {String} payload The MultisigAction with the APPROVE action as the JSON signed by the signer
+// param {String} signature The base64 signature of the payload by the registered public key of the signer
+func (s *SmartContract) ApproveTransfer(ctx contractapi.TransactionContextInterface, payload string, signature string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	action, err := getSignedMultisigAction(ctx, payload, signature, multisigActionApprove)
+	if err != nil {
+		return err
+	}
+
+	proposal, err := getSignedProposal(ctx, action)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(proposal.Approvals, action.Signer) {
+		return fmt.Errorf("%w: signer %s already approved proposal %s", ErrAlreadyExists, action.Signer, proposal.ID)
+	}
+
+	proposal.Approvals = append(proposal.Approvals, action.Signer)
+	err = putProposal(ctx, proposal)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("proposal %s approved by %s", proposal.ID, action.Signer)
+
+	return nil
+}
+
+// ExecuteTransfer makes the transfer of a pending proposal approved by at least the threshold of signers
+// The signer set and threshold of the account at execution time apply
+// This function triggers a Transfer event
+func (s *SmartContract) ExecuteTransfer(ctx contractapi.TransactionContextInterface, account string, id string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	acc, err := getMultisigAccountForCustodian(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	proposal, err := getPendingProposal(ctx, account, id)
+	if err != nil {
+		return err
+	}
+
+	approvals := 0
+	for _, signer := range proposal.Approvals {
+		if slices.Contains(acc.Signers, signer) {
+			approvals++
+		}
+	}
+	if approvals < acc.Threshold {
+		return fmt.Errorf("proposal %s has %d of the %d approvals it needs", id, approvals, acc.Threshold)
+	}
+
+	// Initiate the transfer
+	err = transferHelper(ctx, account, proposal.To, proposal.Amount)
+	if err != nil {
+		return fmt.Errorf("failed to transfer: %v", err)
+	}
+
+	// Record the transfer in the history of the accounts
+	err = recordTransfer(ctx, account, proposal.To, proposal.Amount)
+	if err != nil {
+		return err
+	}
+
+	proposal.Status = proposalStatusExecuted
+	proposal.ExecutedTxID = ctx.GetStub().GetTxID()
+	err = putProposal(ctx, proposal)
+	if err != nil {
+		return err
+	}
+
+	// Emit the Transfer event
+	transferEvent := event{account, proposal.To, proposal.Amount}
+	transferEventJSON, err := json.Marshal(transferEvent)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	log.Printf("proposal %s executed", id)
+
+	return nil
+}
+
+// CancelProposal cancels a pending proposal as signed by a signer of the multisig account
+// param {String} payload The MultisigAction with the CANCEL action as the JSON signed by the signer
+// param {String} signature The base64 signature of the payload by the registered public key of the signer
+func (s *SmartContract) CancelProposal(ctx contractapi.TransactionContextInterface, payload string, signature string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	action, err := getSignedMultisigAction(ctx, payload, signature, multisigActionCancel)
+	if err != nil {
+		return err
+	}
+
+	proposal, err := getSignedProposal(ctx, action)
+	if err != nil {
+		return err
+	}
+
+	proposal.Status = proposalStatusCancelled
+	proposal.CancelledBy = action.Signer
+	err = putProposal(ctx, proposal)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("proposal %s cancelled by %s", proposal.ID, action.Signer)
+
+	return nil
+}
+
+// RotateSigners replaces the signers and threshold of a multisig account
+// The rotation must be signed by at least the current threshold of the current signers
+// Pending proposals stay pending, only the approvals of the new signers count when they are executed
+// param {String} payload The SignerRotation as the JSON signed by the signers
+// param {String[]} signers The current signers who signed the payload
+// param {String[]} signatures The base64 signatures of the payload by the registered public keys of signers, in their order
+func (s *SmartContract) RotateSigners(ctx contractapi.TransactionContextInterface, payload string, signers []string, signatures []string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	var rotation SignerRotation
+	err = json.Unmarshal([]byte(payload), &rotation)
+	if err != nil {
+		return fmt.Errorf("failed to parse signer rotation: %v", err)
+	}
+
+	err = checkSignedDomain(ctx, rotation.Channel, rotation.Chaincode)
+	if err != nil {
+		return err
+	}
+
+	acc, err := getMultisigAccountForCustodian(ctx, rotation.Account)
+	if err != nil {
+		return err
+	}
+
+	if len(signatures) != len(signers) {
+		return fmt.Errorf("expected a signature for each of the %d signers, got %d", len(signers), len(signatures))
+	}
+	for i, signer := range signers {
+		if slices.Contains(signers[:i], signer) {
+			return fmt.Errorf("%w: signer %s appears more than once", ErrInvalidArgument, signer)
+		}
+		publicKey, err := acc.signerKey(signer)
+		if err != nil {
+			return err
+		}
+		err = verifySignature(publicKey, []byte(payload), signatures[i])
+		if err != nil {
+			return fmt.Errorf("signer %s: %v", signer, err)
+		}
+	}
+	if len(signers) < acc.Threshold {
+		return fmt.Errorf("the rotation has %d of the %d signatures it needs", len(signers), acc.Threshold)
+	}
+
+	err = checkSigners(rotation.Signers, rotation.PublicKeys, rotation.Threshold)
+	if err != nil {
+		return err
+	}
+
+	// A signed rotation can only be submitted once
+	err = advanceProposalNonce(ctx, rotation.Account, rotation.Nonce)
+	if err != nil {
+		return err
+	}
+
+	acc.Signers = rotation.Signers
+	acc.SignerKeys = rotation.PublicKeys
+	acc.Threshold = rotation.Threshold
+	err = putAccount(ctx, acc)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("multisig account %s rotated to %d of %d signers", acc.ID, acc.Threshold, len(acc.Signers))
+
+	return nil
+}
+
+// GetProposal returns a proposal of a multisig account
+func (s *SmartContract) GetProposal(ctx contractapi.TransactionContextInterface, account string, id string) (*Proposal, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	return getProposal(ctx, account, id)
+}
+
+// GetPendingProposals returns the proposals of a multisig account waiting for approval or execution
+func (s *SmartContract) GetPendingProposals(ctx contractapi.TransactionContextInterface, account string) ([]*Proposal, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(proposalPrefix, []string{account})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proposals of %s from world state: %v", account, err)
+	}
+	defer resultsIterator.Close()
+
+	proposals := []*Proposal{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read proposal of %s: %v", account, err)
+		}
+
+		var proposal Proposal
+		err = json.Unmarshal(queryResponse.Value, &proposal)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proposal %s: %v", queryResponse.Key, err)
+		}
+		if proposal.Status == proposalStatusPending {
+			proposals = append(proposals, &proposal)
+		}
+	}
+
+	return proposals, nil
+}
+
+// GetProposalNonce returns the nonce of the last proposal or signer rotation of a multisig account, 0 if there was none
+func (s *SmartContract) GetProposalNonce(ctx contractapi.TransactionContextInterface, account string) (uint64, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return 0, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	nonceKey, err := ctx.GetStub().CreateCompositeKey(proposalNoncePrefix, []string{account})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create the composite key for prefix %s: %v", proposalNoncePrefix, err)
+	}
+
+	return getSignedNonce(ctx, nonceKey)
+}
+
+// getMultisigAccountForCustodian reads a multisig account, failing unless the caller is the bank holding its custody mandate
+func getMultisigAccountForCustodian(ctx contractapi.TransactionContextInterface, account string) (*Account, error) {
+
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MSPID: %v", err)
+	}
+
+	acc, err := getAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	if acc == nil || acc.Type != accountTypeMultisig {
+		return nil, fmt.Errorf("%s is not a multisig account", account)
+	}
+	if acc.custodian() != clientMSPID {
+		return nil, fmt.Errorf("%w: client with id %s is not authorized to act for the signers of %s", ErrUnauthorized, clientMSPID, account)
+	}
+
+	return acc, nil
+}
+
+// getSignedMultisigAction parses a multisig action relayed by the custodian of its account and checks the signature of its signer
+func getSignedMultisigAction(ctx contractapi.TransactionContextInterface, payload string, signature string, actionType string) (*MultisigAction, error) {
+
+	var action MultisigAction
+	err := json.Unmarshal([]byte(payload), &action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse multisig action: %v", err)
+	}
+	if action.Action != actionType {
+		return nil, fmt.Errorf("the payload is signed for action %q, not %q", action.Action, actionType)
+	}
+
+	err = checkSignedDomain(ctx, action.Channel, action.Chaincode)
+	if err != nil {
+		return nil, err
+	}
+
+	acc, err := getMultisigAccountForCustodian(ctx, action.Account)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := acc.signerKey(action.Signer)
+	if err != nil {
+		return nil, err
+	}
+	err = verifySignature(publicKey, []byte(payload), signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return &action, nil
+}
+
+// getSignedProposal reads the pending proposal an approval or cancellation acts on, failing unless it carries its nonce
+func getSignedProposal(ctx contractapi.TransactionContextInterface, action *MultisigAction) (*Proposal, error) {
+
+	proposal, err := getPendingProposal(ctx, action.Account, action.Proposal)
+	if err != nil {
+		return nil, err
+	}
+	if action.Nonce != proposal.Nonce {
+		return nil, fmt.Errorf("the payload is signed for nonce %d, proposal %s has nonce %d", action.Nonce, proposal.ID, proposal.Nonce)
+	}
+
+	return proposal, nil
+}
+
+// getPendingProposal reads a proposal, failing unless it is pending
+func getPendingProposal(ctx contractapi.TransactionContextInterface, account string, id string) (*Proposal, error) {
+
+	proposal, err := getProposal(ctx, account, id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status != proposalStatusPending {
+		return nil, fmt.Errorf("the proposal %s is %s", id, proposal.Status)
+	}
+
+	return proposal, nil
+}
+
+// advanceProposalNonce records the nonce of a proposal or rotation of an account, failing unless it is greater than the last one
+func advanceProposalNonce(ctx contractapi.TransactionContextInterface, account string, nonce uint64) error {
+
+	nonceKey, err := ctx.GetStub().CreateCompositeKey(proposalNoncePrefix, []string{account})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", proposalNoncePrefix, err)
+	}
+	lastNonce, err := getSignedNonce(ctx, nonceKey)
+	if err != nil {
+		return err
+	}
+	if nonce <= lastNonce {
+		return fmt.Errorf("%w: nonce %d must be greater than %d", ErrInvalidArgument, nonce, lastNonce)
+	}
+
+	err = ctx.GetStub().PutState(nonceKey, []byte(strconv.FormatUint(nonce, 10)))
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", nonceKey, err)
+	}
+
+	return nil
+}
+
+// checkSigners checks a set of signers, their public keys and the threshold of their approvals
+func checkSigners(signers []string, publicKeys []string, threshold int) error {
+
+	if len(publicKeys) != len(signers) {
+		return fmt.Errorf("expected a public key for each of the %d signers, got %d", len(signers), len(publicKeys))
+	}
+	for i, signer := range signers {
+		if signer == "" {
+			return fmt.Errorf("%w: signer id cannot be empty", ErrInvalidArgument)
+		}
+		if slices.Contains(signers[:i], signer) {
+			return fmt.Errorf("%w: signer %s appears more than once", ErrInvalidArgument, signer)
+		}
+		_, err := parsePublicKey(publicKeys[i])
+		if err != nil {
+			return fmt.Errorf("signer %s: %v", signer, err)
+		}
+	}
+	if threshold < 1 || threshold > len(signers) {
+		return fmt.Errorf("%w: threshold must be between 1 and the %d signers", ErrInvalidArgument, len(signers))
+	}
+
+	return nil
+}
+
+// getProposal reads a proposal of a multisig account
+func getProposal(ctx contractapi.TransactionContextInterface, account string, id string) (*Proposal, error) {
+
+	proposalKey, err := ctx.GetStub().CreateCompositeKey(proposalPrefix, []string{account, id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", proposalPrefix, err)
+	}
+
+	proposalBytes, err := ctx.GetStub().GetState(proposalKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proposal %s from world state: %v", id, err)
+	}
+	if proposalBytes == nil {
+		return nil, fmt.Errorf("%w: the proposal %s of %s does not exist", ErrNotFound, id, account)
+	}
+
+	var proposal Proposal
+	err = json.Unmarshal(proposalBytes, &proposal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proposal %s: %v", id, err)
+	}
+
+	return &proposal, nil
+}
+
+// putProposal stores a proposal
+func putProposal(ctx contractapi.TransactionContextInterface, proposal *Proposal) error {
+
+	proposalKey, err := ctx.GetStub().CreateCompositeKey(proposalPrefix, []string{proposal.Account, proposal.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", proposalPrefix, err)
+	}
+
+	proposalJSON, err := json.Marshal(proposal)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(proposalKey, proposalJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", proposalKey, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package chaincode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+// multisig is a corporate account of HDFC whose debits need 2 of the approvals of alice, bob and carol
+type multisig struct {
+	*network
+	account string
+	signers map[string]*signer
+}
+
+// newMultisig registers a 2 of 3 multisig account funded with 5000
+func newMultisig(t *testing.T) *multisig {
+	n := newNetwork(t)
+	n.invokeChaincode("cbdc")
+	m := &multisig{network: n, account: testAddress("hdfc", 9), signers: make(map[string]*signer)}
+
+	names := []string{"alice", "bob", "carol"}
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		m.signers[name] = newSigner(t, "P-256")
+		keys = append(keys, m.signers[name].publicKey)
+	}
+	n.ok(hdfcMSP, func() error { return n.contract.RegisterMultisigAccount(n.ctx, m.account, names, keys, 2) })
+	n.transfer(hdfcMSP, hdfcReserve, m.account, 5000)
+	return m
+}
+
+// action encodes a multisig action of the account as the JSON its signer signs
+func (m *multisig) action(action chaincode.MultisigAction) []byte {
+	action.Channel = "retail"
+	action.Chaincode = "cbdc"
+	action.Account = m.account
+	payload, err := json.Marshal(action)
+	require.NoError(m.t, err)
+	return payload
+}
+
+// propose submits a proposal to transfer to an account signed by a signer, returning the proposal id
+func (m *multisig) propose(name string, to string, amount int, nonce uint64) (string, error) {
+	payload := m.action(chaincode.MultisigAction{Action: "PROPOSE", Signer: name, To: to, Amount: amount, Nonce: nonce})
+	var id string
+	err := m.submit(hdfcMSP, func() error {
+		var err error
+		id, err = m.contract.ProposeTransfer(m.ctx, string(payload), m.signers[name].sign(payload))
+		return err
+	})
+	return id, err
+}
+
+// approve submits the approval of a proposal signed by a signer
+func (m *multisig) approve(name string, id string, nonce uint64) error {
+	payload := m.action(chaincode.MultisigAction{Action: "APPROVE", Signer: name, Proposal: id, Nonce: nonce})
+	return m.submit(hdfcMSP, func() error { return m.contract.ApproveTransfer(m.ctx, string(payload), m.signers[name].sign(payload)) })
+}
+
+// execute submits the execution of a proposal
+func (m *multisig) execute(id string) error {
+	return m.submit(hdfcMSP, func() error { return m.contract.ExecuteTransfer(m.ctx, m.account, id) })
+}
+
+func TestMultisigQuorum(t *testing.T) {
+	m := newMultisig(t)
+	payee := m.openAccount(axisMSP, 2, 0, "", 0)
+
+	// The bank cannot debit the account on its own
+	err := m.submit(hdfcMSP, func() error { return m.contract.TransferFrom(m.ctx, m.account, payee, 1000, "") })
+	require.ErrorContains(t, err, "its debits must be proposed and approved by its signers")
+
+	// The proposal counts as the approval of its proposer, one short of the threshold
+	id, err := m.propose("alice", payee, 1000, 1)
+	require.NoError(t, err)
+	require.ErrorContains(t, m.execute(id), "has 1 of the 2 approvals it needs")
+
+	// Approving twice does not count twice
+	require.ErrorContains(t, m.approve("alice", id, 1), "signer alice already approved proposal "+id)
+
+	// A key outside the signers cannot approve, whatever signer it claims to be
+	m.signers["mallory"] = newSigner(t, "P-256")
+	require.ErrorContains(t, m.approve("mallory", id, 1), "mallory is not a signer of "+m.account)
+	payload := m.action(chaincode.MultisigAction{Action: "APPROVE", Signer: "bob", Proposal: id, Nonce: 1})
+	err = m.submit(hdfcMSP, func() error {
+		return m.contract.ApproveTransfer(m.ctx, string(payload), m.signers["mallory"].sign(payload))
+	})
+	require.ErrorContains(t, err, "the signature does not match")
+	require.Equal(t, 5000, m.balance(m.account))
+
+	// The approval of a second signer reaches the quorum
+	require.NoError(t, m.approve("bob", id, 1))
+	require.NoError(t, m.execute(id))
+	require.Equal(t, 4000, m.balance(m.account))
+	require.Equal(t, 1000, m.balance(payee))
+
+	proposal, err := m.contract.GetProposal(m.ctx, m.account, id)
+	require.NoError(t, err)
+	require.Equal(t, "EXECUTED", proposal.Status)
+	require.Equal(t, []string{"alice", "bob"}, proposal.Approvals)
+
+	// An executed proposal is neither approved nor executed again
+	require.ErrorContains(t, m.approve("carol", id, 1), "is EXECUTED")
+	require.ErrorContains(t, m.execute(id), "is EXECUTED")
+	require.Equal(t, 4000, m.balance(m.account))
+}
+
+func TestMultisigProposalNonce(t *testing.T) {
+	m := newMultisig(t)
+	payee := m.openAccount(axisMSP, 2, 0, "", 0)
+
+	id, err := m.propose("alice", payee, 1000, 5)
+	require.NoError(t, err)
+
+	// A signed proposal cannot be submitted again, nor can an older one
+	_, err = m.propose("alice", payee, 1000, 5)
+	require.ErrorContains(t, err, "nonce 5 must be greater than 5")
+	_, err = m.propose("bob", payee, 1000, 4)
+	require.ErrorContains(t, err, "nonce 4 must be greater than 5")
+
+	// An approval carries the nonce of the proposal it approves
+	require.ErrorContains(t, m.approve("bob", id, 4), "the payload is signed for nonce 4")
+}
+
+func TestRotateSigners(t *testing.T) {
+	m := newMultisig(t)
+	payee := m.openAccount(axisMSP, 2, 0, "", 0)
+
+	// carol approves a proposal, then is rotated out in favour of dave
+	id, err := m.propose("alice", payee, 1000, 1)
+	require.NoError(t, err)
+	require.NoError(t, m.approve("carol", id, 1))
+
+	m.signers["dave"] = newSigner(t, "Ed25519")
+	rotation, err := json.Marshal(chaincode.SignerRotation{
+		Channel:    "retail",
+		Chaincode:  "cbdc",
+		Account:    m.account,
+		Signers:    []string{"alice", "bob", "dave"},
+		PublicKeys: []string{m.signers["alice"].publicKey, m.signers["bob"].publicKey, m.signers["dave"].publicKey},
+		Threshold:  2,
+		Nonce:      2,
+	})
+	require.NoError(t, err)
+	rotate := func(names ...string) error {
+		signatures := make([]string, 0, len(names))
+		for _, name := range names {
+			signatures = append(signatures, m.signers[name].sign(rotation))
+		}
+		return m.submit(hdfcMSP, func() error { return m.contract.RotateSigners(m.ctx, string(rotation), names, signatures) })
+	}
+
+	// The rotation needs the current threshold of the current signers
+	require.ErrorContains(t, rotate("alice"), "the rotation has 1 of the 2 signatures it needs")
+	require.ErrorContains(t, rotate("alice", "dave"), "dave is not a signer of "+m.account)
+	require.ErrorContains(t, rotate("alice", "alice"), "signer alice appears more than once")
+	require.NoError(t, rotate("alice", "carol"))
+	require.ErrorContains(t, rotate("alice", "bob"), "nonce 2 must be greater than 2")
+
+	// The approval carol gave before the rotation no longer counts, and carol can no longer approve
+	require.ErrorContains(t, m.execute(id), "has 1 of the 2 approvals it needs")
+	require.ErrorContains(t, m.approve("carol", id, 1), "carol is not a signer of "+m.account)
+	require.Equal(t, 5000, m.balance(m.account))
+
+	require.NoError(t, m.approve("dave", id, 1))
+	require.NoError(t, m.execute(id))
+	require.Equal(t, 1000, m.balance(payee))
+}
@@ -0,0 +1,66 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Define objectType names for payment references
+const paymentRefPrefix = "paymentref"
+
+// GetPaymentReference returns the id of the transaction that used a payment reference for debiting an account
+// An empty string is returned if the reference has not been used yet
+func (s *SmartContract) GetPaymentReference(ctx contractapi.TransactionContextInterface, from string, paymentRef string) (string, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return "", fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	paymentRefKey, err := ctx.GetStub().CreateCompositeKey(paymentRefPrefix, []string{from, paymentRef})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the composite key for prefix %s: %v", paymentRefPrefix, err)
+	}
+
+	txIDBytes, err := ctx.GetStub().GetState(paymentRefKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read payment reference %s from world state: %v", paymentRef, err)
+	}
+
+	return string(txIDBytes), nil
+}
+
+// claimPaymentReference records the current transaction against a payment reference of the debited account,
+// failing with the original transaction id if the reference was already used
+// An empty reference is not recorded
+func claimPaymentReference(ctx contractapi.TransactionContextInterface, from string, paymentRef string) error {
+
+	if paymentRef == "" {
+		return nil
+	}
+
+	paymentRefKey, err := ctx.GetStub().CreateCompositeKey(paymentRefPrefix, []string{from, paymentRef})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", paymentRefPrefix, err)
+	}
+
+	txIDBytes, err := ctx.GetStub().GetState(paymentRefKey)
+	if err != nil {
+		return fmt.Errorf("failed to read payment reference %s from world state: %v", paymentRef, err)
+	}
+	if txIDBytes != nil {
+		return fmt.Errorf("%w: payment reference %s was already used by transaction %s", ErrAlreadyExists, paymentRef, string(txIDBytes))
+	}
+
+	err = ctx.GetStub().PutState(paymentRefKey, []byte(ctx.GetStub().GetTxID()))
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", paymentRefKey, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentReference(t *testing.T) {
+	n := newNetwork(t)
+	payer := n.openAccount(hdfcMSP, 1, 0, "", 5000)
+	otherPayer := n.openAccount(hdfcMSP, 3, 0, "", 5000)
+	payee := n.openAccount(axisMSP, 2, 0, "", 0)
+
+	txID, err := n.contract.GetPaymentReference(n.ctx, payer, "invoice-1")
+	require.NoError(t, err)
+	require.Empty(t, txID)
+
+	n.ok(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, payer, payee, 1000, "invoice-1") })
+	paidBy := n.stub.TxID
+
+	txID, err = n.contract.GetPaymentReference(n.ctx, payer, "invoice-1")
+	require.NoError(t, err)
+	require.Equal(t, paidBy, txID)
+
+	// A retried payment is rejected with the transaction that made it
+	err = n.submit(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, payer, payee, 1000, "invoice-1") })
+	require.ErrorContains(t, err, "payment reference invoice-1 was already used by transaction "+paidBy)
+	require.Equal(t, 4000, n.balance(payer))
+
+	// References are scoped to the debited account, and a failed payment does not use its reference
+	err = n.submit(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, otherPayer, payee, 6000, "invoice-1") })
+	require.ErrorContains(t, err, "insufficient funds")
+	n.ok(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, otherPayer, payee, 1000, "invoice-1") })
+
+	// Payments without a reference are not deduplicated
+	n.transfer(hdfcMSP, payer, payee, 1000)
+	n.transfer(hdfcMSP, payer, payee, 1000)
+	require.Equal(t, 4000, n.balance(payee))
+}
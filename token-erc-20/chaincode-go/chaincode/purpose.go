@@ -0,0 +1,357 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"slices"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Define objectType names for purpose-bound funds
+const purposePrefix = "purpose"
+const purposeBalancePrefix = "purposebalance"
+
+// ErrPurposeRestricted is returned when a transfer would spend purpose-bound funds at a recipient outside their purpose
+var ErrPurposeRestricted = errors.New("purpose-bound funds cannot be spent at this recipient")
+
+// Purpose restricts the funds minted for it to merchants of the listed categories
+type Purpose struct {
+	ID                 string   `json:"id"`
+	MerchantCategories []string `json:"merchantCategories"`
+}
+
+// PurposeBalance is the part of an account balance bound to a purpose
+type PurposeBalance struct {
+	Purpose string `json:"purpose"`
+	Amount  string `json:"amount"`
+}
+
+// BalanceBuckets splits the balance of an account into free and purpose-bound funds
+// Amounts are in minor units as base 10 big integers
+type BalanceBuckets struct {
+	Account      string            `json:"account"`
+	Total        string            `json:"total"`
+	Free         string            `json:"free"`
+	PurposeBound []*PurposeBalance `json:"purposeBound"`
+}
+
+// purposeBucket is a purpose-bound balance as read from the world state
+type purposeBucket struct {
+	key     string
+	purpose string
+	amount  *big.Int
+	record  *BalanceRecord
+}
+
+// DefinePurpose defines or replaces the merchant categories at which the funds of a purpose can be spent
+// param {String[]} merchantCategories The merchant category codes accepting the funds
+func (s *SmartContract) DefinePurpose(ctx contractapi.TransactionContextInterface, purpose string, merchantCategories []string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	// Check central banker authorization
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != CentralBankerMSPId {
+		return fmt.Errorf("%w: client with id %s is not authorized to define purposes", ErrUnauthorized, clientMSPID)
+	}
+
+	if purpose == "" {
+		return fmt.Errorf("%w: purpose id cannot be empty", ErrInvalidArgument)
+	}
+	if len(merchantCategories) == 0 {
+		return fmt.Errorf("purpose %s must accept at least one merchant category", purpose)
+	}
+
+	purposeKey, err := ctx.GetStub().CreateCompositeKey(purposePrefix, []string{purpose})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", purposePrefix, err)
+	}
+
+	purposeJSON, err := json.Marshal(Purpose{purpose, merchantCategories})
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(purposeKey, purposeJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", purposeKey, err)
+	}
+
+	log.Printf("purpose %s defined as %s", purpose, purposeJSON)
+
+	return nil
+}
+
+// GetPurpose returns the merchant categories at which the funds of a purpose can be spent
+func (s *SmartContract) GetPurpose(ctx contractapi.TransactionContextInterface, purpose string) (*Purpose, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	p, err := getPurpose(ctx, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, fmt.Errorf("%w: the purpose %s is not defined", ErrNotFound, purpose)
+	}
+
+	return p, nil
+}
+
+// MintPurposeBound creates new tokens that the retail account can only spend at merchants accepting the purpose
+// This function triggers a Transfer event
+func (s *SmartContract) MintPurposeBound(ctx contractapi.TransactionContextInterface, account string, amount int, purpose string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	// Check minter authorization
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != CentralBankerMSPId {
+		return fmt.Errorf("%w: client is not authorized to mint new tokens", ErrUnauthorized)
+	}
+
+	p, err := getPurpose(ctx, purpose)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return fmt.Errorf("%w: the purpose %s is not defined", ErrNotFound, purpose)
+	}
+
+	// Purpose-bound funds are issued to beneficiaries, not to bank reserves
+	acc, err := getAccount(ctx, account)
+	if err != nil {
+		return err
+	}
+	if acc == nil || acc.Type != accountTypeRetail {
+		return fmt.Errorf("%s is not a retail account", account)
+	}
+	err = checkCreditAllowed(acc)
+	if err != nil {
+		return err
+	}
+
+	currentBalance, _, err := readBalance(ctx, account)
+	if err != nil {
+		return err
+	}
+	updatedBalance, err := addBig(currentBalance, amount)
+	if err != nil {
+		return err
+	}
+	err = enforceHoldingLimit(ctx, acc, updatedBalance)
+	if err != nil {
+		return err
+	}
+
+	err = mintHelper(ctx, account, amount)
+	if err != nil {
+		return err
+	}
+
+	// Tag the minted amount with the purpose
+	purposeBalanceKey, err := ctx.GetStub().CreateCompositeKey(purposeBalancePrefix, []string{account, purpose})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", purposeBalancePrefix, err)
+	}
+
+	purposeBalance, purposeRecord, err := readBalance(ctx, purposeBalanceKey)
+	if err != nil {
+		return err
+	}
+	purposeBalance, err = addBig(purposeBalance, amount)
+	if err != nil {
+		return err
+	}
+
+	err = writeBalance(ctx, purposeBalanceKey, purposeBalance, purposeRecord)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("account %s holds %d bound to purpose %s", account, purposeBalance, purpose)
+
+	return nil
+}
+
+// GetBalanceBuckets returns the free and purpose-bound parts of the balance of an account
+func (s *SmartContract) GetBalanceBuckets(ctx contractapi.TransactionContextInterface, account string) (*BalanceBuckets, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	balance, _, err := readBalance(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := readPurposeBuckets(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	free := new(big.Int).Set(balance)
+	result := &BalanceBuckets{Account: account, Total: balance.String(), PurposeBound: []*PurposeBalance{}}
+	for _, bucket := range buckets {
+		free.Sub(free, bucket.amount)
+		result.PurposeBound = append(result.PurposeBound, &PurposeBalance{bucket.purpose, bucket.amount.String()})
+	}
+	result.Free = free.String()
+
+	return result, nil
+}
+
+// getPurpose reads a purpose definition, returning nil if the purpose is not defined
+func getPurpose(ctx contractapi.TransactionContextInterface, purpose string) (*Purpose, error) {
+
+	purposeKey, err := ctx.GetStub().CreateCompositeKey(purposePrefix, []string{purpose})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", purposePrefix, err)
+	}
+
+	purposeBytes, err := ctx.GetStub().GetState(purposeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read purpose %s from world state: %v", purpose, err)
+	}
+	if purposeBytes == nil {
+		return nil, nil
+	}
+
+	var p Purpose
+	err = json.Unmarshal(purposeBytes, &p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse purpose %s: %v", purpose, err)
+	}
+
+	return &p, nil
+}
+
+// readPurposeBuckets reads the purpose-bound balances of an account, ordered by purpose
+func readPurposeBuckets(ctx contractapi.TransactionContextInterface, account string) ([]*purposeBucket, error) {
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(purposeBalancePrefix, []string{account})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read purpose-bound balances of %s from world state: %v", account, err)
+	}
+	defer resultsIterator.Close()
+
+	var buckets []*purposeBucket
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read purpose-bound balance of %s: %v", account, err)
+		}
+
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split the composite key %s: %v", queryResponse.Key, err)
+		}
+
+		amount, record, err := readBalance(ctx, queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, &purposeBucket{queryResponse.Key, keyParts[1], amount, record})
+	}
+
+	return buckets, nil
+}
+
+// spendPurposeBound settles a debit of value from an account holding balance against its purpose-bound funds
+// Funds bound to a purpose accepted by the recipient's merchant category are spent first, the rest must be covered by free funds
+// Purpose-bound funds received by a merchant become free funds of the merchant
+// A nil toAccount only lets free funds be spent
+func spendPurposeBound(ctx contractapi.TransactionContextInterface, from string, toAccount *Account, balance *big.Int, value int) error {
+
+	buckets, err := readPurposeBuckets(ctx, from)
+	if err != nil {
+		return err
+	}
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	free := new(big.Int).Set(balance)
+	for _, bucket := range buckets {
+		free.Sub(free, bucket.amount)
+	}
+
+	remaining := big.NewInt(int64(value))
+	for _, bucket := range buckets {
+		if remaining.Sign() == 0 || toAccount == nil || toAccount.MerchantCategory == "" {
+			break
+		}
+
+		p, err := getPurpose(ctx, bucket.purpose)
+		if err != nil {
+			return err
+		}
+		if p == nil || !slices.Contains(p.MerchantCategories, toAccount.MerchantCategory) {
+			continue
+		}
+
+		spent := new(big.Int).Set(remaining)
+		if spent.Cmp(bucket.amount) > 0 {
+			spent.Set(bucket.amount)
+		}
+		remaining.Sub(remaining, spent)
+
+		updatedAmount := new(big.Int).Sub(bucket.amount, spent)
+		if updatedAmount.Sign() == 0 {
+			err = ctx.GetStub().DelState(bucket.key)
+			if err != nil {
+				return fmt.Errorf("failed to delete state of smart contract for key %s: %v", bucket.key, err)
+			}
+		} else {
+			err = writeBalance(ctx, bucket.key, updatedAmount, bucket.record)
+			if err != nil {
+				return err
+			}
+		}
+
+		log.Printf("account %s spent %d bound to purpose %s at %s", from, spent, bucket.purpose, toAccount.ID)
+	}
+
+	if remaining.Cmp(free) > 0 {
+		return fmt.Errorf("%w: account %s has %s of free funds", ErrPurposeRestricted, from, free)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurposeBoundSpending(t *testing.T) {
+	tests := []struct {
+		name          string
+		category      string
+		amount        int
+		wantErr       string
+		wantFree      string
+		wantBound     string
+		wantMerchant  int
+		wantBucketSet bool
+	}{
+		{
+			name:          "accepting merchant takes bound funds first",
+			category:      "5191",
+			amount:        2000,
+			wantFree:      "1000",
+			wantBound:     "1000",
+			wantMerchant:  2000,
+			wantBucketSet: true,
+		},
+		{
+			name:         "bound funds spent in full drop the bucket",
+			category:     "5191",
+			amount:       3500,
+			wantFree:     "500",
+			wantMerchant: 3500,
+		},
+		{
+			name:          "other merchants take free funds only",
+			category:      "5411",
+			amount:        1000,
+			wantFree:      "0",
+			wantBound:     "3000",
+			wantMerchant:  1000,
+			wantBucketSet: true,
+		},
+		{
+			name:     "bound funds cannot be spent at other merchants",
+			category: "5411",
+			amount:   1001,
+			wantErr:  chaincode.ErrPurposeRestricted.Error(),
+		},
+		{
+			name:     "mixed spending is capped by free and accepted funds",
+			category: "5191",
+			amount:   4001,
+			wantErr:  "insufficient funds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNetwork(t)
+			n.ok(centralBankMSP, func() error { return n.contract.DefinePurpose(n.ctx, "fertilizer", []string{"5191"}) })
+			farmer := n.openAccount(hdfcMSP, 1, 0, "", 1000)
+			merchant := n.openAccount(axisMSP, 2, 0, tt.category, 0)
+			n.ok(centralBankMSP, func() error { return n.contract.MintPurposeBound(n.ctx, farmer, 3000, "fertilizer") })
+
+			err := n.submit(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, farmer, merchant, tt.amount, "") })
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				require.Equal(t, 4000, n.balance(farmer))
+				return
+			}
+			require.NoError(t, err)
+
+			buckets, err := n.contract.GetBalanceBuckets(n.ctx, farmer)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantFree, buckets.Free)
+			if tt.wantBucketSet {
+				require.Equal(t, []*chaincode.PurposeBalance{{Purpose: "fertilizer", Amount: tt.wantBound}}, buckets.PurposeBound)
+			} else {
+				require.Empty(t, buckets.PurposeBound)
+			}
+
+			// The merchant receives free funds
+			merchantBuckets, err := n.contract.GetBalanceBuckets(n.ctx, merchant)
+			require.NoError(t, err)
+			require.Empty(t, merchantBuckets.PurposeBound)
+			require.Equal(t, tt.wantMerchant, n.balance(merchant))
+		})
+	}
+}
+
+func TestMintPurposeBound(t *testing.T) {
+	n := newNetwork(t)
+	farmer := n.openAccount(hdfcMSP, 1, 0, "", 0)
+
+	err := n.submit(centralBankMSP, func() error { return n.contract.MintPurposeBound(n.ctx, farmer, 3000, "fertilizer") })
+	require.ErrorContains(t, err, "the purpose fertilizer is not defined")
+
+	err = n.submit(hdfcMSP, func() error { return n.contract.DefinePurpose(n.ctx, "fertilizer", []string{"5191"}) })
+	require.ErrorContains(t, err, "not authorized to define purposes")
+
+	n.ok(centralBankMSP, func() error { return n.contract.DefinePurpose(n.ctx, "fertilizer", []string{"5191"}) })
+
+	err = n.submit(centralBankMSP, func() error { return n.contract.MintPurposeBound(n.ctx, hdfcReserve, 3000, "fertilizer") })
+	require.ErrorContains(t, err, "is not a retail account")
+
+	n.ok(centralBankMSP, func() error { return n.contract.MintPurposeBound(n.ctx, farmer, 3000, "fertilizer") })
+	n.ok(centralBankMSP, func() error { return n.contract.MintPurposeBound(n.ctx, farmer, 500, "fertilizer") })
+
+	buckets, err := n.contract.GetBalanceBuckets(n.ctx, farmer)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.BalanceBuckets{
+		Account:      farmer,
+		Total:        "3500",
+		Free:         "0",
+		PurposeBound: []*chaincode.PurposeBalance{{Purpose: "fertilizer", Amount: "3500"}},
+	}, buckets)
+}
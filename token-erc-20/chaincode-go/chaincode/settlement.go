@@ -0,0 +1,197 @@
+package chaincode
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Define objectType names for interbank flows
+const interbankFlowPrefix = "interbankflow"
+
+// Settlement windows are UTC days
+const settlementWindowLayout = time.DateOnly
+
+// InterbankFlow is the gross amount paid from accounts of one bank to accounts of another within a settlement window
+type InterbankFlow struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount int    `json:"amount"`
+}
+
+// BilateralPosition is the net amount one bank owes another within a settlement window
+type BilateralPosition struct {
+	Debtor   string `json:"debtor"`
+	Creditor string `json:"creditor"`
+	Amount   int    `json:"amount"`
+}
+
+// BankPosition is the multilateral net position of a bank within a settlement window
+// A positive amount is owed to the bank, a negative one is owed by it
+type BankPosition struct {
+	Bank   string `json:"bank"`
+	Amount int    `json:"amount"`
+}
+
+// NetPositions are the interbank flows of a settlement window with their bilateral and multilateral net positions
+type NetPositions struct {
+	Window       string               `json:"window"`
+	Gross        []*InterbankFlow     `json:"gross"`
+	Bilateral    []*BilateralPosition `json:"bilateral"`
+	Multilateral []*BankPosition      `json:"multilateral"`
+}
+
+// GetNetPositions returns the gross interbank flows of a settlement window and the net positions of the commercial banks
+// param {String} window The settlement window as a UTC date such as 2024-01-31, empty for the current window
+func (s *SmartContract) GetNetPositions(ctx contractapi.TransactionContextInterface, window string) (*NetPositions, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	if window == "" {
+		window, err = currentSettlementWindow(ctx)
+		if err != nil {
+			return nil, err
+		}
+	} else if _, err := time.Parse(settlementWindowLayout, window); err != nil {
+		return nil, fmt.Errorf("%w: settlement window must be a date such as 2024-01-31", ErrInvalidArgument)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(interbankFlowPrefix, []string{window})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read interbank flows of %s from world state: %v", window, err)
+	}
+	defer resultsIterator.Close()
+
+	positions := &NetPositions{Window: window, Gross: []*InterbankFlow{}, Bilateral: []*BilateralPosition{}, Multilateral: []*BankPosition{}}
+	gross := make(map[[2]string]int)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read interbank flow: %v", err)
+		}
+
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split the composite key %s: %v", queryResponse.Key, err)
+		}
+
+		amount, _ := strconv.Atoi(string(queryResponse.Value)) // Error handling not needed since Itoa() was used when setting the flow, guaranteeing it was an integer.
+		gross[[2]string{keyParts[1], keyParts[2]}] = amount
+		positions.Gross = append(positions.Gross, &InterbankFlow{keyParts[1], keyParts[2], amount})
+	}
+
+	banks, err := getCommercialBankMSPIds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	multilateral := make(map[string]int)
+	for i, a := range banks {
+		for _, b := range banks[i+1:] {
+			net := gross[[2]string{a, b}] - gross[[2]string{b, a}]
+			switch {
+			case net > 0:
+				positions.Bilateral = append(positions.Bilateral, &BilateralPosition{a, b, net})
+			case net < 0:
+				positions.Bilateral = append(positions.Bilateral, &BilateralPosition{b, a, -net})
+			}
+			multilateral[a] -= net
+			multilateral[b] += net
+		}
+	}
+	for _, bank := range banks {
+		positions.Multilateral = append(positions.Multilateral, &BankPosition{bank, multilateral[bank]})
+	}
+
+	return positions, nil
+}
+
+// addInterbankFlow adds a payment between two accounts to the gross flow between their banks in the current settlement window
+func addInterbankFlow(ctx contractapi.TransactionContextInterface, fromAccount *Account, toAccount *Account, value int) error {
+	if fromAccount == nil || toAccount == nil {
+		return nil
+	}
+	return addInterbankFlows(ctx, map[[2]string]int{{fromAccount.Bank, toAccount.Bank}: value})
+}
+
+// addInterbankFlows adds payments summed per paying and receiving bank to the gross flows of the current settlement window
+// Payments within a bank and payments involving the central bank are not interbank flows
+func addInterbankFlows(ctx contractapi.TransactionContextInterface, flows map[[2]string]int) error {
+
+	window, err := currentSettlementWindow(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Every peer must produce the same write set
+	pairs := make([][2]string, 0, len(flows))
+	for pair := range flows {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+
+	banks, err := getCommercialBankMSPIds(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, pair := range pairs {
+		from, to := pair[0], pair[1]
+		if from == to || !slices.Contains(banks, from) || !slices.Contains(banks, to) || flows[pair] == 0 {
+			continue
+		}
+
+		flowKey, err := ctx.GetStub().CreateCompositeKey(interbankFlowPrefix, []string{window, from, to})
+		if err != nil {
+			return fmt.Errorf("failed to create the composite key for prefix %s: %v", interbankFlowPrefix, err)
+		}
+
+		flowBytes, err := ctx.GetStub().GetState(flowKey)
+		if err != nil {
+			return fmt.Errorf("failed to read interbank flow from %s to %s from world state: %v", from, to, err)
+		}
+
+		var flow int
+		if flowBytes != nil {
+			flow, _ = strconv.Atoi(string(flowBytes)) // Error handling not needed since Itoa() was used when setting the flow, guaranteeing it was an integer.
+		}
+
+		updatedFlow, err := add(flow, flows[pair])
+		if err != nil {
+			return err
+		}
+
+		err = ctx.GetStub().PutState(flowKey, []byte(strconv.Itoa(updatedFlow)))
+		if err != nil {
+			return fmt.Errorf("failed to update state of smart contract for key %s: %v", flowKey, err)
+		}
+	}
+
+	return nil
+}
+
+// currentSettlementWindow returns the settlement window of the transaction
+func currentSettlementWindow(ctx contractapi.TransactionContextInterface) (string, error) {
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return "", fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	return txTimestamp.AsTime().UTC().Format(settlementWindowLayout), nil
+}
@@ -0,0 +1,75 @@
+package chaincode_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNetPositions(t *testing.T) {
+	n := newNetwork(t)
+	hdfcCustomer := n.openAccount(hdfcMSP, 1, 0, "", 5000)
+	hdfcMerchant := n.openAccount(hdfcMSP, 2, 0, "", 0)
+	axisCustomer := n.openAccount(axisMSP, 3, 0, "", 5000)
+
+	n.transfer(hdfcMSP, hdfcCustomer, axisCustomer, 3000)
+	n.transfer(axisMSP, axisCustomer, hdfcCustomer, 1000)
+	n.transfer(hdfcMSP, hdfcCustomer, hdfcMerchant, 500)
+
+	tests := []struct {
+		name    string
+		window  string
+		want    *chaincode.NetPositions
+		wantErr string
+	}{
+		{
+			name:   "current window",
+			window: "",
+			want: &chaincode.NetPositions{
+				Window: "2023-11-14",
+				Gross: []*chaincode.InterbankFlow{
+					{From: axisMSP, To: hdfcMSP, Amount: 1000},
+					{From: hdfcMSP, To: axisMSP, Amount: 3000},
+				},
+				Bilateral: []*chaincode.BilateralPosition{
+					{Debtor: hdfcMSP, Creditor: axisMSP, Amount: 2000},
+				},
+				Multilateral: []*chaincode.BankPosition{
+					{Bank: axisMSP, Amount: 2000},
+					{Bank: hdfcMSP, Amount: -2000},
+				},
+			},
+		},
+		{
+			name:   "window without flows",
+			window: "2023-11-15",
+			want: &chaincode.NetPositions{
+				Window:    "2023-11-15",
+				Gross:     []*chaincode.InterbankFlow{},
+				Bilateral: []*chaincode.BilateralPosition{},
+				Multilateral: []*chaincode.BankPosition{
+					{Bank: axisMSP, Amount: 0},
+					{Bank: hdfcMSP, Amount: 0},
+				},
+			},
+		},
+		{
+			name:    "invalid window",
+			window:  "14/11/2023",
+			wantErr: "settlement window must be a date",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			positions, err := n.contract.GetNetPositions(n.ctx, tt.window)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, positions)
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package chaincode
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// Define objectType names for the nonces of signed transfers
+const signedNoncePrefix = "signednonce"
+
+// SignedTransfer is the payload a self-custodial account holder signs to authorize a debit
+// Channel and Chaincode name the deployment the payload is meant for, so a signature cannot be replayed on another one
+// Expiry is a unix timestamp in seconds after which the payload cannot be submitted
+// Nonce must be greater than the nonce of the last signed transfer of the account, e.g. a timestamp in nanoseconds
+type SignedTransfer struct {
+	Channel   string `json:"channel"`
+	Chaincode string `json:"chaincode"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Amount    int    `json:"amount"`
+	Nonce     uint64 `json:"nonce"`
+	Expiry    int64  `json:"expiry"`
+}
+
+// RegisterSelfCustodialAccount registers a retail account of the calling commercial bank debited only by its holder's signatures
+// param {String} account The address of the account, derived from the public key
+// param {String} publicKey The ECDSA P-256 or Ed25519 public key of the holder as base64 of its DER encoded SubjectPublicKeyInfo
+func (s *SmartContract) RegisterSelfCustodialAccount(ctx contractapi.TransactionContextInterface, account string, kycTier int, publicKey string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	// Only commercial banks onboard retail customers
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	isBank, err := isCommercialBank(ctx, clientMSPID)
+	if err != nil {
+		return err
+	}
+	if !isBank {
+		return fmt.Errorf("%w: client with id %s is not authorized to register accounts", ErrUnauthorized, clientMSPID)
+	}
+
+	if kycTier < 0 {
+		return fmt.Errorf("%w: kyc tier cannot be negative", ErrInvalidArgument)
+	}
+
+	_, err = parsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	// The address of a self-custodial account is derived from the public key of its holder
+	address, err := addressFromPublicKey(ctx, clientMSPID, publicKey)
+	if err != nil {
+		return err
+	}
+	if account != address {
+		return fmt.Errorf("the address of the public key is %s", address)
+	}
+
+	acc, err := newAccount(ctx, account, clientMSPID, accountTypeRetail, kycTier, "")
+	if err != nil {
+		return err
+	}
+	acc.PublicKey = publicKey
+
+	err = putAccount(ctx, acc)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("self-custodial account %s registered for %s", account, clientMSPID)
+
+	return nil
+}
+
+// TransferSigned transfers funds from a self-custodial account as authorized by the signature of its holder
+// Any client can relay the transfer, the signature is checked against the public key of the "from" account
+// param {String} payload The SignedTransfer as the JSON signed by the holder
+// param {String} signature The base64 signature of the payload: ASN.1 ECDSA over its SHA-256 digest, or Ed25519 over the payload itself
+// This function triggers a Transfer event
+func (s *SmartContract) TransferSigned(ctx contractapi.TransactionContextInterface, payload string, signature string) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	var transfer SignedTransfer
+	err = json.Unmarshal([]byte(payload), &transfer)
+	if err != nil {
+		return fmt.Errorf("failed to parse signed transfer: %v", err)
+	}
+	if transfer.Amount <= 0 {
+		return fmt.Errorf("%w: transfer amount must be a positive integer", ErrInvalidArgument)
+	}
+
+	fromAccount, err := getAccount(ctx, transfer.From)
+	if err != nil {
+		return err
+	}
+	if fromAccount == nil || fromAccount.PublicKey == "" {
+		return fmt.Errorf("%s is not a self-custodial account", transfer.From)
+	}
+
+	err = checkSignedDomain(ctx, transfer.Channel, transfer.Chaincode)
+	if err != nil {
+		return err
+	}
+
+	err = verifySignature(fromAccount.PublicKey, []byte(payload), signature)
+	if err != nil {
+		return err
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	if txTimestamp.GetSeconds() > transfer.Expiry {
+		return fmt.Errorf("the signed transfer expired at %d", transfer.Expiry)
+	}
+
+	// A signed payload can only be submitted once
+	nonceKey, err := ctx.GetStub().CreateCompositeKey(signedNoncePrefix, []string{transfer.From})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", signedNoncePrefix, err)
+	}
+	lastNonce, err := getSignedNonce(ctx, nonceKey)
+	if err != nil {
+		return err
+	}
+	if transfer.Nonce <= lastNonce {
+		return fmt.Errorf("%w: nonce %d of the signed transfer must be greater than %d", ErrInvalidArgument, transfer.Nonce, lastNonce)
+	}
+	err = ctx.GetStub().PutState(nonceKey, []byte(strconv.FormatUint(transfer.Nonce, 10)))
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", nonceKey, err)
+	}
+
+	// Initiate the transfer
+	err = transferHelper(ctx, transfer.From, transfer.To, transfer.Amount)
+	if err != nil {
+		return fmt.Errorf("failed to transfer: %v", err)
+	}
+
+	// Record the transfer in the history of the accounts
+	err = recordTransfer(ctx, transfer.From, transfer.To, transfer.Amount)
+	if err != nil {
+		return err
+	}
+
+	// Emit the Transfer event
+	transferEvent := event{transfer.From, transfer.To, transfer.Amount}
+	transferEventJSON, err := json.Marshal(transferEvent)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	return nil
+}
+
+// GetSignedNonce returns the nonce of the last signed transfer of an account, 0 if there was none
+func (s *SmartContract) GetSignedNonce(ctx contractapi.TransactionContextInterface, account string) (uint64, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return 0, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	nonceKey, err := ctx.GetStub().CreateCompositeKey(signedNoncePrefix, []string{account})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create the composite key for prefix %s: %v", signedNoncePrefix, err)
+	}
+
+	return getSignedNonce(ctx, nonceKey)
+}
+
+// getSignedNonce reads the nonce of the last signed transfer stored under nonceKey
+func getSignedNonce(ctx contractapi.TransactionContextInterface, nonceKey string) (uint64, error) {
+
+	nonceBytes, err := ctx.GetStub().GetState(nonceKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read nonce from world state: %v", err)
+	}
+	if nonceBytes == nil {
+		return 0, nil
+	}
+
+	nonce, _ := strconv.ParseUint(string(nonceBytes), 10, 64) // Error handling not needed since FormatUint() was used when setting the nonce, guaranteeing it was an integer.
+
+	return nonce, nil
+}
+
+// checkSignedDomain checks that a signed payload names the channel and chaincode processing the transaction
+func checkSignedDomain(ctx contractapi.TransactionContextInterface, channel string, chaincode string) error {
+
+	if channel != ctx.GetStub().GetChannelID() {
+		return fmt.Errorf("the payload is signed for channel %q, not %q", channel, ctx.GetStub().GetChannelID())
+	}
+
+	name, err := getChaincodeName(ctx)
+	if err != nil {
+		return err
+	}
+	if chaincode != name {
+		return fmt.Errorf("the payload is signed for chaincode %q, not %q", chaincode, name)
+	}
+
+	return nil
+}
+
+// getChaincodeName reads the name of the invoked chaincode from the signed proposal of the transaction
+func getChaincodeName(ctx contractapi.TransactionContextInterface) (string, error) {
+
+	signedProposal, err := ctx.GetStub().GetSignedProposal()
+	if err != nil {
+		return "", fmt.Errorf("failed to get signed proposal: %v", err)
+	}
+	if signedProposal == nil {
+		return "", fmt.Errorf("the transaction has no signed proposal")
+	}
+
+	var proposal peer.Proposal
+	err = proto.Unmarshal(signedProposal.GetProposalBytes(), &proposal)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse proposal: %v", err)
+	}
+	var payload peer.ChaincodeProposalPayload
+	err = proto.Unmarshal(proposal.GetPayload(), &payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse proposal payload: %v", err)
+	}
+	var invocation peer.ChaincodeInvocationSpec
+	err = proto.Unmarshal(payload.GetInput(), &invocation)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse chaincode invocation: %v", err)
+	}
+
+	return invocation.GetChaincodeSpec().GetChaincodeId().GetName(), nil
+}
+
+// parsePublicKey decodes a base64 DER public key, accepting ECDSA P-256 and Ed25519 keys
+func parsePublicKey(publicKey string) (any, error) {
+
+	der, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: public key must be base64 encoded: %v", ErrInvalidArgument, err)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %v", err)
+	}
+
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: ECDSA public keys must be on the P-256 curve", ErrInvalidArgument)
+		}
+	case ed25519.PublicKey:
+	default:
+		return nil, fmt.Errorf("%w: public key must be an ECDSA P-256 or Ed25519 key", ErrInvalidArgument)
+	}
+
+	return key, nil
+}
+
+// verifySignature checks a base64 signature of payload by the holder of a base64 DER public key
+func verifySignature(publicKey string, payload []byte, signature string) error {
+
+	key, err := parsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("%w: signature must be base64 encoded: %v", ErrInvalidArgument, err)
+	}
+
+	valid := false
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(payload)
+		valid = ecdsa.VerifyASN1(key, digest[:], sig)
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, payload, sig)
+	}
+	if !valid {
+		return fmt.Errorf("the signature does not match the public key of the account")
+	}
+
+	return nil
+}
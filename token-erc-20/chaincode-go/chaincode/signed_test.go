@@ -0,0 +1,317 @@
+package chaincode_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// signer is the holder of a key signing payloads as TransferSigned expects
+type signer struct {
+	publicKey string
+	sign      func(payload []byte) string
+}
+
+// newSigner generates a key of the given type, "P-256" or "Ed25519"
+func newSigner(t *testing.T, keyType string) *signer {
+	var key crypto.Signer
+	var err error
+	switch keyType {
+	case "P-256":
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "Ed25519":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	}
+	require.NoError(t, err)
+
+	return &signer{
+		publicKey: publicKeyOf(t, key.Public()),
+		sign: func(payload []byte) string {
+			// ECDSA signs the SHA-256 digest of the payload, Ed25519 the payload itself
+			message, opts := payload, crypto.SignerOpts(crypto.Hash(0))
+			if keyType == "P-256" {
+				digest := sha256.Sum256(payload)
+				message, opts = digest[:], crypto.SHA256
+			}
+			signature, err := key.Sign(rand.Reader, message, opts)
+			require.NoError(t, err)
+			return base64.StdEncoding.EncodeToString(signature)
+		},
+	}
+}
+
+// publicKeyOf encodes a public key as base64 of its DER SubjectPublicKeyInfo
+func publicKeyOf(t *testing.T, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+// keyAddress derives the address of a self-custodial account of a bank from the public key of its holder
+func keyAddress(prefix string, publicKey string) string {
+	der, _ := base64.StdEncoding.DecodeString(publicKey)
+	digest := sha256.Sum256(der)
+	address := prefix + "1" + hex.EncodeToString(digest[:20])
+	checksum := sha256.Sum256([]byte(address))
+	return address + hex.EncodeToString(checksum[:4])
+}
+
+// invokeChaincode makes the next transactions invocations of the named chaincode
+func (n *network) invokeChaincode(name string) {
+	input, err := proto.Marshal(&peer.ChaincodeInvocationSpec{ChaincodeSpec: &peer.ChaincodeSpec{ChaincodeId: &peer.ChaincodeID{Name: name}}})
+	require.NoError(n.t, err)
+	payload, err := proto.Marshal(&peer.ChaincodeProposalPayload{Input: input})
+	require.NoError(n.t, err)
+	proposal, err := proto.Marshal(&peer.Proposal{Payload: payload})
+	require.NoError(n.t, err)
+	n.stub.SignedProposal = &peer.SignedProposal{ProposalBytes: proposal}
+}
+
+// openSelfCustodialAccount registers a self-custodial account of HDFC for the holder of a key and funds it
+func (n *network) openSelfCustodialAccount(holder *signer, funds int) string {
+	n.t.Helper()
+	account := keyAddress("hdfc", holder.publicKey)
+	n.ok(hdfcMSP, func() error { return n.contract.RegisterSelfCustodialAccount(n.ctx, account, 0, holder.publicKey) })
+	n.transfer(hdfcMSP, hdfcReserve, account, funds)
+	return account
+}
+
+// signedTransfer is a transfer of the "retail" channel and "cbdc" chaincode valid for an hour
+func (n *network) signedTransfer(from string, to string, amount int, nonce uint64) chaincode.SignedTransfer {
+	return chaincode.SignedTransfer{
+		Channel:   "retail",
+		Chaincode: "cbdc",
+		From:      from,
+		To:        to,
+		Amount:    amount,
+		Nonce:     nonce,
+		Expiry:    n.now() + int64(time.Hour.Seconds()),
+	}
+}
+
+// payloadOf encodes a signed transfer as the JSON its holder signs
+func payloadOf(t *testing.T, transfer chaincode.SignedTransfer) []byte {
+	payload, err := json.Marshal(transfer)
+	require.NoError(t, err)
+	return payload
+}
+
+func TestTransferSigned(t *testing.T) {
+	for _, keyType := range []string{"P-256", "Ed25519"} {
+		t.Run(keyType, func(t *testing.T) {
+			n := newNetwork(t)
+			n.invokeChaincode("cbdc")
+			holder := newSigner(t, keyType)
+			from := n.openSelfCustodialAccount(holder, 1000)
+			to := n.openAccount(axisMSP, 2, 0, "", 0)
+
+			payload := payloadOf(t, n.signedTransfer(from, to, 300, 7))
+
+			// Any client can relay the signed transfer
+			n.ok(axisMSP, func() error { return n.contract.TransferSigned(n.ctx, string(payload), holder.sign(payload)) })
+			require.Equal(t, 700, n.balance(from))
+			require.Equal(t, 300, n.balance(to))
+			require.Equal(t, "Transfer", n.stub.EventName)
+			nonce, err := n.contract.GetSignedNonce(n.ctx, from)
+			require.NoError(t, err)
+			require.Equal(t, uint64(7), nonce)
+
+			// The bank of the account cannot debit it without a signature
+			err = n.submit(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, from, to, 100, "") })
+			require.ErrorContains(t, err, "its debits must be signed by its holder")
+			require.Equal(t, 700, n.balance(from))
+		})
+	}
+}
+
+func TestTransferSignedRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(transfer *chaincode.SignedTransfer)
+		sign    func(holder *signer, other *signer, payload []byte) string
+		wantErr string
+	}{
+		{
+			name:    "signature of another key",
+			sign:    func(holder *signer, other *signer, payload []byte) string { return other.sign(payload) },
+			wantErr: "the signature does not match the public key of the account",
+		},
+		{
+			name: "payload changed after signing",
+			sign: func(holder *signer, other *signer, payload []byte) string {
+				var transfer chaincode.SignedTransfer
+				_ = json.Unmarshal(payload, &transfer)
+				transfer.Amount = 1
+				changed, _ := json.Marshal(transfer)
+				return holder.sign(changed)
+			},
+			wantErr: "the signature does not match the public key of the account",
+		},
+		{
+			name:    "signature not base64 encoded",
+			sign:    func(holder *signer, other *signer, payload []byte) string { return "not base64!" },
+			wantErr: "signature must be base64 encoded",
+		},
+		{
+			name:    "signed for another channel",
+			change:  func(transfer *chaincode.SignedTransfer) { transfer.Channel = "wholesale" },
+			wantErr: `the payload is signed for channel "wholesale", not "retail"`,
+		},
+		{
+			name:    "signed for another chaincode",
+			change:  func(transfer *chaincode.SignedTransfer) { transfer.Chaincode = "cbdc-test" },
+			wantErr: `the payload is signed for chaincode "cbdc-test", not "cbdc"`,
+		},
+		{
+			name:    "expired",
+			change:  func(transfer *chaincode.SignedTransfer) { transfer.Expiry = 1700000000 },
+			wantErr: "the signed transfer expired at 1700000000",
+		},
+		{
+			name:    "nonce not above the last one",
+			change:  func(transfer *chaincode.SignedTransfer) { transfer.Nonce = 5 },
+			wantErr: "nonce 5 of the signed transfer must be greater than 5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNetwork(t)
+			n.invokeChaincode("cbdc")
+			holder, other := newSigner(t, "P-256"), newSigner(t, "Ed25519")
+			from := n.openSelfCustodialAccount(holder, 1000)
+			to := n.openAccount(axisMSP, 2, 0, "", 0)
+
+			// A first transfer sets the nonce of the account to 5
+			first := payloadOf(t, n.signedTransfer(from, to, 100, 5))
+			n.ok(axisMSP, func() error { return n.contract.TransferSigned(n.ctx, string(first), holder.sign(first)) })
+
+			transfer := n.signedTransfer(from, to, 300, 6)
+			if tt.change != nil {
+				tt.change(&transfer)
+			}
+			payload := payloadOf(t, transfer)
+			signature := holder.sign(payload)
+			if tt.sign != nil {
+				signature = tt.sign(holder, other, payload)
+			}
+
+			err := n.submit(axisMSP, func() error { return n.contract.TransferSigned(n.ctx, string(payload), signature) })
+			require.ErrorContains(t, err, tt.wantErr)
+			require.Equal(t, 900, n.balance(from))
+		})
+	}
+}
+
+func TestTransferSignedReplay(t *testing.T) {
+	n := newNetwork(t)
+	n.invokeChaincode("cbdc")
+	holder := newSigner(t, "Ed25519")
+	from := n.openSelfCustodialAccount(holder, 1000)
+	to := n.openAccount(axisMSP, 2, 0, "", 0)
+
+	payload := payloadOf(t, n.signedTransfer(from, to, 300, 1))
+	signature := holder.sign(payload)
+	n.ok(axisMSP, func() error { return n.contract.TransferSigned(n.ctx, string(payload), signature) })
+
+	err := n.submit(hdfcMSP, func() error { return n.contract.TransferSigned(n.ctx, string(payload), signature) })
+	require.ErrorContains(t, err, "nonce 1 of the signed transfer must be greater than 1")
+	require.Equal(t, 700, n.balance(from))
+
+	// A signature only authorizes debits of the self-custodial account it was registered for
+	custodial := n.openAccount(hdfcMSP, 3, 0, "", 1000)
+	payload = payloadOf(t, n.signedTransfer(custodial, to, 300, 2))
+	err = n.submit(axisMSP, func() error { return n.contract.TransferSigned(n.ctx, string(payload), holder.sign(payload)) })
+	require.ErrorContains(t, err, custodial+" is not a self-custodial account")
+}
+
+func TestRegisterSelfCustodialAccount(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	holder := newSigner(t, "P-256")
+
+	tests := []struct {
+		name      string
+		msp       string
+		account   func(publicKey string) string
+		publicKey string
+		wantErr   string
+	}{
+		{
+			name:      "P-256 key",
+			msp:       hdfcMSP,
+			publicKey: holder.publicKey,
+		},
+		{
+			name:      "RSA key",
+			msp:       hdfcMSP,
+			publicKey: publicKeyOf(t, &rsaKey.PublicKey),
+			wantErr:   "public key must be an ECDSA P-256 or Ed25519 key",
+		},
+		{
+			name:      "ECDSA key on another curve",
+			msp:       hdfcMSP,
+			publicKey: publicKeyOf(t, &p384Key.PublicKey),
+			wantErr:   "ECDSA public keys must be on the P-256 curve",
+		},
+		{
+			name:      "key not base64 encoded",
+			msp:       hdfcMSP,
+			account:   func(publicKey string) string { return testAddress("hdfc", 1) },
+			publicKey: "not base64!",
+			wantErr:   "public key must be base64 encoded",
+		},
+		{
+			name:      "address of another key",
+			msp:       hdfcMSP,
+			account:   func(publicKey string) string { return testAddress("hdfc", 1) },
+			publicKey: holder.publicKey,
+			wantErr:   "the address of the public key is " + keyAddress("hdfc", holder.publicKey),
+		},
+		{
+			name:      "address of another bank",
+			msp:       axisMSP,
+			publicKey: holder.publicKey,
+			wantErr:   "the address of the public key is " + keyAddress("axis", holder.publicKey),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNetwork(t)
+			account := keyAddress("hdfc", tt.publicKey)
+			if tt.account != nil {
+				account = tt.account(tt.publicKey)
+			}
+
+			err := n.submit(tt.msp, func() error {
+				return n.contract.RegisterSelfCustodialAccount(n.ctx, account, 0, tt.publicKey)
+			})
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			acc, err := n.contract.GetAccount(n.ctx, account)
+			require.NoError(t, err)
+			require.Equal(t, tt.publicKey, acc.PublicKey)
+		})
+	}
+}
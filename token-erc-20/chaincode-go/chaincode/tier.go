@@ -0,0 +1,226 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Define objectType names for KYC tier limits
+const tierPrefix = "tier"
+const outflowPrefix = "outflow"
+
+// Errors returned when a transfer breaches the limits of a KYC tier
+var (
+	ErrMaxBalanceExceeded     = errors.New("tier holding limit exceeded")
+	ErrMaxTransferExceeded    = errors.New("tier per-transaction limit exceeded")
+	ErrDailyOutflowExceeded   = errors.New("tier daily outflow limit exceeded")
+	ErrMonthlyOutflowExceeded = errors.New("tier monthly outflow limit exceeded")
+)
+
+// Tier holds the wallet limits applied to retail accounts of a KYC tier
+// A limit of 0 is not enforced
+type Tier struct {
+	ID             int `json:"id"`
+	MaxBalance     int `json:"maxBalance"`
+	MaxTransfer    int `json:"maxTransfer"`
+	DailyOutflow   int `json:"dailyOutflow"`
+	MonthlyOutflow int `json:"monthlyOutflow"`
+}
+
+// SetTier defines or replaces the limits of a KYC tier
+// param {Integer} maxBalance The maximum balance an account of the tier can hold
+// param {Integer} maxTransfer The maximum value of a single debit
+// param {Integer} dailyOutflow The maximum cumulative debits within a UTC day
+// param {Integer} monthlyOutflow The maximum cumulative debits within a UTC month
+func (s *SmartContract) SetTier(ctx contractapi.TransactionContextInterface, tier int, maxBalance int, maxTransfer int, dailyOutflow int, monthlyOutflow int) error {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	// Check central banker authorization
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	if clientMSPID != CentralBankerMSPId {
+		return fmt.Errorf("%w: client with id %s is not authorized to configure tiers", ErrUnauthorized, clientMSPID)
+	}
+
+	if tier < 0 {
+		return fmt.Errorf("%w: kyc tier cannot be negative", ErrInvalidArgument)
+	}
+	if maxBalance < 0 || maxTransfer < 0 || dailyOutflow < 0 || monthlyOutflow < 0 {
+		return fmt.Errorf("%w: tier limits cannot be negative", ErrInvalidArgument)
+	}
+
+	tierKey, err := ctx.GetStub().CreateCompositeKey(tierPrefix, []string{strconv.Itoa(tier)})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", tierPrefix, err)
+	}
+
+	tierJSON, err := json.Marshal(Tier{tier, maxBalance, maxTransfer, dailyOutflow, monthlyOutflow})
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(tierKey, tierJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", tierKey, err)
+	}
+
+	log.Printf("tier %d limits set to %s", tier, tierJSON)
+
+	return nil
+}
+
+// GetTier returns the limits of a KYC tier
+func (s *SmartContract) GetTier(ctx contractapi.TransactionContextInterface, tier int) (*Tier, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	t, err := getTier(ctx, tier)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("%w: the tier %d is not defined", ErrNotFound, tier)
+	}
+
+	return t, nil
+}
+
+// getTier reads the limits of a KYC tier, returning nil if the tier is not defined
+func getTier(ctx contractapi.TransactionContextInterface, tier int) (*Tier, error) {
+
+	tierKey, err := ctx.GetStub().CreateCompositeKey(tierPrefix, []string{strconv.Itoa(tier)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the composite key for prefix %s: %v", tierPrefix, err)
+	}
+
+	tierBytes, err := ctx.GetStub().GetState(tierKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tier %d from world state: %v", tier, err)
+	}
+	if tierBytes == nil {
+		return nil, nil
+	}
+
+	var t Tier
+	err = json.Unmarshal(tierBytes, &t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tier %d: %v", tier, err)
+	}
+
+	return &t, nil
+}
+
+// getAccountTier returns the tier limits that apply to an account, or nil if the account is not subject to any
+func getAccountTier(ctx contractapi.TransactionContextInterface, acc *Account) (*Tier, error) {
+	if acc == nil || acc.Type != accountTypeRetail {
+		return nil, nil
+	}
+	return getTier(ctx, acc.KYCTier)
+}
+
+// enforceHoldingLimit checks that the updated balance of an account stays within its tier holding limit
+func enforceHoldingLimit(ctx contractapi.TransactionContextInterface, acc *Account, updatedBalance *big.Int) error {
+
+	tier, err := getAccountTier(ctx, acc)
+	if err != nil {
+		return err
+	}
+	if tier == nil || tier.MaxBalance == 0 {
+		return nil
+	}
+
+	if updatedBalance.Cmp(big.NewInt(int64(tier.MaxBalance))) > 0 {
+		return fmt.Errorf("%w: account %s would hold %d, tier %d allows %d", ErrMaxBalanceExceeded, acc.ID, updatedBalance, tier.ID, tier.MaxBalance)
+	}
+
+	return nil
+}
+
+// enforceOutflowLimits checks a debit against the per-transaction, daily and monthly limits of the account's tier
+// and records the debit against the cumulative daily and monthly outflow
+func enforceOutflowLimits(ctx contractapi.TransactionContextInterface, acc *Account, value int) error {
+
+	tier, err := getAccountTier(ctx, acc)
+	if err != nil {
+		return err
+	}
+	if tier == nil {
+		return nil
+	}
+
+	if tier.MaxTransfer != 0 && value > tier.MaxTransfer {
+		return fmt.Errorf("%w: transfer of %d from account %s, tier %d allows %d", ErrMaxTransferExceeded, value, acc.ID, tier.ID, tier.MaxTransfer)
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	txTime := txTimestamp.AsTime().UTC()
+
+	err = addOutflow(ctx, acc, txTime.Format(time.DateOnly), value, tier.DailyOutflow, ErrDailyOutflowExceeded)
+	if err != nil {
+		return err
+	}
+
+	return addOutflow(ctx, acc, txTime.Format("2006-01"), value, tier.MonthlyOutflow, ErrMonthlyOutflowExceeded)
+}
+
+// addOutflow adds a debit to the cumulative outflow of an account within a period, failing with limitErr if the limit is breached
+func addOutflow(ctx contractapi.TransactionContextInterface, acc *Account, period string, value int, limit int, limitErr error) error {
+
+	outflowKey, err := ctx.GetStub().CreateCompositeKey(outflowPrefix, []string{acc.ID, period})
+	if err != nil {
+		return fmt.Errorf("failed to create the composite key for prefix %s: %v", outflowPrefix, err)
+	}
+
+	outflowBytes, err := ctx.GetStub().GetState(outflowKey)
+	if err != nil {
+		return fmt.Errorf("failed to read outflow of account %s from world state: %v", acc.ID, err)
+	}
+
+	var outflow int
+	if outflowBytes != nil {
+		outflow, _ = strconv.Atoi(string(outflowBytes)) // Error handling not needed since Itoa() was used when setting the outflow, guaranteeing it was an integer.
+	}
+
+	updatedOutflow, err := add(outflow, value)
+	if err != nil {
+		return err
+	}
+
+	if limit != 0 && updatedOutflow > limit {
+		return fmt.Errorf("%w: account %s would send %d in %s, tier allows %d", limitErr, acc.ID, updatedOutflow, period, limit)
+	}
+
+	err = ctx.GetStub().PutState(outflowKey, []byte(strconv.Itoa(updatedOutflow)))
+	if err != nil {
+		return fmt.Errorf("failed to update state of smart contract for key %s: %v", outflowKey, err)
+	}
+
+	return nil
+}
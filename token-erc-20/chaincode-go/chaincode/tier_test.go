@@ -0,0 +1,101 @@
+package chaincode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferTierLimits(t *testing.T) {
+	type debit struct {
+		after  time.Duration
+		amount int
+	}
+
+	tests := []struct {
+		name       string
+		payeeFunds int
+		earlier    []debit
+		after      time.Duration
+		amount     int
+		wantErr    string
+	}{
+		{
+			name:   "within limits",
+			amount: 10000,
+		},
+		{
+			name:    "per-transaction limit",
+			amount:  10001,
+			wantErr: chaincode.ErrMaxTransferExceeded.Error(),
+		},
+		{
+			name:    "daily outflow limit",
+			earlier: []debit{{0, 10000}},
+			amount:  6000,
+			wantErr: chaincode.ErrDailyOutflowExceeded.Error(),
+		},
+		{
+			name:    "daily outflow resets the next day",
+			earlier: []debit{{0, 10000}},
+			after:   24 * time.Hour,
+			amount:  6000,
+		},
+		{
+			name:    "monthly outflow limit",
+			earlier: []debit{{0, 10000}, {24 * time.Hour, 10000}, {24 * time.Hour, 10000}, {24 * time.Hour, 10000}},
+			after:   24 * time.Hour,
+			amount:  1,
+			wantErr: chaincode.ErrMonthlyOutflowExceeded.Error(),
+		},
+		{
+			name:       "holding limit of the payee",
+			payeeFunds: 45000,
+			amount:     10000,
+			wantErr:    chaincode.ErrMaxBalanceExceeded.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNetwork(t)
+			n.ok(centralBankMSP, func() error { return n.contract.SetTier(n.ctx, 1, 50000, 10000, 15000, 40000) })
+			payer := n.openAccount(hdfcMSP, 1, 1, "", 45000)
+			payee := n.openAccount(axisMSP, 2, 1, "", tt.payeeFunds)
+
+			for _, d := range tt.earlier {
+				n.wait(d.after)
+				n.transfer(hdfcMSP, payer, payee, d.amount)
+			}
+			n.wait(tt.after)
+
+			err := n.submit(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, payer, payee, tt.amount, "") })
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSetTier(t *testing.T) {
+	n := newNetwork(t)
+
+	err := n.submit(hdfcMSP, func() error { return n.contract.SetTier(n.ctx, 1, 50000, 10000, 15000, 40000) })
+	require.ErrorContains(t, err, "not authorized")
+
+	// Limits only apply to retail accounts of a defined tier
+	payer := n.openAccount(hdfcMSP, 1, 1, "", 45000)
+	payee := n.openAccount(axisMSP, 2, 1, "", 0)
+	n.transfer(hdfcMSP, payer, payee, 20000)
+
+	n.ok(centralBankMSP, func() error { return n.contract.SetTier(n.ctx, 1, 50000, 10000, 15000, 40000) })
+	err = n.submit(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, payer, payee, 20000, "") })
+	require.ErrorContains(t, err, chaincode.ErrMaxTransferExceeded.Error())
+
+	// Reserve accounts are not limited
+	n.transfer(hdfcMSP, hdfcReserve, axisReserve, 100000)
+}
@@ -2,10 +2,9 @@ package chaincode
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
-	"slices"
+	"math/big"
 	"strconv"
 
 	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
@@ -23,10 +22,6 @@ const allowancePrefix = "allowance"
 // Define Access control parameters
 const CentralBankerMSPId = "RBIMSP"
 
-func getCommercialBankMSPIds() []string {
-	return []string{"HDFCBankMSP", "AxisBankMSP"}
-}
-
 // SmartContract provides functions for transferring tokens between accounts
 type SmartContract struct {
 	contractapi.Contract
@@ -40,6 +35,7 @@ type event struct {
 }
 
 // Mint creates new tokens and adds them to minter's account balance
+// Like every amount in this contract, the amount is given in minor units of the token, see Decimals()
 // This function triggers a Transfer event
 func (s *SmartContract) Mint(ctx contractapi.TransactionContextInterface, amount int) error {
 
@@ -58,7 +54,7 @@ func (s *SmartContract) Mint(ctx contractapi.TransactionContextInterface, amount
 		return fmt.Errorf("failed to get MSPID: %v", err)
 	}
 	if clientMSPID != CentralBankerMSPId {
-		return fmt.Errorf("client is not authorized to mint new tokens")
+		return fmt.Errorf("%w: client is not authorized to mint new tokens", ErrUnauthorized)
 	}
 
 	// Get ID of submitting client identity
@@ -67,74 +63,46 @@ func (s *SmartContract) Mint(ctx contractapi.TransactionContextInterface, amount
 		return fmt.Errorf("failed to get client id: %v", err)
 	}
 
-	if amount <= 0 {
-		return fmt.Errorf("mint amount must be a positive integer")
-	}
-
-	currentBalanceBytes, err := ctx.GetStub().GetState(minter)
-	if err != nil {
-		return fmt.Errorf("failed to read minter account %s from world state: %v", minter, err)
-	}
-
-	var currentBalance int
+	return mintHelper(ctx, minter, amount)
+}
 
-	// If minter current balance doesn't yet exist, we'll create it with a current balance of 0
-	if currentBalanceBytes == nil {
-		currentBalance = 0
-	} else {
-		currentBalance, _ = strconv.Atoi(string(currentBalanceBytes)) // Error handling not needed since Itoa() was used when setting the account balance, guaranteeing it was an integer.
-	}
+// MintTo creates new tokens and adds them to the balance of a commercial bank reserve account
+// The reserve account and the total supply are updated in a single transaction
+// This function triggers a Transfer event
+func (s *SmartContract) MintTo(ctx contractapi.TransactionContextInterface, account string, amount int) error {
 
-	updatedBalance, err := add(currentBalance, amount)
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
 	}
-
-	err = ctx.GetStub().PutState(minter, []byte(strconv.Itoa(updatedBalance)))
-	if err != nil {
-		return err
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
 	}
 
-	// Update the totalSupply
-	totalSupplyBytes, err := ctx.GetStub().GetState(totalSupplyKey)
+	// Check minter authorization
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
 	if err != nil {
-		return fmt.Errorf("failed to retrieve total token supply: %v", err)
-	}
-
-	var totalSupply int
-
-	// If no tokens have been minted, initialize the totalSupply
-	if totalSupplyBytes == nil {
-		totalSupply = 0
-	} else {
-		totalSupply, _ = strconv.Atoi(string(totalSupplyBytes)) // Error handling not needed since Itoa() was used when setting the totalSupply, guaranteeing it was an integer.
+		return fmt.Errorf("failed to get MSPID: %v", err)
 	}
-
-	// Add the mint amount to the total supply and update the state
-	totalSupply, err = add(totalSupply, amount)
-	if err != nil {
-		return err
+	if clientMSPID != CentralBankerMSPId {
+		return fmt.Errorf("%w: client is not authorized to mint new tokens", ErrUnauthorized)
 	}
 
-	err = ctx.GetStub().PutState(totalSupplyKey, []byte(strconv.Itoa(totalSupply)))
+	// New tokens are only issued into bank reserves
+	acc, err := getAccount(ctx, account)
 	if err != nil {
 		return err
 	}
-
-	// Emit the Transfer event
-	transferEvent := event{"0x0", minter, amount}
-	transferEventJSON, err := json.Marshal(transferEvent)
-	if err != nil {
-		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	if acc == nil || acc.Type != accountTypeReserve {
+		return fmt.Errorf("%s is not a reserve account", account)
 	}
-	err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
+	err = checkCreditAllowed(acc)
 	if err != nil {
-		return fmt.Errorf("failed to set event: %v", err)
+		return err
 	}
 
-	log.Printf("minter account %s balance updated from %d to %d", minter, currentBalance, updatedBalance)
-
-	return nil
+	return mintHelper(ctx, account, amount)
 }
 
 // Burn redeems tokens the minter's account balance
@@ -155,7 +123,7 @@ func (s *SmartContract) Burn(ctx contractapi.TransactionContextInterface, amount
 		return fmt.Errorf("failed to get MSPID: %v", err)
 	}
 	if clientMSPID != CentralBankerMSPId {
-		return fmt.Errorf("client is not authorized to mint new tokens")
+		return fmt.Errorf("%w: client is not authorized to mint new tokens", ErrUnauthorized)
 	}
 
 	// Get ID of submitting client identity
@@ -164,78 +132,58 @@ func (s *SmartContract) Burn(ctx contractapi.TransactionContextInterface, amount
 		return fmt.Errorf("failed to get client id: %v", err)
 	}
 
-	if amount <= 0 {
-		return errors.New("burn amount must be a positive integer")
-	}
-
-	currentBalanceBytes, err := ctx.GetStub().GetState(minter)
-	if err != nil {
-		return fmt.Errorf("failed to read minter account %s from world state: %v", minter, err)
-	}
-
-	var currentBalance int
-
-	// Check if minter current balance exists
-	if currentBalanceBytes == nil {
-		return errors.New("The balance does not exist")
-	}
+	return burnHelper(ctx, minter, amount)
+}
 
-	currentBalance, _ = strconv.Atoi(string(currentBalanceBytes)) // Error handling not needed since Itoa() was used when setting the account balance, guaranteeing it was an integer.
+// BurnFrom redeems tokens from the balance of a commercial bank reserve account
+// The reserve account and the total supply are updated in a single transaction
+// This function triggers a Transfer event
+func (s *SmartContract) BurnFrom(ctx contractapi.TransactionContextInterface, account string, amount int) error {
 
-	updatedBalance, err := sub(currentBalance, amount)
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to check if contract is already initialized: %v", err)
 	}
-
-	err = ctx.GetStub().PutState(minter, []byte(strconv.Itoa(updatedBalance)))
-	if err != nil {
-		return err
+	if !initialized {
+		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
 	}
 
-	// Update the totalSupply
-	totalSupplyBytes, err := ctx.GetStub().GetState(totalSupplyKey)
+	// Check minter authorization
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
 	if err != nil {
-		return fmt.Errorf("failed to retrieve total token supply: %v", err)
+		return fmt.Errorf("failed to get MSPID: %v", err)
 	}
-
-	// If no tokens have been minted, throw error
-	if totalSupplyBytes == nil {
-		return errors.New("totalSupply does not exist")
+	if clientMSPID != CentralBankerMSPId {
+		return fmt.Errorf("%w: client is not authorized to burn tokens", ErrUnauthorized)
 	}
 
-	totalSupply, _ := strconv.Atoi(string(totalSupplyBytes)) // Error handling not needed since Itoa() was used when setting the totalSupply, guaranteeing it was an integer.
-
-	// Subtract the burn amount to the total supply and update the state
-	totalSupply, err = sub(totalSupply, amount)
+	// Tokens are only redeemed from bank reserves
+	acc, err := getAccount(ctx, account)
 	if err != nil {
 		return err
 	}
-
-	err = ctx.GetStub().PutState(totalSupplyKey, []byte(strconv.Itoa(totalSupply)))
-	if err != nil {
-		return err
+	if acc == nil || acc.Type != accountTypeReserve {
+		return fmt.Errorf("%s is not a reserve account", account)
 	}
 
-	// Emit the Transfer event
-	transferEvent := event{minter, "0x0", amount}
-	transferEventJSON, err := json.Marshal(transferEvent)
+	currentBalance, _, err := readBalance(ctx, account)
 	if err != nil {
-		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+		return err
 	}
-	err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
+	err = checkDebitAllowed(acc, currentBalance, amount)
 	if err != nil {
-		return fmt.Errorf("failed to set event: %v", err)
+		return err
 	}
 
-	log.Printf("minter account %s balance updated from %d to %d", minter, currentBalance, updatedBalance)
-
-	return nil
+	return burnHelper(ctx, account, amount)
 }
 
 // Transfer transfers tokens from client account to recipient account
-// recipient account must be a valid clientID as returned by the ClientID() function
+// recipient account must be registered with RegisterAccount or RegisterReserveAccount
+// paymentRef is an optional client supplied reference, a transfer reusing the reference of an earlier one is rejected
 // This function triggers a Transfer event
-func (s *SmartContract) Transfer(ctx contractapi.TransactionContextInterface, recipient string, amount int) error {
+func (s *SmartContract) Transfer(ctx contractapi.TransactionContextInterface, recipient string, amount int, paymentRef string) error {
 
 	// Check if contract has been intilized first
 	initialized, err := checkInitialized(ctx)
@@ -252,11 +200,22 @@ func (s *SmartContract) Transfer(ctx contractapi.TransactionContextInterface, re
 		return fmt.Errorf("failed to get client id: %v", err)
 	}
 
+	err = claimPaymentReference(ctx, clientID, paymentRef)
+	if err != nil {
+		return err
+	}
+
 	err = transferHelper(ctx, clientID, recipient, amount)
 	if err != nil {
 		return fmt.Errorf("failed to transfer: %v", err)
 	}
 
+	// Record the transfer in the history of the accounts
+	err = recordTransfer(ctx, clientID, recipient, amount)
+	if err != nil {
+		return err
+	}
+
 	// Emit the Transfer event
 	transferEvent := event{clientID, recipient, amount}
 	transferEventJSON, err := json.Marshal(transferEvent)
@@ -272,59 +231,57 @@ func (s *SmartContract) Transfer(ctx contractapi.TransactionContextInterface, re
 }
 
 // BalanceOf returns the balance of the given account
-func (s *SmartContract) BalanceOf(ctx contractapi.TransactionContextInterface, account string) (int, error) {
+// The balance is returned as a base 10 integer string as it is not bounded by the platform integer size
+func (s *SmartContract) BalanceOf(ctx contractapi.TransactionContextInterface, account string) (string, error) {
 
 	// Check if contract has been intilized first
 	initialized, err := checkInitialized(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+		return "", fmt.Errorf("failed to check if contract is already initialized: %v", err)
 	}
 	if !initialized {
-		return 0, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+		return "", fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
 	}
 
-	balanceBytes, err := ctx.GetStub().GetState(account)
+	balance, record, err := readBalance(ctx, account)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read from world state: %v", err)
+		return "", err
 	}
-	if balanceBytes == nil {
-		return 0, fmt.Errorf("the account %s does not exist", account)
+	if record == nil {
+		return "", fmt.Errorf("%w: the account %s does not exist", ErrNotFound, account)
 	}
 
-	balance, _ := strconv.Atoi(string(balanceBytes)) // Error handling not needed since Itoa() was used when setting the account balance, guaranteeing it was an integer.
-
-	return balance, nil
+	return balance.String(), nil
 }
 
 // ClientAccountBalance returns the balance of the requesting client's account
-func (s *SmartContract) ClientAccountBalance(ctx contractapi.TransactionContextInterface) (int, error) {
+// The balance is returned as a base 10 integer string as it is not bounded by the platform integer size
+func (s *SmartContract) ClientAccountBalance(ctx contractapi.TransactionContextInterface) (string, error) {
 
 	// Check if contract has been intilized first
 	initialized, err := checkInitialized(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+		return "", fmt.Errorf("failed to check if contract is already initialized: %v", err)
 	}
 	if !initialized {
-		return 0, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+		return "", fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
 	}
 
 	// Get ID of submitting client identity
 	clientID, err := ctx.GetClientIdentity().GetID()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get client id: %v", err)
+		return "", fmt.Errorf("failed to get client id: %v", err)
 	}
 
-	balanceBytes, err := ctx.GetStub().GetState(clientID)
+	balance, record, err := readBalance(ctx, clientID)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read from world state: %v", err)
+		return "", err
 	}
-	if balanceBytes == nil {
-		return 0, fmt.Errorf("the account %s does not exist", clientID)
+	if record == nil {
+		return "", fmt.Errorf("%w: the account %s does not exist", ErrNotFound, clientID)
 	}
 
-	balance, _ := strconv.Atoi(string(balanceBytes)) // Error handling not needed since Itoa() was used when setting the account balance, guaranteeing it was an integer.
-
-	return balance, nil
+	return balance.String(), nil
 }
 
 // ClientAccountID returns the id of the requesting client's account
@@ -351,35 +308,27 @@ func (s *SmartContract) ClientAccountID(ctx contractapi.TransactionContextInterf
 }
 
 // TotalSupply returns the total token supply
-func (s *SmartContract) TotalSupply(ctx contractapi.TransactionContextInterface) (int, error) {
+// The total supply is returned as a base 10 integer string as it is not bounded by the platform integer size
+func (s *SmartContract) TotalSupply(ctx contractapi.TransactionContextInterface) (string, error) {
 
 	// Check if contract has been intilized first
 	initialized, err := checkInitialized(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+		return "", fmt.Errorf("failed to check if contract is already initialized: %v", err)
 	}
 	if !initialized {
-		return 0, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+		return "", fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
 	}
 
-	// Retrieve total supply of tokens from state of smart contract
-	totalSupplyBytes, err := ctx.GetStub().GetState(totalSupplyKey)
+	// Retrieve total supply of tokens from state of smart contract, if no tokens have been minted it is 0
+	totalSupply, _, err := readBalance(ctx, totalSupplyKey)
 	if err != nil {
-		return 0, fmt.Errorf("failed to retrieve total token supply: %v", err)
-	}
-
-	var totalSupply int
-
-	// If no tokens have been minted, return 0
-	if totalSupplyBytes == nil {
-		totalSupply = 0
-	} else {
-		totalSupply, _ = strconv.Atoi(string(totalSupplyBytes)) // Error handling not needed since Itoa() was used when setting the totalSupply, guaranteeing it was an integer.
+		return "", fmt.Errorf("failed to retrieve total token supply: %v", err)
 	}
 
-	log.Printf("TotalSupply: %d tokens", totalSupply)
+	log.Printf("TotalSupply: %s tokens", totalSupply)
 
-	return totalSupply, nil
+	return totalSupply.String(), nil
 }
 
 // Approve allows the spender to withdraw from the calling client's token account
@@ -469,8 +418,10 @@ func (s *SmartContract) Allowance(ctx contractapi.TransactionContextInterface, o
 }
 
 // TransferFrom transfers the value amount from the "from" address to the "to" address
+// The caller must be the bank holding the custody mandate of the "from" account or have an allowance from its owner
+// paymentRef is an optional client supplied reference, a transfer reusing the reference of an earlier one is rejected
 // This function triggers a Transfer event
-func (s *SmartContract) TransferFrom(ctx contractapi.TransactionContextInterface, from string, to string, value int) error {
+func (s *SmartContract) TransferFrom(ctx contractapi.TransactionContextInterface, from string, to string, value int, paymentRef string) error {
 
 	// Check if contract has been intilized first
 	initialized, err := checkInitialized(ctx)
@@ -481,86 +432,39 @@ func (s *SmartContract) TransferFrom(ctx contractapi.TransactionContextInterface
 		return fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
 	}
 
-	// Get ID of submitting client identity
-	spender, err := ctx.GetClientIdentity().GetMSPID() //.GetID()
+	err = authorizeDebit(ctx, from, value)
 	if err != nil {
-		return fmt.Errorf("failed to get client id: %v", err)
+		return err
 	}
 
-	// Commercial Banks are allowed to spend on behalf of customers; no need for approval :)
-	// This is being done to keep things simple :)
-	if slices.Contains(getCommercialBankMSPIds(), spender) {
-		// Initiate the transfer
-		err = transferHelper(ctx, from, to, value)
-		if err != nil {
-			return fmt.Errorf("failed to transfer: %v", err)
-		}
-
-		// Emit the Transfer event
-		transferEvent := event{from, to, value}
-		transferEventJSON, err := json.Marshal(transferEvent)
-		if err != nil {
-			return fmt.Errorf("failed to obtain JSON encoding: %v", err)
-		}
-		err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
-		if err != nil {
-			return fmt.Errorf("failed to set event: %v", err)
-		}
-	} else {
-		return fmt.Errorf("Not allowed to transact from %v ", from)
+	err = claimPaymentReference(ctx, from, paymentRef)
+	if err != nil {
+		return err
 	}
 
-	/*
-		// Create allowanceKey
-		allowanceKey, err := ctx.GetStub().CreateCompositeKey(allowancePrefix, []string{from, spender})
-		if err != nil {
-			return fmt.Errorf("failed to create the composite key for prefix %s: %v", allowancePrefix, err)
-		}
-
-		// Retrieve the allowance of the spender
-		currentAllowanceBytes, err := ctx.GetStub().GetState(allowanceKey)
-		if err != nil {
-			return fmt.Errorf("failed to retrieve the allowance for %s from world state: %v", allowanceKey, err)
-		}
-
-		var currentAllowance int
-		currentAllowance, _ = strconv.Atoi(string(currentAllowanceBytes)) // Error handling not needed since Itoa() was used when setting the totalSupply, guaranteeing it was an integer.
-
-		// Check if transferred value is less than allowance
-		if currentAllowance < value {
-			return fmt.Errorf("spender does not have enough allowance for transfer")
-		}
-
-		// Initiate the transfer
-		err = transferHelper(ctx, from, to, value)
-		if err != nil {
-			return fmt.Errorf("failed to transfer: %v", err)
-		}
-
-		// Decrease the allowance
-		updatedAllowance, err := sub(currentAllowance, value)
-		if err != nil {
-			return err
-		}
+	// Initiate the transfer
+	err = transferHelper(ctx, from, to, value)
+	if err != nil {
+		return fmt.Errorf("failed to transfer: %v", err)
+	}
 
-		err = ctx.GetStub().PutState(allowanceKey, []byte(strconv.Itoa(updatedAllowance)))
-		if err != nil {
-			return err
-		}
+	// Record the transfer in the history of the accounts
+	err = recordTransfer(ctx, from, to, value)
+	if err != nil {
+		return err
+	}
 
-		// Emit the Transfer event
-		transferEvent := event{from, to, value}
-		transferEventJSON, err := json.Marshal(transferEvent)
-		if err != nil {
-			return fmt.Errorf("failed to obtain JSON encoding: %v", err)
-		}
-		err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
-		if err != nil {
-			return fmt.Errorf("failed to set event: %v", err)
-		}
+	// Emit the Transfer event
+	transferEvent := event{from, to, value}
+	transferEventJSON, err := json.Marshal(transferEvent)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
 
-		log.Printf("spender %s allowance updated from %d to %d", spender, currentAllowance, updatedAllowance)
-	*/
 	return nil
 }
 
@@ -608,6 +512,32 @@ func (s *SmartContract) Symbol(ctx contractapi.TransactionContextInterface) (str
 	return string(bytes), nil
 }
 
+// Decimals returns the number of decimals of the token
+// All amounts handled by the contract are integer minor units, e.g. paise for 2 decimals
+func (s *SmartContract) Decimals(ctx contractapi.TransactionContextInterface) (int, error) {
+
+	// Check if contract has been intilized first
+	initialized, err := checkInitialized(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check if contract is already initialized: %v", err)
+	}
+	if !initialized {
+		return 0, fmt.Errorf("Contract options need to be set before calling any function, call Initialize() to initialize contract")
+	}
+
+	bytes, err := ctx.GetStub().GetState(decimalsKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get Decimals: %v", err)
+	}
+
+	decimals, err := strconv.Atoi(string(bytes))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse Decimals %q: %v", string(bytes), err)
+	}
+
+	return decimals, nil
+}
+
 // Set information for a token and intialize contract.
 // param {String} name The name of the token
 // param {String} symbol The symbol of the token
@@ -621,7 +551,12 @@ func (s *SmartContract) Initialize(ctx contractapi.TransactionContextInterface,
 		return false, fmt.Errorf("failed to get MSPID: %v", err)
 	}
 	if clientMSPID != CentralBankerMSPId {
-		return false, fmt.Errorf("client with id %s is not authorized to initialize contract", clientMSPID)
+		return false, fmt.Errorf("%w: client with id %s is not authorized to initialize contract", ErrUnauthorized, clientMSPID)
+	}
+
+	// Amounts are stored as integer minor units, the decimals only tell clients where the decimal point goes
+	if d, err := strconv.Atoi(decimals); err != nil || d < 0 || d > 18 {
+		return false, fmt.Errorf("%w: decimals must be an integer between 0 and 18, got %s", ErrInvalidArgument, decimals)
 	}
 
 	// Check contract options are not already set, client is not authorized to change them once intitialized
@@ -630,7 +565,7 @@ func (s *SmartContract) Initialize(ctx contractapi.TransactionContextInterface,
 		return false, fmt.Errorf("failed to get Name: %v", err)
 	}
 	if bytes != nil {
-		return false, fmt.Errorf("contract options are already set, client is not authorized to change them")
+		return false, fmt.Errorf("%w: contract options are already set, client is not authorized to change them", ErrUnauthorized)
 	}
 
 	err = ctx.GetStub().PutState(nameKey, []byte(name))
@@ -648,11 +583,219 @@ func (s *SmartContract) Initialize(ctx contractapi.TransactionContextInterface,
 		return false, fmt.Errorf("failed to set token name: %v", err)
 	}
 
+	// A new ledger holds no legacy balances to migrate
+	err = putMigratedSchema(ctx, balanceMigration, balanceSchema)
+	if err != nil {
+		return false, err
+	}
+
 	return true, nil
 }
 
 // Helper Functions
 
+// authorizeDebit checks that the client may debit value from the "from" account
+// The bank holding the custody mandate of the account needs no allowance, the allowance of any other spender is decreased
+// Dependant functions include TransferFrom and TransferBatch
+func authorizeDebit(ctx contractapi.TransactionContextInterface, from string, value int) error {
+
+	// Get MSP and ID of submitting client identity
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get MSPID: %v", err)
+	}
+	spender, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client id: %v", err)
+	}
+
+	fromAccount, err := getAccount(ctx, from)
+	if err != nil {
+		return err
+	}
+	err = checkDirectDebit(fromAccount)
+	if err != nil {
+		return err
+	}
+
+	// The bank holding the custody mandate of the account spends on behalf of its customer without an allowance,
+	// any other spender needs an allowance from the account owner
+	if fromAccount == nil || fromAccount.custodian() != clientMSPID {
+		// Create allowanceKey
+		allowanceKey, err := ctx.GetStub().CreateCompositeKey(allowancePrefix, []string{from, spender})
+		if err != nil {
+			return fmt.Errorf("failed to create the composite key for prefix %s: %v", allowancePrefix, err)
+		}
+
+		// Retrieve the allowance of the spender
+		currentAllowanceBytes, err := ctx.GetStub().GetState(allowanceKey)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve the allowance for %s from world state: %v", allowanceKey, err)
+		}
+
+		// If no current allowance, set allowance to 0
+		var currentAllowance int
+		if currentAllowanceBytes != nil {
+			currentAllowance, _ = strconv.Atoi(string(currentAllowanceBytes)) // Error handling not needed since Itoa() was used when setting the allowance, guaranteeing it was an integer.
+		}
+
+		// Check if transferred value is less than allowance
+		if currentAllowance < value {
+			return fmt.Errorf("spender does not have enough allowance for transfer from %s", from)
+		}
+
+		// Decrease the allowance
+		updatedAllowance, err := sub(currentAllowance, value)
+		if err != nil {
+			return err
+		}
+
+		err = ctx.GetStub().PutState(allowanceKey, []byte(strconv.Itoa(updatedAllowance)))
+		if err != nil {
+			return err
+		}
+
+		log.Printf("spender %s allowance updated from %d to %d", spender, currentAllowance, updatedAllowance)
+	}
+
+	return nil
+}
+
+// mintHelper creates new tokens, adds them to the account balance and to the total supply
+// Dependant functions include Mint and MintTo
+func mintHelper(ctx contractapi.TransactionContextInterface, account string, amount int) error {
+
+	if amount <= 0 {
+		return fmt.Errorf("%w: mint amount must be a positive integer", ErrInvalidArgument)
+	}
+
+	// If the account current balance doesn't yet exist, we'll create it with a current balance of 0
+	currentBalance, currentRecord, err := readBalance(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	updatedBalance, err := addBig(currentBalance, amount)
+	if err != nil {
+		return err
+	}
+
+	err = writeBalance(ctx, account, updatedBalance, currentRecord)
+	if err != nil {
+		return err
+	}
+
+	// Update the totalSupply, if no tokens have been minted it starts from 0
+	totalSupply, totalSupplyRecord, err := readBalance(ctx, totalSupplyKey)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve total token supply: %v", err)
+	}
+
+	// Add the mint amount to the total supply and update the state
+	totalSupply, err = addBig(totalSupply, amount)
+	if err != nil {
+		return err
+	}
+
+	err = writeBalance(ctx, totalSupplyKey, totalSupply, totalSupplyRecord)
+	if err != nil {
+		return err
+	}
+
+	// Record the transfer in the history of the accounts
+	err = recordTransfer(ctx, "0x0", account, amount)
+	if err != nil {
+		return err
+	}
+
+	// Emit the Transfer event
+	transferEvent := event{"0x0", account, amount}
+	transferEventJSON, err := json.Marshal(transferEvent)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	log.Printf("account %s balance updated from %d to %d", account, currentBalance, updatedBalance)
+
+	return nil
+}
+
+// burnHelper redeems tokens, removing them from the account balance and from the total supply
+// Dependant functions include Burn and BurnFrom
+func burnHelper(ctx contractapi.TransactionContextInterface, account string, amount int) error {
+
+	if amount <= 0 {
+		return fmt.Errorf("%w: burn amount must be a positive integer", ErrInvalidArgument)
+	}
+
+	currentBalance, currentRecord, err := readBalance(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	// Check if the account current balance exists
+	if currentRecord == nil {
+		return fmt.Errorf("%w: The balance does not exist", ErrNotFound)
+	}
+
+	updatedBalance, err := subBig(currentBalance, amount)
+	if err != nil {
+		return err
+	}
+
+	err = writeBalance(ctx, account, updatedBalance, currentRecord)
+	if err != nil {
+		return err
+	}
+
+	// Update the totalSupply
+	totalSupply, totalSupplyRecord, err := readBalance(ctx, totalSupplyKey)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve total token supply: %v", err)
+	}
+
+	// If no tokens have been minted, throw error
+	if totalSupplyRecord == nil {
+		return fmt.Errorf("%w: totalSupply does not exist", ErrNotFound)
+	}
+
+	// Subtract the burn amount to the total supply and update the state
+	totalSupply, err = subBig(totalSupply, amount)
+	if err != nil {
+		return err
+	}
+
+	err = writeBalance(ctx, totalSupplyKey, totalSupply, totalSupplyRecord)
+	if err != nil {
+		return err
+	}
+
+	// Record the transfer in the history of the accounts
+	err = recordTransfer(ctx, account, "0x0", amount)
+	if err != nil {
+		return err
+	}
+
+	// Emit the Transfer event
+	transferEvent := event{account, "0x0", amount}
+	transferEventJSON, err := json.Marshal(transferEvent)
+	if err != nil {
+		return fmt.Errorf("failed to obtain JSON encoding: %v", err)
+	}
+	err = ctx.GetStub().SetEvent("Transfer", transferEventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+
+	log.Printf("account %s balance updated from %d to %d", account, currentBalance, updatedBalance)
+
+	return nil
+}
+
 // transferHelper is a helper function that transfers tokens from the "from" address to the "to" address
 // Dependant functions include Transfer and TransferFrom
 func transferHelper(ctx contractapi.TransactionContextInterface, from string, to string, value int) error {
@@ -662,53 +805,103 @@ func transferHelper(ctx contractapi.TransactionContextInterface, from string, to
 	}
 
 	if value < 0 { // transfer of 0 is allowed in ERC-20, so just validate against negative amounts
-		return fmt.Errorf("transfer amount cannot be negative")
+		return fmt.Errorf("%w: transfer amount cannot be negative", ErrInvalidArgument)
 	}
 
-	fromCurrentBalanceBytes, err := ctx.GetStub().GetState(from)
+	// Reject a mistyped recipient address before looking the account up
+	err := checkAccountID(ctx, to)
 	if err != nil {
-		return fmt.Errorf("failed to read client account %s from world state: %v", from, err)
+		return err
 	}
 
-	if fromCurrentBalanceBytes == nil {
-		return fmt.Errorf("client account %s has no balance", from)
+	// Funds can only be moved into registered accounts that are open for credits
+	toAccount, err := getAccount(ctx, to)
+	if err != nil {
+		return err
+	}
+	if toAccount == nil {
+		return fmt.Errorf("%w: recipient account %s is not registered", ErrNotFound, to)
+	}
+	err = checkCreditAllowed(toAccount)
+	if err != nil {
+		return err
+	}
+
+	fromAccount, err := getAccount(ctx, from)
+	if err != nil {
+		return err
+	}
+
+	fromCurrentBalance, fromRecord, err := readBalance(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed to read client account %s: %v", from, err)
 	}
 
-	fromCurrentBalance, _ := strconv.Atoi(string(fromCurrentBalanceBytes)) // Error handling not needed since Itoa() was used when setting the account balance, guaranteeing it was an integer.
+	if fromRecord == nil {
+		return fmt.Errorf("client account %s has no balance", from)
+	}
 
-	if fromCurrentBalance < value {
+	if fromCurrentBalance.Cmp(big.NewInt(int64(value))) < 0 {
 		return fmt.Errorf("client account %s has insufficient funds", from)
 	}
 
-	toCurrentBalanceBytes, err := ctx.GetStub().GetState(to)
+	// Court and regulatory orders take precedence over the account holder's instructions
+	err = checkDebitAllowed(fromAccount, fromCurrentBalance, value)
+	if err != nil {
+		return err
+	}
+
+	// Purpose-bound funds can only move to merchants accepting their purpose
+	err = spendPurposeBound(ctx, from, toAccount, fromCurrentBalance, value)
+	if err != nil {
+		return err
+	}
+
+	// Expiring lots are spent first, expired ones cannot be spent at all
+	err = spendExpiringLots(ctx, from, fromCurrentBalance, value)
 	if err != nil {
-		return fmt.Errorf("failed to read recipient account %s from world state: %v", to, err)
+		return err
 	}
 
-	var toCurrentBalance int
 	// If recipient current balance doesn't yet exist, we'll create it with a current balance of 0
-	if toCurrentBalanceBytes == nil {
-		toCurrentBalance = 0
-	} else {
-		toCurrentBalance, _ = strconv.Atoi(string(toCurrentBalanceBytes)) // Error handling not needed since Itoa() was used when setting the account balance, guaranteeing it was an integer.
+	toCurrentBalance, toRecord, err := readBalance(ctx, to)
+	if err != nil {
+		return fmt.Errorf("failed to read recipient account %s: %v", to, err)
+	}
+
+	fromUpdatedBalance, err := subBig(fromCurrentBalance, value)
+	if err != nil {
+		return err
+	}
+
+	toUpdatedBalance, err := addBig(toCurrentBalance, value)
+	if err != nil {
+		return err
+	}
+
+	// Enforce the wallet limits of the KYC tiers of both accounts
+	err = enforceOutflowLimits(ctx, fromAccount, value)
+	if err != nil {
+		return err
 	}
 
-	fromUpdatedBalance, err := sub(fromCurrentBalance, value)
+	err = enforceHoldingLimit(ctx, toAccount, toUpdatedBalance)
 	if err != nil {
 		return err
 	}
 
-	toUpdatedBalance, err := add(toCurrentBalance, value)
+	// Track the obligation between the banks of the accounts
+	err = addInterbankFlow(ctx, fromAccount, toAccount, value)
 	if err != nil {
 		return err
 	}
 
-	err = ctx.GetStub().PutState(from, []byte(strconv.Itoa(fromUpdatedBalance)))
+	err = writeBalance(ctx, from, fromUpdatedBalance, fromRecord)
 	if err != nil {
 		return err
 	}
 
-	err = ctx.GetStub().PutState(to, []byte(strconv.Itoa(toUpdatedBalance)))
+	err = writeBalance(ctx, to, toUpdatedBalance, toRecord)
 	if err != nil {
 		return err
 	}
@@ -750,9 +943,9 @@ func checkInitialized(ctx contractapi.TransactionContextInterface) (bool, error)
 // sub two number checking for overflow
 func sub(b int, q int) (int, error) {
 
-	// sub two number checking
-	if q <= 0 {
-		return 0, fmt.Errorf("Error: the subtraction number is %d, it should be greater than 0", q)
+	// sub two number checking, subtracting 0 is allowed as transfers of 0 are allowed in ERC-20
+	if q < 0 {
+		return 0, fmt.Errorf("Error: the subtraction number is %d, it should not be negative", q)
 	}
 	if b < q {
 		return 0, fmt.Errorf("Error: the number %d is not enough to be subtracted by %d", b, q)
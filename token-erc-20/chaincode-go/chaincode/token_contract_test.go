@@ -0,0 +1,462 @@
+package chaincode_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode"
+	"github.com/hyperledger/fabric-samples/token-erc-20/chaincode-go/chaincode/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	centralBankMSP = "RBIMSP"
+	hdfcMSP        = "HDFCBankMSP"
+	axisMSP        = "AxisBankMSP"
+	hdfcReserve    = "hdfc.cbdc"
+	axisReserve    = "axis.cbdc"
+	reserveFunds   = 1000000
+)
+
+// network is a ledger initialized by the central bank, with HDFC and Axis in the bank registry and funded reserves
+type network struct {
+	t        *testing.T
+	stub     *mocks.ChaincodeStub
+	ctx      *mocks.TransactionContext
+	contract *chaincode.SmartContract
+}
+
+func newNetwork(t *testing.T) *network {
+	stub := mocks.NewChaincodeStub("retail")
+	n := &network{
+		t:        t,
+		stub:     stub,
+		ctx:      &mocks.TransactionContext{Stub: stub, ClientIdentity: &mocks.ClientIdentity{}},
+		contract: &chaincode.SmartContract{},
+	}
+
+	n.ok(centralBankMSP, func() error {
+		_, err := n.contract.Initialize(n.ctx, "Indian eRupee", "eINR", "2")
+		return err
+	})
+	n.ok(centralBankMSP, func() error { return n.contract.RegisterBank(n.ctx, hdfcMSP, "hdfc", hdfcReserve) })
+	n.ok(centralBankMSP, func() error { return n.contract.RegisterBank(n.ctx, axisMSP, "axis", axisReserve) })
+	n.ok(centralBankMSP, func() error { return n.contract.MintTo(n.ctx, hdfcReserve, reserveFunds) })
+	n.ok(centralBankMSP, func() error { return n.contract.MintTo(n.ctx, axisReserve, reserveFunds) })
+	return n
+}
+
+// submit calls the contract as a client of the given MSP in a new transaction a second after the previous one
+// The write set of a successful call is committed, the one of a failed call is dropped as the peers do
+func (n *network) submit(msp string, call func() error) error {
+	return n.submitAs(msp, "client@"+msp, call)
+}
+
+// submitAs submits a call as the client with the given id, e.g. the holder of an account
+func (n *network) submitAs(msp string, clientID string, call func() error) error {
+	n.stub.NextTx(time.Second)
+	n.ctx.ClientIdentity.MSPID = msp
+	n.ctx.ClientIdentity.ID = clientID
+
+	err := call()
+	if err == nil {
+		n.stub.Commit()
+	}
+	return err
+}
+
+// ok submits a call that must succeed
+func (n *network) ok(msp string, call func() error) {
+	n.t.Helper()
+	require.NoError(n.t, n.submit(msp, call))
+}
+
+// wait moves the clock of the next transactions forward
+func (n *network) wait(elapsed time.Duration) {
+	n.stub.TxTimestamp = n.stub.TxTimestamp.Add(elapsed)
+}
+
+// now is the Unix time of the last transaction
+func (n *network) now() int64 {
+	return n.stub.TxTimestamp.Unix()
+}
+
+// openAccount registers a retail account of a bank at a KYC tier and funds it from the reserve of the bank
+func (n *network) openAccount(msp string, seed byte, kycTier int, merchantCategory string, funds int) string {
+	n.t.Helper()
+	prefix, reserve := "hdfc", hdfcReserve
+	if msp == axisMSP {
+		prefix, reserve = "axis", axisReserve
+	}
+
+	account := testAddress(prefix, seed)
+	n.ok(msp, func() error { return n.contract.RegisterAccount(n.ctx, account, kycTier, merchantCategory) })
+	if funds > 0 {
+		n.transfer(msp, reserve, account, funds)
+	}
+	return account
+}
+
+// transfer moves funds between accounts as the bank holding the custody mandate of the "from" account
+func (n *network) transfer(msp string, from string, to string, amount int) {
+	n.t.Helper()
+	n.ok(msp, func() error { return n.contract.TransferFrom(n.ctx, from, to, amount, "") })
+}
+
+// balance reads the balance of an account, zero for an account never credited
+// BalanceOf fails for such accounts, so the total of the balance buckets is read instead
+func (n *network) balance(account string) int {
+	n.t.Helper()
+	buckets, err := n.contract.GetBalanceBuckets(n.ctx, account)
+	require.NoError(n.t, err)
+	value, err := strconv.Atoi(buckets.Total)
+	require.NoError(n.t, err)
+	return value
+}
+
+// testAddress builds a checksummed address of a bank from a repeated id byte
+func testAddress(prefix string, seed byte) string {
+	address := prefix + "1" + hex.EncodeToString(bytes.Repeat([]byte{seed}, 20))
+	digest := sha256.Sum256([]byte(address))
+	return address + hex.EncodeToString(digest[:4])
+}
+
+func TestRegisterAccount(t *testing.T) {
+	n := newNetwork(t)
+	account := testAddress("hdfc", 1)
+
+	err := n.submit("SBIMSP", func() error { return n.contract.RegisterAccount(n.ctx, testAddress("sbi", 1), 0, "") })
+	require.ErrorContains(t, err, "not authorized to register accounts")
+	require.ErrorIs(t, err, chaincode.ErrUnauthorized)
+
+	// A bank registers addresses under its own prefix only
+	err = n.submit(hdfcMSP, func() error { return n.contract.RegisterAccount(n.ctx, testAddress("axis", 1), 0, "") })
+	require.ErrorContains(t, err, "is not an address of "+hdfcMSP)
+
+	err = n.submit(hdfcMSP, func() error { return n.contract.RegisterAccount(n.ctx, account, -1, "") })
+	require.ErrorContains(t, err, "kyc tier cannot be negative")
+
+	n.ok(hdfcMSP, func() error { return n.contract.RegisterAccount(n.ctx, account, 1, "5411") })
+
+	acc, err := n.contract.GetAccount(n.ctx, account)
+	require.NoError(t, err)
+	require.Equal(t, &chaincode.Account{
+		ID:               account,
+		Bank:             hdfcMSP,
+		Type:             "RETAIL",
+		KYCTier:          1,
+		Status:           "ACTIVE",
+		CreatedAt:        n.now(),
+		Custodian:        hdfcMSP,
+		MerchantCategory: "5411",
+	}, acc)
+
+	err = n.submit(hdfcMSP, func() error { return n.contract.RegisterAccount(n.ctx, account, 0, "") })
+	require.ErrorContains(t, err, "is already registered")
+
+	_, err = n.contract.GetAccount(n.ctx, testAddress("hdfc", 2))
+	require.ErrorContains(t, err, "is not registered")
+
+	// Funds can only be sent to registered accounts
+	err = n.submit(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, hdfcReserve, testAddress("hdfc", 2), 1000, "") })
+	require.ErrorContains(t, err, "is not registered")
+}
+
+func TestRegisterReserveAccount(t *testing.T) {
+	n := newNetwork(t)
+
+	err := n.submit(hdfcMSP, func() error { return n.contract.RegisterReserveAccount(n.ctx, "hdfc2.cbdc", hdfcMSP) })
+	require.ErrorContains(t, err, "not authorized to register reserve accounts")
+
+	err = n.submit(centralBankMSP, func() error { return n.contract.RegisterReserveAccount(n.ctx, "sbi.cbdc", "SBIMSP") })
+	require.ErrorContains(t, err, "SBIMSP is not a registered commercial bank")
+
+	n.ok(centralBankMSP, func() error { return n.contract.RegisterReserveAccount(n.ctx, "hdfc2.cbdc", hdfcMSP) })
+
+	acc, err := n.contract.GetAccount(n.ctx, "hdfc2.cbdc")
+	require.NoError(t, err)
+	require.Equal(t, "RESERVE", acc.Type)
+	require.Equal(t, hdfcMSP, acc.Bank)
+}
+
+func TestCloseAccount(t *testing.T) {
+	n := newNetwork(t)
+	account := n.openAccount(hdfcMSP, 1, 0, "", 1000)
+
+	err := n.submit(axisMSP, func() error { return n.contract.CloseAccount(n.ctx, account) })
+	require.ErrorContains(t, err, "not authorized to close account")
+
+	err = n.submit(hdfcMSP, func() error { return n.contract.CloseAccount(n.ctx, account) })
+	require.ErrorContains(t, err, "still holds a balance of 1000")
+
+	n.transfer(hdfcMSP, account, hdfcReserve, 1000)
+	n.ok(hdfcMSP, func() error { return n.contract.CloseAccount(n.ctx, account) })
+
+	err = n.submit(hdfcMSP, func() error { return n.contract.CloseAccount(n.ctx, account) })
+	require.ErrorContains(t, err, "is already closed")
+
+	// A closed account no longer receives funds
+	err = n.submit(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, hdfcReserve, account, 1000, "") })
+	require.ErrorContains(t, err, "is closed")
+}
+
+func TestTransferFromCustody(t *testing.T) {
+	n := newNetwork(t)
+	payer := n.openAccount(hdfcMSP, 1, 0, "", 5000)
+	payee := n.openAccount(axisMSP, 2, 0, "", 0)
+
+	// The bank holding the custody mandate debits without an allowance
+	n.transfer(hdfcMSP, payer, payee, 2000)
+	require.Equal(t, 3000, n.balance(payer))
+	require.Equal(t, 2000, n.balance(payee))
+	require.Equal(t, "Transfer", n.stub.EventName)
+	require.JSONEq(t, `{"from":"`+payer+`","to":"`+payee+`","value":2000}`, string(n.stub.EventPayload))
+
+	// Other banks need an allowance
+	err := n.submit(axisMSP, func() error { return n.contract.TransferFrom(n.ctx, payer, payee, 1000, "") })
+	require.ErrorContains(t, err, "spender does not have enough allowance")
+
+	// Moving the mandate moves the right to debit
+	n.ok(hdfcMSP, func() error { return n.contract.SetCustodian(n.ctx, payer, axisMSP) })
+	n.transfer(axisMSP, payer, payee, 1000)
+	err = n.submit(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, payer, payee, 1000, "") })
+	require.ErrorContains(t, err, "spender does not have enough allowance")
+	require.Equal(t, 2000, n.balance(payer))
+}
+
+func TestTransferFromAllowance(t *testing.T) {
+	tests := []struct {
+		name          string
+		allowance     int
+		amount        int
+		wantErr       string
+		wantAllowance int
+	}{
+		{
+			name:          "within the allowance",
+			allowance:     1500,
+			amount:        1000,
+			wantAllowance: 500,
+		},
+		{
+			name:          "whole allowance",
+			allowance:     1500,
+			amount:        1500,
+			wantAllowance: 0,
+		},
+		{
+			name:          "zero amount",
+			allowance:     1500,
+			amount:        0,
+			wantAllowance: 1500,
+		},
+		{
+			name:          "zero amount without an allowance",
+			amount:        0,
+			wantAllowance: 0,
+		},
+		{
+			name:          "above the allowance",
+			allowance:     1500,
+			amount:        1501,
+			wantErr:       "spender does not have enough allowance",
+			wantAllowance: 1500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNetwork(t)
+			payer := n.openAccount(hdfcMSP, 1, 0, "", 5000)
+			payee := n.openAccount(axisMSP, 2, 0, "", 0)
+			spender := "client@" + axisMSP
+
+			// The holder of the account approves the spender
+			if tt.allowance > 0 {
+				require.NoError(t, n.submitAs(hdfcMSP, payer, func() error { return n.contract.Approve(n.ctx, spender, tt.allowance) }))
+			}
+
+			err := n.submit(axisMSP, func() error { return n.contract.TransferFrom(n.ctx, payer, payee, tt.amount, "") })
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, 5000-tt.amount, n.balance(payer))
+			}
+
+			allowance, err := n.contract.Allowance(n.ctx, payer, spender)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantAllowance, allowance)
+		})
+	}
+}
+
+func TestTransferZeroAmount(t *testing.T) {
+	n := newNetwork(t)
+	payer := n.openAccount(hdfcMSP, 1, 0, "", 5000)
+	payee := n.openAccount(axisMSP, 2, 0, "", 0)
+
+	// Transfers of 0 are allowed in ERC-20 whichever way the debit is authorized
+	require.NoError(t, n.submitAs(hdfcMSP, payer, func() error { return n.contract.Transfer(n.ctx, payee, 0, "") }))
+	n.transfer(hdfcMSP, payer, payee, 0)
+	require.NoError(t, n.submit(axisMSP, func() error { return n.contract.TransferFrom(n.ctx, payer, payee, 0, "") }))
+
+	err := n.submit(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, payer, payee, -1, "") })
+	require.ErrorContains(t, err, "cannot be negative")
+	require.Equal(t, 5000, n.balance(payer))
+}
+
+func TestTransferAccountIDs(t *testing.T) {
+	n := newNetwork(t)
+	payer := n.openAccount(hdfcMSP, 1, 0, "", 5000)
+	payee := n.openAccount(axisMSP, 2, 0, "", 0)
+
+	// A mistyped address is caught by its checksum before the registry is read
+	mistyped := payee[:len(payee)-1] + "0"
+	if mistyped == payee {
+		mistyped = payee[:len(payee)-1] + "1"
+	}
+	err := n.submit(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, payer, mistyped, 1000, "") })
+	require.ErrorContains(t, err, "checksum")
+
+	// Legacy ids are accepted once registered, with or without a "." and even when they look like an address
+	for _, legacy := range []string{"hdfc.ops", "hdfcops", "hdfc1ops"} {
+		err = n.submit(hdfcMSP, func() error { return n.contract.TransferFrom(n.ctx, payer, legacy, 1000, "") })
+		require.ErrorContains(t, err, legacy+" is not a valid account address")
+
+		n.ok(centralBankMSP, func() error { return n.contract.RegisterReserveAccount(n.ctx, legacy, hdfcMSP) })
+		n.transfer(hdfcMSP, payer, legacy, 1000)
+		require.Equal(t, 1000, n.balance(legacy))
+	}
+	require.Equal(t, 2000, n.balance(payer))
+}
+
+// totalSupply reads the total supply of the token
+func (n *network) totalSupply() string {
+	total, err := n.contract.TotalSupply(n.ctx)
+	require.NoError(n.t, err)
+	return total
+}
+
+func TestMintTo(t *testing.T) {
+	n := newNetwork(t)
+	retail := n.openAccount(hdfcMSP, 1, 0, "", 0)
+
+	tests := []struct {
+		name    string
+		msp     string
+		account string
+		amount  int
+		wantErr string
+	}{
+		{
+			name:    "commercial bank",
+			msp:     hdfcMSP,
+			account: hdfcReserve,
+			amount:  500,
+			wantErr: "client is not authorized to mint new tokens",
+		},
+		{
+			name:    "retail account",
+			msp:     centralBankMSP,
+			account: retail,
+			amount:  500,
+			wantErr: retail + " is not a reserve account",
+		},
+		{
+			name:    "unregistered account",
+			msp:     centralBankMSP,
+			account: testAddress("hdfc", 9),
+			amount:  500,
+			wantErr: "is not a reserve account",
+		},
+		{
+			name:    "zero amount",
+			msp:     centralBankMSP,
+			account: hdfcReserve,
+			wantErr: "mint amount must be a positive integer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := n.submit(tt.msp, func() error { return n.contract.MintTo(n.ctx, tt.account, tt.amount) })
+			require.ErrorContains(t, err, tt.wantErr)
+			require.Equal(t, "2000000", n.totalSupply())
+		})
+	}
+
+	// The reserve and the total supply are credited together
+	require.Equal(t, 0, n.balance(retail))
+	n.ok(centralBankMSP, func() error { return n.contract.MintTo(n.ctx, hdfcReserve, 500) })
+	require.Equal(t, reserveFunds+500, n.balance(hdfcReserve))
+	require.Equal(t, "2000500", n.totalSupply())
+	require.Equal(t, "Transfer", n.stub.EventName)
+	require.JSONEq(t, `{"from":"0x0","to":"`+hdfcReserve+`","value":500}`, string(n.stub.EventPayload))
+}
+
+func TestBurnFrom(t *testing.T) {
+	n := newNetwork(t)
+	retail := n.openAccount(hdfcMSP, 1, 0, "", 500)
+
+	tests := []struct {
+		name    string
+		msp     string
+		account string
+		amount  int
+		wantErr string
+	}{
+		{
+			name:    "commercial bank",
+			msp:     hdfcMSP,
+			account: hdfcReserve,
+			amount:  500,
+			wantErr: "client is not authorized to burn tokens",
+		},
+		{
+			name:    "retail account",
+			msp:     centralBankMSP,
+			account: retail,
+			amount:  500,
+			wantErr: retail + " is not a reserve account",
+		},
+		{
+			name:    "more than the balance",
+			msp:     centralBankMSP,
+			account: hdfcReserve,
+			amount:  reserveFunds,
+			wantErr: "is not enough to be subtracted by",
+		},
+		{
+			name:    "zero amount",
+			msp:     centralBankMSP,
+			account: hdfcReserve,
+			wantErr: "burn amount must be a positive integer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := n.submit(tt.msp, func() error { return n.contract.BurnFrom(n.ctx, tt.account, tt.amount) })
+			require.ErrorContains(t, err, tt.wantErr)
+			require.Equal(t, "2000000", n.totalSupply())
+		})
+	}
+
+	// The reserve and the total supply are debited together
+	require.Equal(t, reserveFunds-500, n.balance(hdfcReserve))
+	n.ok(centralBankMSP, func() error { return n.contract.BurnFrom(n.ctx, hdfcReserve, reserveFunds-500) })
+	require.Equal(t, 0, n.balance(hdfcReserve))
+	require.Equal(t, "1000500", n.totalSupply())
+	require.Equal(t, "Transfer", n.stub.EventName)
+	require.JSONEq(t, `{"from":"`+hdfcReserve+`","to":"0x0","value":999500}`, string(n.stub.EventPayload))
+
+	// A frozen reserve cannot be redeemed from
+	n.ok(centralBankMSP, func() error { return n.contract.FreezeAccount(n.ctx, axisReserve, true, "court order") })
+	err := n.submit(centralBankMSP, func() error { return n.contract.BurnFrom(n.ctx, axisReserve, 500) })
+	require.ErrorContains(t, err, "is frozen")
+}